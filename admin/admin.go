@@ -0,0 +1,1385 @@
+// Package admin adds the admin pages to a web.Server.
+package admin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openwichita/infant-info/hours"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+	"github.com/openwichita/infant-info/web"
+	"github.com/openwichita/infant-info/webhook"
+)
+
+type editUserData struct {
+	Email      string
+	Password   string
+	FormAction string
+}
+
+type listData struct {
+	List []string
+}
+
+// Admin handles everything under /admin
+type Admin struct {
+	srv   *web.Server
+	hooks *webhook.Dispatcher
+}
+
+const (
+	actCreate = "create"
+	actEdit   = "edit"
+	actSave   = "save"
+	actDelete = "delete"
+	actPing   = "ping"
+	actLog    = "log"
+	actRetry  = "retry"
+	actExport = "export"
+	actBanner = "banner"
+	actImport = "import"
+	actMerge  = "merge"
+)
+
+// How many rows each table on the search statistics page shows
+const statsTableRows = 50
+
+// Register
+// Add the admin pages to 'srv'. Webhooks are pinged and retried through 'hooks'.
+func Register(srv *web.Server, hooks *webhook.Dispatcher) *Admin {
+	a := &Admin{srv: srv, hooks: hooks}
+
+	// Admin Subrouter
+	s := srv.Routes().PathPrefix("/admin").Subrouter()
+	s.HandleFunc("/", a.handleAdmin)
+	s.HandleFunc("/{category}", a.handleAdmin)
+	s.HandleFunc("/{category}/", a.handleAdmin)
+	s.HandleFunc("/{category}/{action}", a.handleAdmin)
+	s.HandleFunc("/{category}/{action}/", a.handleAdmin)
+	s.HandleFunc("/{category}/{action}/{item}", a.handleAdmin)
+	return a
+}
+
+// handleAdmin
+// Handle entry into the Admin side of things
+func (a *Admin) handleAdmin(w http.ResponseWriter, req *http.Request) {
+	site := a.initAdminRequest(w, req)
+
+	vars := mux.Vars(req)
+
+	adminCategory := vars["category"]
+
+	// First, check if we're logged in
+	userEmail, _ := a.srv.SessionString("email", req)
+
+	// With a valid account
+	validUser := a.srv.Admin.IsUser(userEmail)
+
+	if validUser != nil {
+		// Not logged in, only allow access to the login page
+		if adminCategory == "dologin" {
+			a.handleAdminDoLogin(w, req)
+			return
+		}
+		if adminCategory == "firstcreate" {
+			if firstErr := a.srv.Admin.CheckFirstRun(); firstErr != nil {
+				a.handleAdminSaveUser(w, req)
+			} else {
+				// We already have an admin account... So...
+				a.srv.Redirect(w, req, "/")
+			}
+			return
+		}
+		if adminCategory == "" {
+			a.handleAdminLogin(w, req, site)
+			return
+		}
+		a.srv.Redirect(w, req, "/admin")
+		return
+	}
+
+	site.SubTitle = fmt.Sprintf("Logged in as %s", userEmail)
+
+	site.SetMenuItemActive("Admin")
+
+	if adminCategory == "dologout" {
+		a.handleAdminDoLogout(w, req, site)
+		return
+	}
+	if adminCategory == "users" {
+		a.handleAdminUsers(w, req, site)
+		return
+	}
+	if adminCategory == "resources" {
+		a.handleAdminResources(w, req, site)
+		return
+	}
+	if adminCategory == "tokens" {
+		a.handleAdminTokens(w, req, site)
+		return
+	}
+	if adminCategory == "apikeys" {
+		a.handleAdminAPIKeys(w, req, site)
+		return
+	}
+	if adminCategory == "webhooks" {
+		a.handleAdminWebhooks(w, req, site)
+		return
+	}
+	if adminCategory == "synonyms" {
+		a.handleAdminSynonyms(w, req, site)
+		return
+	}
+	if adminCategory == "searches" {
+		a.handleAdminSearches(w, req, site)
+		return
+	}
+	if adminCategory == "pins" {
+		a.handleAdminPins(w, req, site)
+		return
+	}
+	if adminCategory == "crisis" {
+		a.handleAdminCrisis(w, req, site)
+		return
+	}
+	if adminCategory == "timeline" {
+		a.handleAdminTimeline(w, req, site)
+		return
+	}
+	if adminCategory == "orgs" {
+		a.handleAdminOrgs(w, req, site)
+		return
+	}
+	if adminCategory == "locations" {
+		a.handleAdminLocations(w, req)
+		return
+	}
+
+	a.srv.Redirect(w, req, "/admin/resources")
+}
+
+func (a *Admin) initAdminRequest(w http.ResponseWriter, req *http.Request) *web.SiteData {
+	a.srv.PrintOutput(fmt.Sprintf("Admin Request: %s\n", req.URL))
+
+	w.Header().Set("Cache-Control", "no-cache")
+
+	// First, check if we're logged in
+	userEmail, _ := a.srv.SessionString("email", req)
+
+	// With a valid account
+	validUser := a.srv.Admin.IsUser(userEmail)
+
+	site := &web.SiteData{
+		DevMode:  a.srv.DevMode,
+		Title:    a.srv.Title,
+		BasePath: a.srv.BasePath,
+	}
+	site.Flash = a.srv.TakeFlash(w, req)
+	site.Menu = make([]web.MenuItem, 0, 0)
+	site.BottomMenu = make([]web.MenuItem, 0, 0)
+
+	site.Stylesheets = make([]string, 0, 0)
+	site.Stylesheets = append(site.Stylesheets, a.srv.URL("/assets/css/pure-min.css"))
+	site.Stylesheets = append(site.Stylesheets, "https://maxcdn.bootstrapcdn.com/font-awesome/4.4.0/css/font-awesome.min.css")
+	site.Stylesheets = append(site.Stylesheets, a.srv.URL("/assets/css/ii.css"))
+
+	site.Scripts = make([]string, 0, 0)
+	site.Scripts = append(site.Scripts, a.srv.URL("/assets/js/ii.js"))
+	site.Scripts = append(site.Scripts, a.srv.URL("/assets/js/admin.js"))
+
+	if validUser == nil {
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Users", Link: a.srv.URL("/admin/users")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Resources", Link: a.srv.URL("/admin/resources")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Organizations", Link: a.srv.URL("/admin/orgs")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "API Tokens", Link: a.srv.URL("/admin/tokens")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "API Keys", Link: a.srv.URL("/admin/apikeys")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Webhooks", Link: a.srv.URL("/admin/webhooks")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Synonyms", Link: a.srv.URL("/admin/synonyms")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Searches", Link: a.srv.URL("/admin/searches")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Pins", Link: a.srv.URL("/admin/pins")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Crisis", Link: a.srv.URL("/admin/crisis")})
+		site.Menu = append(site.Menu, web.MenuItem{Text: "Timeline", Link: a.srv.URL("/admin/timeline")})
+
+		site.BottomMenu = append(site.BottomMenu, web.MenuItem{Text: "Logout", Link: a.srv.URL("/admin/dologout")})
+	}
+	site.BottomMenu = append(site.BottomMenu, web.MenuItem{Text: "Home", Link: a.srv.URL("/")})
+	return site
+}
+
+// handleAdminLogin
+// Show the Login screen
+func (a *Admin) handleAdminLogin(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SetMenuItemActive("Admin")
+	if err := a.srv.Admin.CheckFirstRun(); err != nil {
+		a.handleAdminCreateUser(w, req, site)
+		return
+	}
+	site.SubTitle = "Admin Login"
+	a.srv.ShowPage("admin-login.html", site, w)
+}
+
+// handleAdminDoLogin
+// Verify the provided credentials, set up a cookie (if requested)
+// And redirect back to /admin
+func (a *Admin) handleAdminDoLogin(w http.ResponseWriter, req *http.Request) {
+	// Fetch the login credentials
+	email := req.FormValue("email")
+	password := req.FormValue("password")
+	// Remember functionality is not included (yet? ever?)
+	// remember := req.FormValue("remember")
+	if email != "" && password != "" {
+		a.srv.PrintOutput(fmt.Sprintf("  Login Request (%s)\n", email))
+		if err := a.srv.Admin.CheckCredentials(email, password); err != nil {
+			// Couldn't find the credentials
+			a.srv.PrintOutput(fmt.Sprintf("		Failed!\n"))
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			session, err := a.srv.Session(req)
+			if err != nil {
+				http.Error(w, err.Error(), 500)
+				return
+			}
+			session.Values["email"] = email
+			session.Save(req, w)
+		}
+	}
+	// TODO: Show Flash Message
+	//showFlashMessage(fmt.Sprintf("Logged in as %s", email), "warning")
+	a.srv.Redirect(w, req, "/admin")
+}
+
+func (a *Admin) handleAdminDoLogout(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	session, err := a.srv.Session(req)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	session.Options.MaxAge = -1
+	session.Save(req, w)
+
+	site.SubTitle = "Login"
+	site.SetMenuItemActive("Admin")
+
+	// TODO: Show Flash Message
+	//showFlashMessage("You have been logged out.", "secondary")
+
+	a.srv.ShowPage("admin-login.html", site, w)
+
+}
+
+func (a *Admin) handleAdminUsers(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Admin User Management"
+	site.SetMenuItemActive("Users")
+
+	vars := mux.Vars(req)
+	userFunction := vars["action"]
+
+	if userFunction == actCreate {
+		a.handleAdminCreateUser(w, req, site)
+		return
+	} else if userFunction == actEdit {
+		a.handleAdminEditUser(w, req, site)
+		return
+	} else if userFunction == actSave {
+		a.handleAdminSaveUser(w, req)
+		return
+	} else if userFunction == actDelete {
+		a.handleAdminDeleteUser(w, req)
+		return
+	}
+
+	// No action given, display users
+	users, err := a.srv.Admin.Users()
+	userList := make([]string, 0, 0)
+	for i := range users {
+		userList = append(userList, users[i])
+	}
+	site.TemplateData = listData{List: userList}
+	if err == nil {
+		a.srv.ShowPage("admin-users.html", site, w)
+	} else {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+}
+
+func (a *Admin) handleAdminCreateUser(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Create Admin Account"
+	var frmAction string
+	vars := mux.Vars(req)
+	userFunction := vars["action"]
+	if userFunction == actCreate {
+		frmAction = a.srv.URL("/admin/users/save")
+	} else {
+		frmAction = a.srv.URL("/admin/firstcreate")
+	}
+	site.TemplateData = editUserData{Email: "", Password: "", FormAction: frmAction}
+	a.srv.ShowPage("admin-createuser.html", site, w)
+}
+func (a *Admin) handleAdminEditUser(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Edit Admin Account"
+	vars := mux.Vars(req)
+	userEmail := vars["item"]
+	site.TemplateData = editUserData{Email: userEmail, Password: "", FormAction: a.srv.URL("/admin/users/save/" + url.QueryEscape(userEmail))}
+	a.srv.ShowPage("admin-edituser.html", site, w)
+}
+
+func (a *Admin) handleAdminSaveUser(w http.ResponseWriter, req *http.Request) {
+	// Fetch the login credentials
+	vars := mux.Vars(req)
+	email := vars["item"]
+	if email == "" {
+		email = req.FormValue("email")
+	}
+	password := req.FormValue("password")
+	repeatpw := req.FormValue("repeat")
+	if email != "" && password != "" && password == repeatpw {
+		a.srv.PrintOutput(fmt.Sprintf("  Save User Request (%s)\n", email))
+		if err := a.srv.Admin.SaveUser(email, password); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed!\n"))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+	} else {
+		a.srv.PrintOutput(fmt.Sprintf("		Failed!\n"))
+		// TODO: Set Flash Message for Failure
+	}
+
+	a.srv.Redirect(w, req, "/admin/users")
+}
+
+func (a *Admin) handleAdminDeleteUser(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	userItem := vars["item"]
+	a.srv.PrintOutput("Deleting User: " + userItem)
+	if err := a.srv.Admin.DeleteUser(userItem); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+		// TODO: Set Flash Message for Failure
+	} else {
+		a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+		// TODO: Set Flash Message for Success
+	}
+
+	//a.handleAdminUsers(w, req, site)
+	a.srv.Redirect(w, req, "/admin/users")
+}
+
+func (a *Admin) handleAdminResources(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Resource Management"
+	site.SetMenuItemActive("Resources")
+
+	vars := mux.Vars(req)
+	resFunction := vars["action"]
+	if resFunction == actCreate {
+		a.handleAdminEditResource(w, req, site)
+		return
+	} else if resFunction == actEdit {
+		a.handleAdminEditResource(w, req, site)
+		return
+	} else if resFunction == actSave {
+		a.handleAdminSaveResource(w, req)
+		return
+	} else if resFunction == actDelete {
+		a.handleAdminDeleteResource(w, req)
+		return
+	}
+
+	// No action given, display resources
+	type resList struct {
+		Resources []store.Resource
+	}
+	var rList resList
+	var err error
+	rList.Resources, err = a.srv.Store.Resources()
+	for i := range rList.Resources {
+		a.srv.PrintOutput(fmt.Sprintf("%s -> %d\n", rList.Resources[i].Title, len(rList.Resources[i].Tags)))
+		if len(rList.Resources[i].Tags) == 1 {
+			// Make sure that the tag isn't actually blank
+			if rList.Resources[i].Tags[0] == "" {
+				rList.Resources[i].Tags = make([]string, 0, 0)
+			}
+		} else if len(rList.Resources[i].Tags) > 3 {
+			rList.Resources[i].Tags = rList.Resources[i].Tags[0:2]
+			rList.Resources[i].Tags = append(rList.Resources[i].Tags, "...")
+		}
+	}
+	site.TemplateData = rList
+	if err == nil {
+		a.srv.ShowPage("admin-resources.html", site, w)
+		return
+	}
+	a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+
+	a.srv.Redirect(w, req, "/admin/resources")
+}
+func (a *Admin) handleAdminEditResource(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	type tempData struct {
+		FormAction    string
+		Resource      store.Resource
+		ResourceTags  string
+		ResourceAreas string
+		CostOptions   []store.CostOption
+
+		ResourceResidency string
+		PregnancyStages   []store.EligibilityOption
+		InsuranceStatuses []store.EligibilityOption
+
+		Orgs []store.Organization // With their locations, to pick from
+	}
+	site.SubTitle = "Edit Resource"
+	orgs, err := a.srv.Store.Organizations()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	vars := mux.Vars(req)
+	resTitle, err := url.QueryUnescape(vars["item"])
+	var res store.Resource
+	if resTitle != "" {
+		if res, err = a.srv.Store.Resource(resTitle); err == nil {
+			site.TemplateData = tempData{
+				FormAction:    a.srv.URL("/admin/resources/save/" + url.QueryEscape(resTitle)),
+				Resource:      res,
+				ResourceTags:  strings.Join(res.Tags, ","),
+				ResourceAreas: strings.Join(res.ServiceAreas, ", "),
+				CostOptions:   store.CostOptions,
+
+				ResourceResidency: strings.Join(res.Eligibility.Residency, ", "),
+				PregnancyStages:   store.PregnancyStages,
+				InsuranceStatuses: store.InsuranceStatuses,
+
+				Orgs: orgs,
+			}
+		}
+	} else {
+		site.TemplateData = tempData{
+			FormAction:   a.srv.URL("/admin/resources/save"),
+			Resource:     store.Resource{},
+			ResourceTags: "",
+			CostOptions:  store.CostOptions,
+
+			PregnancyStages:   store.PregnancyStages,
+			InsuranceStatuses: store.InsuranceStatuses,
+
+			Orgs: orgs,
+		}
+	}
+	a.srv.ShowPage("admin-editresource.html", site, w)
+	return
+}
+
+// parseEligibility
+// Read the eligibility fields of the resource form
+func parseEligibility(req *http.Request) (store.Eligibility, error) {
+	e := store.Eligibility{
+		Pregnancy: req.Form["pregnancy"],
+		Residency: strings.Split(req.FormValue("residency"), ","),
+		Insurance: req.Form["insurance"],
+		Notes:     req.FormValue("eligibility_notes"),
+	}
+	var err error
+	if e.ParentAge, err = store.ParseAgeRange(req.FormValue("parent_age")); err != nil {
+		return e, err
+	}
+	if e.ChildAge, err = store.ParseAgeRange(req.FormValue("child_age")); err != nil {
+		return e, err
+	}
+	if v := strings.TrimSpace(req.FormValue("income_fpl")); v != "" {
+		if e.IncomeFPL, err = strconv.Atoi(v); err != nil {
+			return e, fmt.Errorf("Invalid income limit: %s", v)
+		}
+	}
+	return e, nil
+}
+
+func (a *Admin) handleAdminDeleteResource(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	resItem, err := url.QueryUnescape(vars["item"])
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+		a.srv.Redirect(w, req, "/admin/resources")
+		return
+	}
+	a.srv.PrintOutput("Deleting Resource: " + resItem)
+	if err := a.srv.Store.Delete(resItem); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+		// TODO: Set Flash Message for Failure
+	} else {
+		a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+		// TODO: Set Flash Message for Success
+	}
+	a.srv.Redirect(w, req, "/admin/resources")
+}
+
+func (a *Admin) handleAdminSaveResource(w http.ResponseWriter, req *http.Request) {
+	// Fetch the Resource Details
+	vars := mux.Vars(req)
+	origTitle, err := url.QueryUnescape(vars["item"])
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+		a.srv.Redirect(w, req, "/admin/resources")
+		return
+	}
+	// The form only has some of the fields, keep the rest as they were
+	// (like the ones only set through the API)
+	var res store.Resource
+	if origTitle == "" {
+		a.srv.PrintOutput("Saving New Resource\n")
+	} else {
+		a.srv.PrintOutput("Saving Old Resource\n")
+		if res, err = a.srv.Store.Resource(origTitle); err != nil {
+			a.failSave(w, req, err, "/admin/resources")
+			return
+		}
+	}
+	back := "/admin/resources/edit/" + url.QueryEscape(origTitle)
+	if origTitle == "" {
+		back = "/admin/resources/create"
+	}
+	res.Title = req.FormValue("title")
+	res.URL = req.FormValue("url")
+	res.Tags = strings.Split(req.FormValue("tags"), ",")
+	res.ServiceAreas = strings.Split(req.FormValue("areas"), ",")
+	res.OrgID = req.FormValue("org_id")
+	res.LocationIDs = req.Form["locations"]
+	if res.Schedule, err = hours.Parse(req.FormValue("schedule")); err != nil {
+		a.failSave(w, req, err, back)
+		return
+	}
+	if res.Cost, err = store.ParseCost(req.Form["cost"], req.FormValue("flat_fee"), req.FormValue("cost_notes")); err != nil {
+		a.failSave(w, req, err, back)
+		return
+	}
+	if res.Eligibility, err = parseEligibility(req); err != nil {
+		a.failSave(w, req, err, back)
+		return
+	}
+	a.srv.PrintOutput(fmt.Sprintf("  %s -> %s\n", res.Title, res.URL))
+	if err := a.srv.Store.Save(origTitle, res); err != nil {
+		a.failSave(w, req, err, back)
+		return
+	}
+	a.srv.SetFlash(w, req, fmt.Sprintf("Saved %s", res.Title), "success")
+	a.srv.Redirect(w, req, "/admin/resources")
+}
+
+// failSave
+// Tell the admin why a save failed and send them back to 'path'
+func (a *Admin) failSave(w http.ResponseWriter, req *http.Request, err error, path string) {
+	a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+	a.srv.SetFlash(w, req, fmt.Sprintf("Not saved: %s", err), "error")
+	a.srv.Redirect(w, req, path)
+}
+
+func (a *Admin) handleAdminTokens(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "API Token Management"
+	site.SetMenuItemActive("API Tokens")
+
+	type tokenData struct {
+		Tokens   []store.Token
+		Scopes   []string
+		NewToken string
+		NewName  string
+	}
+	var tData tokenData
+	tData.Scopes = store.TokenScopes
+
+	vars := mux.Vars(req)
+	tokFunction := vars["action"]
+	if tokFunction == actSave {
+		name := req.FormValue("name")
+		scopes := make([]string, 0, 0)
+		for _, v := range store.TokenScopes {
+			if req.FormValue("scope-"+v) != "" {
+				scopes = append(scopes, v)
+			}
+		}
+		if name == "" || len(scopes) == 0 {
+			a.srv.PrintOutput("		Failed: Token needs a name and at least one scope!\n")
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/tokens")
+			return
+		}
+		a.srv.PrintOutput(fmt.Sprintf("  Create Token Request (%s)\n", name))
+		token, err := a.srv.Admin.CreateToken(name, scopes)
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/tokens")
+			return
+		}
+		// The token is only shown this one time, so show the list right here
+		// instead of redirecting.
+		tData.NewToken = token
+		tData.NewName = name
+	} else if tokFunction == actDelete {
+		tokItem := vars["item"]
+		a.srv.PrintOutput("Revoking Token: " + tokItem)
+		if err := a.srv.Admin.RevokeToken(tokItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/tokens")
+		return
+	}
+
+	var err error
+	if tData.Tokens, err = a.srv.Admin.Tokens(); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	site.TemplateData = tData
+	a.srv.ShowPage("admin-tokens.html", site, w)
+}
+
+func (a *Admin) handleAdminAPIKeys(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "API Key Management"
+	site.SetMenuItemActive("API Keys")
+
+	type keyData struct {
+		Keys         []store.APIKey
+		AnonRequests int
+		AnonLimited  int
+		AnonRate     int
+		DefaultRate  int
+		DefaultQuota int
+		NewKey       string
+		NewName      string
+	}
+	tData := keyData{
+		AnonRate:     web.AnonRatePerMinute,
+		DefaultRate:  web.DefaultKeyRatePerMinute,
+		DefaultQuota: web.DefaultKeyQuotaPerDay,
+	}
+
+	vars := mux.Vars(req)
+	keyFunction := vars["action"]
+	if keyFunction == actSave {
+		name := req.FormValue("name")
+		contact := req.FormValue("contact")
+		rate, err := strconv.Atoi(req.FormValue("rate"))
+		if err != nil || rate <= 0 {
+			rate = web.DefaultKeyRatePerMinute
+		}
+		quota, err := strconv.Atoi(req.FormValue("quota"))
+		if err != nil || quota < 0 {
+			quota = web.DefaultKeyQuotaPerDay
+		}
+		if name == "" {
+			a.srv.PrintOutput("		Failed: Key needs a name!\n")
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/apikeys")
+			return
+		}
+		a.srv.PrintOutput(fmt.Sprintf("  Create API Key Request (%s)\n", name))
+		key, err := a.srv.Admin.CreateAPIKey(name, contact, rate, quota)
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/apikeys")
+			return
+		}
+		a.srv.Limiter.Reset()
+		// The key is only shown this one time, so show the list right here
+		// instead of redirecting.
+		tData.NewKey = key
+		tData.NewName = name
+	} else if keyFunction == actDelete {
+		keyItem := vars["item"]
+		a.srv.PrintOutput("Revoking API Key: " + keyItem)
+		if err := a.srv.Admin.RevokeAPIKey(keyItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Limiter.Reset()
+		a.srv.Redirect(w, req, "/admin/apikeys")
+		return
+	}
+
+	var err error
+	if tData.Keys, tData.AnonRequests, tData.AnonLimited, err = a.srv.Limiter.Usage(); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	sort.Slice(tData.Keys, func(i, j int) bool { return tData.Keys[i].Created.Before(tData.Keys[j].Created) })
+	site.TemplateData = tData
+	a.srv.ShowPage("admin-apikeys.html", site, w)
+}
+
+func (a *Admin) handleAdminWebhooks(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Webhook Management"
+	site.SetMenuItemActive("Webhooks")
+
+	vars := mux.Vars(req)
+	hookFunction := vars["action"]
+	hookItem := vars["item"]
+	if hookFunction == actSave {
+		hookURL := req.FormValue("url")
+		events := make([]string, 0, 0)
+		for _, v := range store.WebhookEvents {
+			if req.FormValue("event-"+v) != "" {
+				events = append(events, v)
+			}
+		}
+		if u, err := url.Parse(hookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(events) == 0 {
+			a.srv.PrintOutput("		Failed: Webhook needs an http(s) URL and at least one event!\n")
+			// TODO: Set Flash Message for Failure
+		} else if _, err := a.srv.Admin.SaveWebhook(hookURL, events); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/webhooks")
+		return
+	} else if hookFunction == actDelete {
+		a.srv.PrintOutput("Deleting Webhook: " + hookItem)
+		if err := a.srv.Admin.DeleteWebhook(hookItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/webhooks")
+		return
+	} else if hookFunction == actPing {
+		h, err := a.srv.Admin.Webhook(hookItem)
+		if err == nil {
+			err = a.hooks.Ping(h)
+		}
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/webhooks/log")
+		return
+	} else if hookFunction == actRetry {
+		id, err := strconv.ParseUint(hookItem, 10, 64)
+		if err == nil {
+			err = a.hooks.Retry(id)
+		}
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/webhooks/log")
+		return
+	} else if hookFunction == actLog {
+		site.SubTitle = "Webhook Deliveries"
+		type logData struct {
+			Deliveries []store.Delivery
+		}
+		dels, err := a.srv.Admin.Deliveries("", 100)
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+		}
+		site.TemplateData = logData{Deliveries: dels}
+		a.srv.ShowPage("admin-webhooklog.html", site, w)
+		return
+	}
+
+	// No action given, display webhooks
+	type hookData struct {
+		Webhooks []store.Webhook
+		Events   []string
+	}
+	hooks, err := a.srv.Admin.Webhooks()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	site.TemplateData = hookData{Webhooks: hooks, Events: store.WebhookEvents}
+	a.srv.ShowPage("admin-webhooks.html", site, w)
+}
+
+func (a *Admin) handleAdminSynonyms(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Search Synonyms"
+	site.SetMenuItemActive("Synonyms")
+
+	vars := mux.Vars(req)
+	synFunction := vars["action"]
+	synItem := vars["item"]
+	if synFunction == actSave {
+		syn := store.Synonym{
+			ID:      synItem,
+			Acronym: req.FormValue("acronym"),
+			Terms:   strings.Split(req.FormValue("terms"), ","),
+		}
+		if _, err := a.srv.Admin.SaveSynonym(syn); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/synonyms")
+		return
+	} else if synFunction == actDelete {
+		a.srv.PrintOutput("Deleting Synonym: " + synItem)
+		if err := a.srv.Admin.DeleteSynonym(synItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/synonyms")
+		return
+	}
+
+	// No action given (or 'edit'), display synonyms
+	type synonymData struct {
+		Synonyms []store.Synonym
+		Editing  store.Synonym
+		// Preview shows how a query is expanded, and what it finds with and
+		// without the synonyms
+		Preview          string
+		PreviewExpanded  string
+		PreviewMatches   int
+		PreviewUnchanged int
+	}
+	syns, err := a.srv.Admin.Synonyms()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	data := synonymData{Synonyms: syns}
+	if synFunction == actEdit {
+		if data.Editing, err = a.srv.Admin.Synonym(synItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+			a.srv.Redirect(w, req, "/admin/synonyms")
+			return
+		}
+	}
+	if data.Preview = strings.TrimSpace(req.FormValue("preview")); data.Preview != "" {
+		resources, err := a.srv.Store.Resources()
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+		}
+		f := search.Filter{Query: data.Preview}
+		data.PreviewUnchanged = len(f.Apply(resources))
+		f.Expansion = search.NewThesaurus(syns).Expand(data.Preview)
+		data.PreviewExpanded = f.Expansion.String()
+		data.PreviewMatches = len(f.Apply(resources))
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-synonyms.html", site, w)
+}
+
+// handleAdminSearches
+// Show what people have been searching for, or export it as CSV
+func (a *Admin) handleAdminSearches(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Search Statistics"
+	site.SetMenuItemActive("Searches")
+
+	vars := mux.Vars(req)
+	days, err := strconv.Atoi(req.FormValue("days"))
+	if err != nil || days < 1 {
+		days = 30
+	}
+	// Include what hasn't been saved yet
+	if err := a.srv.SaveSearchStats(); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	now := time.Now()
+	from := now.AddDate(0, 0, 1-days).Format(store.StatsDayFormat)
+	to := now.Format(store.StatsDayFormat)
+	stats, err := a.srv.Admin.SearchStats(from, to)
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+
+	if vars["action"] == actExport {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="search-stats-%s-%s.csv"`, from, to))
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"day", "type", "term", "searches", "zero_results", "clicks"})
+		for _, day := range stats {
+			for _, q := range day.Queries {
+				cw.Write([]string{day.Day, "query", q.Query, strconv.Itoa(q.Searches), strconv.Itoa(q.ZeroResults), strconv.Itoa(q.Clicks)})
+			}
+			for _, c := range day.Clicks {
+				cw.Write([]string{day.Day, "resource", c.Title, "", "", strconv.Itoa(c.Clicks)})
+			}
+		}
+		cw.Flush()
+		return
+	}
+
+	type searchesData struct {
+		Days        int
+		DayOptions  []int
+		Searches    int
+		ZeroResults int
+		Clicks      int
+		TopQueries  []store.QueryStat
+		ZeroQueries []store.QueryStat // Queries that found nothing, most often first
+		TopClicks   []store.ClickStat
+	}
+	queries, clicks := store.SumSearchStats(stats)
+	data := searchesData{Days: days, DayOptions: []int{7, 30, 90, 365}}
+	zero := make([]store.QueryStat, 0, 0)
+	for _, q := range queries {
+		data.Searches += q.Searches
+		data.ZeroResults += q.ZeroResults
+		if q.ZeroResults > 0 {
+			zero = append(zero, q)
+		}
+	}
+	for _, c := range clicks {
+		data.Clicks += c.Clicks
+	}
+	sort.SliceStable(zero, func(i, j int) bool { return zero[i].ZeroResults > zero[j].ZeroResults })
+	data.TopQueries = queries
+	if len(data.TopQueries) > statsTableRows {
+		data.TopQueries = data.TopQueries[:statsTableRows]
+	}
+	data.ZeroQueries = zero
+	if len(data.ZeroQueries) > statsTableRows {
+		data.ZeroQueries = data.ZeroQueries[:statsTableRows]
+	}
+	data.TopClicks = clicks
+	if len(data.TopClicks) > statsTableRows {
+		data.TopClicks = data.TopClicks[:statsTableRows]
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-searches.html", site, w)
+}
+
+// pinDateFormat is how pin expiry dates are entered
+const pinDateFormat = "2006-01-02"
+
+func (a *Admin) handleAdminPins(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Pinned and Boosted Results"
+	site.SetMenuItemActive("Pins")
+
+	vars := mux.Vars(req)
+	pinFunction := vars["action"]
+	pinItem := vars["item"]
+	if pinFunction == actSave {
+		p := store.Pin{
+			ID:       pinItem,
+			Resource: req.FormValue("resource"),
+			Pinned:   req.FormValue("pinned") != "",
+		}
+		if req.FormValue("kind") == "tag" {
+			p.Tag = strings.TrimSpace(req.FormValue("match"))
+		} else {
+			p.Query = search.NormalizeQuery(req.FormValue("match"))
+		}
+		var err error
+		if boost := strings.TrimSpace(req.FormValue("boost")); boost != "" {
+			p.Boost, err = strconv.ParseFloat(boost, 64)
+		}
+		if exp := req.FormValue("expires"); err == nil && exp != "" {
+			// Pins last through the day they expire on
+			if p.Expires, err = time.ParseInLocation(pinDateFormat, exp, time.Local); err == nil {
+				p.Expires = p.Expires.AddDate(0, 0, 1)
+			}
+		}
+		if pinItem != "" {
+			if old, oErr := a.srv.Admin.Pin(pinItem); oErr == nil {
+				p.Created = old.Created
+			}
+		}
+		if err == nil {
+			_, err = a.srv.Store.Resource(p.Resource)
+		}
+		if err == nil {
+			_, err = a.srv.Admin.SavePin(p)
+		}
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/pins")
+		return
+	} else if pinFunction == actDelete {
+		a.srv.PrintOutput("Deleting Pin: " + pinItem)
+		if err := a.srv.Admin.DeletePin(pinItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/pins")
+		return
+	}
+
+	// No action given (or 'edit'), display pins
+	type pinItemData struct {
+		store.Pin
+		Active  bool
+		Missing bool   // The resource has been deleted
+		Until   string // The last day the pin is active, as entered
+	}
+	type pinData struct {
+		Active    []pinItemData
+		Expired   []pinItemData
+		Resources []string
+		Editing   pinItemData
+	}
+	pins, err := a.srv.Admin.Pins()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	resources, err := a.srv.Store.Resources()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	data := pinData{}
+	titles := make(map[string]bool)
+	for i := range resources {
+		data.Resources = append(data.Resources, resources[i].Title)
+		titles[resources[i].Title] = true
+	}
+	now := time.Now()
+	item := func(p store.Pin) pinItemData {
+		ret := pinItemData{Pin: p, Active: p.IsActive(now), Missing: !titles[p.Resource]}
+		if !p.Expires.IsZero() {
+			ret.Until = p.Expires.AddDate(0, 0, -1).Format(pinDateFormat)
+		}
+		return ret
+	}
+	for _, p := range pins {
+		if p.IsActive(now) {
+			data.Active = append(data.Active, item(p))
+		} else {
+			data.Expired = append(data.Expired, item(p))
+		}
+	}
+	if pinFunction == actEdit {
+		p, err := a.srv.Admin.Pin(pinItem)
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+			a.srv.Redirect(w, req, "/admin/pins")
+			return
+		}
+		data.Editing = item(p)
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-pins.html", site, w)
+}
+
+// handleAdminCrisis
+// Manage the crisis messages shown for searches like "suicide", and the
+// sitewide emergency banner
+func (a *Admin) handleAdminCrisis(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Crisis Messages"
+	site.SetMenuItemActive("Crisis")
+
+	vars := mux.Vars(req)
+	crisisFunction := vars["action"]
+	crisisItem := vars["item"]
+	if crisisFunction == actSave {
+		c := store.Crisis{
+			ID:      crisisItem,
+			Terms:   strings.Split(req.FormValue("terms"), ","),
+			Message: req.FormValue("message"),
+			Phone:   req.FormValue("phone"),
+			Link:    req.FormValue("link"),
+		}
+		if _, err := a.srv.Admin.SaveCrisis(c); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/crisis")
+		return
+	} else if crisisFunction == actDelete {
+		a.srv.PrintOutput("Deleting Crisis Message: " + crisisItem)
+		if err := a.srv.Admin.DeleteCrisis(crisisItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/crisis")
+		return
+	} else if crisisFunction == actBanner {
+		a.srv.PrintOutput("Saving Emergency Banner")
+		if err := a.srv.Admin.SaveSettings(map[string]string{
+			store.SettingBannerMessage: req.FormValue("message"),
+			store.SettingBannerStatus:  req.FormValue("status"),
+			store.SettingBannerLink:    req.FormValue("link"),
+		}); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/crisis")
+		return
+	}
+
+	// No action given (or 'edit'), display crisis messages
+	type crisisData struct {
+		Crises  []store.Crisis
+		Editing store.Crisis
+		Banner  web.FlashMessage
+		// Test shows which message, if any, a search would bring up
+		Test      string
+		TestMatch *store.Crisis
+	}
+	crises, err := a.srv.Admin.Crises()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	data := crisisData{Crises: crises, Banner: a.srv.Banner()}
+	if crisisFunction == actEdit {
+		if data.Editing, err = a.srv.Admin.Crisis(crisisItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+			a.srv.Redirect(w, req, "/admin/crisis")
+			return
+		}
+	}
+	if data.Test = strings.TrimSpace(req.FormValue("test")); data.Test != "" {
+		if c, ok := search.MatchCrisis(data.Test, crises); ok {
+			data.TestMatch = &c
+		}
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-crisis.html", site, w)
+}
+
+// handleAdminTimeline
+// Add, edit and delete the milestones on the public timeline
+func (a *Admin) handleAdminTimeline(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Timeline Milestones"
+	site.SetMenuItemActive("Timeline")
+
+	vars := mux.Vars(req)
+	timelineFunction := vars["action"]
+	timelineItem := vars["item"]
+	if timelineFunction == actSave {
+		week, err := strconv.Atoi(strings.TrimSpace(req.FormValue("week")))
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: Invalid week %s!\n", req.FormValue("week")))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/timeline")
+			return
+		}
+		m := store.Milestone{
+			ID:          timelineItem,
+			Stage:       req.FormValue("stage"),
+			Week:        week,
+			Title:       req.FormValue("title"),
+			Description: req.FormValue("description"),
+			Tags:        strings.Split(req.FormValue("tags"), ","),
+			Resources:   req.Form["resources"],
+		}
+		if _, err := a.srv.Admin.SaveMilestone(m); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, "/admin/timeline")
+		return
+	} else if timelineFunction == actDelete {
+		a.srv.PrintOutput("Deleting Milestone: " + timelineItem)
+		if err := a.srv.Admin.DeleteMilestone(timelineItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/timeline")
+		return
+	}
+
+	// No action given (or 'edit'), display the milestones
+	type resourceOption struct {
+		Title    string
+		Selected bool
+	}
+	type timelineData struct {
+		Milestones []store.Milestone
+		Editing    store.Milestone
+		Resources  []resourceOption // Every resource, to pick from
+	}
+	milestones, err := a.srv.Admin.Milestones()
+	if err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	data := timelineData{Milestones: milestones, Editing: store.Milestone{Stage: store.MilestonePregnancy}}
+	if timelineFunction == actEdit {
+		if data.Editing, err = a.srv.Admin.Milestone(timelineItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+			a.srv.Redirect(w, req, "/admin/timeline")
+			return
+		}
+	}
+	if resources, err := a.srv.Store.Resources(); err == nil {
+		for _, res := range resources {
+			data.Resources = append(data.Resources, resourceOption{
+				Title:    res.Title,
+				Selected: search.ContainsFold(data.Editing.Resources, res.Title),
+			})
+		}
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-timeline.html", site, w)
+}
+
+func (a *Admin) handleAdminOrgs(w http.ResponseWriter, req *http.Request, site *web.SiteData) {
+	site.SubTitle = "Organizations"
+	site.SetMenuItemActive("Organizations")
+
+	vars := mux.Vars(req)
+	orgFunction := vars["action"]
+	orgItem := vars["item"]
+	if orgFunction == actSave {
+		org := store.Organization{
+			ID:           orgItem,
+			Name:         req.FormValue("name"),
+			Description:  req.FormValue("description"),
+			Website:      req.FormValue("website"),
+			ContactName:  req.FormValue("contact_name"),
+			ContactEmail: req.FormValue("contact_email"),
+			ContactPhone: req.FormValue("contact_phone"),
+			Logo:         req.FormValue("logo"),
+		}
+		org, err := a.srv.Store.SaveOrganization(org)
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/orgs")
+			return
+		}
+		a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+		// TODO: Set Flash Message for Success
+		// Stay on the organization, to add its locations
+		a.srv.Redirect(w, req, "/admin/orgs/edit/"+url.PathEscape(org.ID))
+		return
+	} else if orgFunction == actDelete {
+		a.srv.PrintOutput("Deleting Organization: " + orgItem)
+		if err := a.srv.Store.DeleteOrganization(orgItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.Redirect(w, req, "/admin/orgs")
+		return
+	} else if orgFunction == actMerge {
+		into := req.FormValue("into")
+		a.srv.PrintOutput(fmt.Sprintf("Merging Organization: %s into %s\n", orgItem, into))
+		if err := a.srv.Store.MergeOrganization(orgItem, into); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/orgs/edit/"+url.PathEscape(orgItem))
+			return
+		}
+		a.srv.Redirect(w, req, "/admin/orgs/edit/"+url.PathEscape(into))
+		return
+	} else if orgFunction == actImport {
+		if req.Method != "POST" {
+			a.srv.Redirect(w, req, "/admin/orgs")
+			return
+		}
+		n, err := a.srv.Store.ImportOrganizations()
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		}
+		a.srv.PrintOutput(fmt.Sprintf("		Linked %d resources to organizations\n", n))
+		a.srv.Redirect(w, req, "/admin/orgs")
+		return
+	}
+
+	// No action given (or 'edit'), display the organizations
+	type orgData struct {
+		Orgs     []store.Organization
+		Editing  store.Organization
+		Location store.Location // The location being edited, or a new one
+		Programs []store.Resource
+		Unlinked int // Resources with an org name but no organization
+	}
+	var data orgData
+	var err error
+	if data.Orgs, err = a.srv.Store.Organizations(); err != nil {
+		a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+	if orgFunction == actEdit {
+		if data.Editing, err = a.srv.Store.Organization(orgItem); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+			a.srv.Redirect(w, req, "/admin/orgs")
+			return
+		}
+		site.SubTitle = "Organization: " + data.Editing.Name
+		data.Location.OrgID = data.Editing.ID
+		if id := req.FormValue("location"); id != "" {
+			for _, loc := range data.Editing.Locations {
+				if loc.ID == id {
+					data.Location = loc
+				}
+			}
+		}
+		if data.Programs, err = a.srv.Store.Programs(data.Editing); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("%s\n", err))
+		}
+	}
+	if resources, err := a.srv.Store.Resources(); err == nil {
+		for _, res := range resources {
+			if res.OrgID == "" && strings.TrimSpace(res.Org) != "" {
+				data.Unlinked++
+			}
+		}
+	}
+	site.TemplateData = data
+	a.srv.ShowPage("admin-orgs.html", site, w)
+}
+
+// handleAdminLocations
+// Save and delete an organization's locations, they're listed and edited on
+// the organization's page
+func (a *Admin) handleAdminLocations(w http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	locFunction := vars["action"]
+	locItem := vars["item"]
+	if locFunction == actSave {
+		loc := store.Location{
+			ID:      locItem,
+			OrgID:   req.FormValue("org_id"),
+			Name:    req.FormValue("name"),
+			Address: req.FormValue("address"),
+			Phones:  strings.Split(req.FormValue("phones"), "\n"),
+		}
+		back := "/admin/orgs/edit/" + url.PathEscape(loc.OrgID)
+		var err error
+		if loc.Schedule, err = hours.Parse(req.FormValue("schedule")); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, back)
+			return
+		}
+		if _, err := a.srv.Store.SaveLocation(loc); err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+		} else {
+			a.srv.PrintOutput(fmt.Sprintf("		Success!\n"))
+			// TODO: Set Flash Message for Success
+		}
+		a.srv.Redirect(w, req, back)
+		return
+	} else if locFunction == actDelete {
+		a.srv.PrintOutput("Deleting Location: " + locItem)
+		loc, err := a.srv.Store.Location(locItem)
+		if err == nil {
+			err = a.srv.Store.DeleteLocation(locItem)
+		}
+		if err != nil {
+			a.srv.PrintOutput(fmt.Sprintf("		Failed: %s!\n", err))
+			// TODO: Set Flash Message for Failure
+			a.srv.Redirect(w, req, "/admin/orgs")
+			return
+		}
+		a.srv.Redirect(w, req, "/admin/orgs/edit/"+url.PathEscape(loc.OrgID))
+		return
+	}
+	a.srv.Redirect(w, req, "/admin/orgs")
+}
@@ -1,28 +1,46 @@
 (function (window, document) {
-  var deleteUserIcons = document.getElementsByClassName("delete-admin-user"),
+  var base = document.body.getAttribute("data-base") || "",
+      deleteUserIcons = document.getElementsByClassName("delete-admin-user"),
       editUserIcons = document.getElementsByClassName("edit-admin-user"),
       addNewUserButton = document.getElementById("addUserButton"),
       deleteResourceIcons = document.getElementsByClassName("delete-resource"),
       editResourceIcons = document.getElementsByClassName("edit-resource"),
-      addNewResourceButton = document.getElementById("addResourceButton");
+      addNewResourceButton = document.getElementById("addResourceButton"),
+      revokeTokenIcons = document.getElementsByClassName("revoke-token"),
+      revokeAPIKeyIcons = document.getElementsByClassName("revoke-apikey"),
+      pingWebhookIcons = document.getElementsByClassName("ping-webhook"),
+      deleteWebhookIcons = document.getElementsByClassName("delete-webhook"),
+      retryDeliveryIcons = document.getElementsByClassName("retry-delivery"),
+      editSynonymIcons = document.getElementsByClassName("edit-synonym"),
+      deleteSynonymIcons = document.getElementsByClassName("delete-synonym"),
+      editPinIcons = document.getElementsByClassName("edit-pin"),
+      deletePinIcons = document.getElementsByClassName("delete-pin"),
+      editCrisisIcons = document.getElementsByClassName("edit-crisis"),
+      deleteCrisisIcons = document.getElementsByClassName("delete-crisis"),
+      editMilestoneIcons = document.getElementsByClassName("edit-milestone"),
+      deleteMilestoneIcons = document.getElementsByClassName("delete-milestone"),
+      editOrgIcons = document.getElementsByClassName("edit-org"),
+      deleteOrgIcons = document.getElementsByClassName("delete-org"),
+      editLocationIcons = document.getElementsByClassName("edit-location"),
+      deleteLocationIcons = document.getElementsByClassName("delete-location");
   /* User Management */
   if(addNewUserButton) {
     addNewUserButton.onclick = function(e) {
-      location.href = "/admin/users/create";
+      location.href = base+"/admin/users/create";
     }
     for(var i = 0; i < deleteUserIcons.length; i++) {
       deleteUserIcons[i].onclick = function(e) {
         var userName = this.parentElement.parentElement.getAttribute("data-user");
         var answer = confirm("Are you sure you want to delete user '"+userName+"'?");
         if(answer) {
-          location.href = "/admin/users/delete/"+encodeURIComponent(userName);
+          location.href = base+"/admin/users/delete/"+encodeURIComponent(userName);
         }
       };
     }
     for(var i = 0; i < editUserIcons.length; i++) {
       editUserIcons[i].onclick = function(e) {
         var userName = this.parentElement.parentElement.getAttribute("data-user");
-        location.href = "/admin/users/edit/"+encodeURIComponent(userName);
+        location.href = base+"/admin/users/edit/"+encodeURIComponent(userName);
       };
     }
   }
@@ -30,22 +48,170 @@
   /* Resource Management */
   if(addNewResourceButton) {
     addNewResourceButton.onclick = function(e) {
-      location.href = "/admin/resources/create";
+      location.href = base+"/admin/resources/create";
     }
     for(var i = 0; i < deleteResourceIcons.length; i++) {
       deleteResourceIcons[i].onclick = function(e) {
         var resTitle = this.parentElement.parentElement.getAttribute("data-resource");
         var answer = confirm("Are you sure you want to delete resource '"+resTitle+"'?");
         if(answer) {
-          location.href = "/admin/resources/delete/"+encodeURIComponent(resTitle);
+          location.href = base+"/admin/resources/delete/"+encodeURIComponent(resTitle);
         }
       };
     }
     for(var i = 0; i < editResourceIcons.length; i++) {
       editResourceIcons[i].onclick = function(e) {
         var resTitle = this.parentElement.parentElement.getAttribute("data-resource");
-        location.href = "/admin/resources/edit/"+encodeURIComponent(resTitle);
+        location.href = base+"/admin/resources/edit/"+encodeURIComponent(resTitle);
       };
     }
   }
+
+  /* API Token Management */
+  for(var i = 0; i < revokeTokenIcons.length; i++) {
+    revokeTokenIcons[i].onclick = function(e) {
+      var tokenId = this.parentElement.parentElement.getAttribute("data-token");
+      var tokenName = this.parentElement.parentElement.getAttribute("data-token-name");
+      var answer = confirm("Are you sure you want to revoke token '"+tokenName+"'?");
+      if(answer) {
+        location.href = base+"/admin/tokens/delete/"+encodeURIComponent(tokenId);
+      }
+    };
+  }
+
+  /* API Key Management */
+  for(var i = 0; i < revokeAPIKeyIcons.length; i++) {
+    revokeAPIKeyIcons[i].onclick = function(e) {
+      var keyId = this.parentElement.parentElement.getAttribute("data-apikey");
+      var keyName = this.parentElement.parentElement.getAttribute("data-apikey-name");
+      var answer = confirm("Are you sure you want to revoke the API key for '"+keyName+"'?");
+      if(answer) {
+        location.href = base+"/admin/apikeys/delete/"+encodeURIComponent(keyId);
+      }
+    };
+  }
+
+  /* Webhook Management */
+  for(var i = 0; i < pingWebhookIcons.length; i++) {
+    pingWebhookIcons[i].onclick = function(e) {
+      var hookId = this.parentElement.parentElement.getAttribute("data-webhook");
+      location.href = base+"/admin/webhooks/ping/"+encodeURIComponent(hookId);
+    };
+  }
+  for(var i = 0; i < deleteWebhookIcons.length; i++) {
+    deleteWebhookIcons[i].onclick = function(e) {
+      var hookId = this.parentElement.parentElement.getAttribute("data-webhook");
+      var hookUrl = this.parentElement.parentElement.getAttribute("data-webhook-url");
+      var answer = confirm("Are you sure you want to delete the webhook to '"+hookUrl+"'?");
+      if(answer) {
+        location.href = base+"/admin/webhooks/delete/"+encodeURIComponent(hookId);
+      }
+    };
+  }
+  for(var i = 0; i < retryDeliveryIcons.length; i++) {
+    retryDeliveryIcons[i].onclick = function(e) {
+      var deliveryId = this.parentElement.parentElement.getAttribute("data-delivery");
+      location.href = base+"/admin/webhooks/retry/"+encodeURIComponent(deliveryId);
+    };
+  }
+
+  /* Synonym Management */
+  for(var i = 0; i < editSynonymIcons.length; i++) {
+    editSynonymIcons[i].onclick = function(e) {
+      var synId = this.parentElement.parentElement.getAttribute("data-synonym");
+      location.href = base+"/admin/synonyms/edit/"+encodeURIComponent(synId);
+    };
+  }
+  for(var i = 0; i < deleteSynonymIcons.length; i++) {
+    deleteSynonymIcons[i].onclick = function(e) {
+      var synId = this.parentElement.parentElement.getAttribute("data-synonym");
+      var answer = confirm("Are you sure you want to delete this synonym?");
+      if(answer) {
+        location.href = base+"/admin/synonyms/delete/"+encodeURIComponent(synId);
+      }
+    };
+  }
+
+  /* Pin Management */
+  for(var i = 0; i < editPinIcons.length; i++) {
+    editPinIcons[i].onclick = function(e) {
+      var pinId = this.parentElement.parentElement.getAttribute("data-pin");
+      location.href = base+"/admin/pins/edit/"+encodeURIComponent(pinId);
+    };
+  }
+  for(var i = 0; i < deletePinIcons.length; i++) {
+    deletePinIcons[i].onclick = function(e) {
+      var pinId = this.parentElement.parentElement.getAttribute("data-pin");
+      var answer = confirm("Are you sure you want to delete this pin?");
+      if(answer) {
+        location.href = base+"/admin/pins/delete/"+encodeURIComponent(pinId);
+      }
+    };
+  }
+
+  /* Crisis Message Management */
+  for(var i = 0; i < editCrisisIcons.length; i++) {
+    editCrisisIcons[i].onclick = function(e) {
+      var crisisId = this.parentElement.parentElement.getAttribute("data-crisis");
+      location.href = base+"/admin/crisis/edit/"+encodeURIComponent(crisisId);
+    };
+  }
+  for(var i = 0; i < deleteCrisisIcons.length; i++) {
+    deleteCrisisIcons[i].onclick = function(e) {
+      var crisisId = this.parentElement.parentElement.getAttribute("data-crisis");
+      var answer = confirm("Are you sure you want to delete this crisis message?");
+      if(answer) {
+        location.href = base+"/admin/crisis/delete/"+encodeURIComponent(crisisId);
+      }
+    };
+  }
+
+  /* Timeline Milestone Management */
+  for(var i = 0; i < editMilestoneIcons.length; i++) {
+    editMilestoneIcons[i].onclick = function(e) {
+      var milestoneId = this.parentElement.parentElement.getAttribute("data-milestone");
+      location.href = base+"/admin/timeline/edit/"+encodeURIComponent(milestoneId);
+    };
+  }
+  for(var i = 0; i < deleteMilestoneIcons.length; i++) {
+    deleteMilestoneIcons[i].onclick = function(e) {
+      var milestoneId = this.parentElement.parentElement.getAttribute("data-milestone");
+      var answer = confirm("Are you sure you want to delete this milestone?");
+      if(answer) {
+        location.href = base+"/admin/timeline/delete/"+encodeURIComponent(milestoneId);
+      }
+    };
+  }
+
+  /* Organization Management */
+  for(var i = 0; i < editOrgIcons.length; i++) {
+    editOrgIcons[i].onclick = function(e) {
+      var orgId = this.parentElement.parentElement.getAttribute("data-org");
+      location.href = base+"/admin/orgs/edit/"+encodeURIComponent(orgId);
+    };
+  }
+  for(var i = 0; i < deleteOrgIcons.length; i++) {
+    deleteOrgIcons[i].onclick = function(e) {
+      var orgId = this.parentElement.parentElement.getAttribute("data-org");
+      var answer = confirm("Are you sure you want to delete this organization and its locations? Its programs are kept.");
+      if(answer) {
+        location.href = base+"/admin/orgs/delete/"+encodeURIComponent(orgId);
+      }
+    };
+  }
+  for(var i = 0; i < editLocationIcons.length; i++) {
+    editLocationIcons[i].onclick = function(e) {
+      var row = this.parentElement.parentElement;
+      location.href = base+"/admin/orgs/edit/"+encodeURIComponent(row.getAttribute("data-org"))+"?location="+encodeURIComponent(row.getAttribute("data-location"));
+    };
+  }
+  for(var i = 0; i < deleteLocationIcons.length; i++) {
+    deleteLocationIcons[i].onclick = function(e) {
+      var locationId = this.parentElement.parentElement.getAttribute("data-location");
+      var answer = confirm("Are you sure you want to delete this location?");
+      if(answer) {
+        location.href = base+"/admin/locations/delete/"+encodeURIComponent(locationId);
+      }
+    };
+  }
 }(this, this.document));
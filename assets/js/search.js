@@ -0,0 +1,40 @@
+(function (window, document) {
+  var input = document.querySelector('input[data-suggest]'),
+      list = document.getElementById('search-suggestions'),
+      timer = null,
+      last = '';
+
+  if(input == null || list == null || !window.fetch) {
+    return;
+  }
+
+  // Fill the datalist with suggestions for what has been typed so far
+  function suggest() {
+    var q = input.value.trim();
+    if(q.length < 2 || q === last) {
+      return;
+    }
+    last = q;
+    fetch(input.getAttribute('data-suggest')+'?q='+encodeURIComponent(q))
+      .then(function(resp) { return resp.ok ? resp.json() : []; })
+      .then(function(suggestions) {
+        if(input.value.trim() !== q) {
+          // Something else has been typed since
+          return;
+        }
+        list.innerHTML = '';
+        suggestions.forEach(function(s) {
+          var opt = document.createElement('option');
+          opt.value = s.text;
+          opt.label = s.kind;
+          list.appendChild(opt);
+        });
+      })
+      .catch(function() {});
+  }
+
+  input.addEventListener('input', function() {
+    clearTimeout(timer);
+    timer = setTimeout(suggest, 150);
+  });
+}(this, this.document));
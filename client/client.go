@@ -0,0 +1,326 @@
+// Package client talks to the Infant Info directory API
+//
+//	c := client.New("https://infant-info.example.org", client.WithAPIKey(key))
+//	it := c.List(ctx, 50)
+//	for it.Next() {
+//		fmt.Println(it.Resource().Title)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Requests that are rate limited (429) or fail on the server (5xx) are
+// retried, waiting as long as the server asks, until the context is done.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client is a client for the directory API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	apiKey     string
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sets the API token used for creating, updating and deleting
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithAPIKey sets the public API key used to raise the read rate limit
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithHTTPClient sets the http.Client that requests are made with
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetries sets how many times a failed request is retried, and how long
+// to wait before the first retry when the server doesn't say
+func WithRetries(max int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = max
+		c.backoff = backoff
+	}
+}
+
+// New returns a Client for the directory at baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+		backoff:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is returned when the API responds with an error status
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("infant-info: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the API
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// Get returns the resource with the given title
+func (c *Client) Get(ctx context.Context, title string) (*Resource, error) {
+	var res Resource
+	if _, err := c.do(ctx, "GET", resourcePath(title), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Create adds a new resource, it needs a token with the 'create' scope
+func (c *Client) Create(ctx context.Context, res Resource) (*Resource, error) {
+	var ret Resource
+	if _, err := c.do(ctx, "POST", "/api/resources", res, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Update replaces the resource with the given title, it needs a token with
+// the 'update' scope. The resource can be renamed by giving res a new Title.
+func (c *Client) Update(ctx context.Context, title string, res Resource) (*Resource, error) {
+	var ret Resource
+	if _, err := c.do(ctx, "PUT", resourcePath(title), res, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Delete removes the resource with the given title, it needs a token with
+// the 'delete' scope
+func (c *Client) Delete(ctx context.Context, title string) error {
+	_, err := c.do(ctx, "DELETE", resourcePath(title), nil, nil)
+	return err
+}
+
+// Orgs returns every organization with its locations, sorted by name
+func (c *Client) Orgs(ctx context.Context) ([]Organization, error) {
+	var orgs []Organization
+	if _, err := c.do(ctx, "GET", "/api/orgs", nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// Org returns the organization with the given ID, with its locations
+func (c *Client) Org(ctx context.Context, id string) (*Organization, error) {
+	var org Organization
+	if _, err := c.do(ctx, "GET", "/api/orgs/"+url.PathEscape(id), nil, &org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// Bootstrap returns every resource and the token to sync changes from
+func (c *Client) Bootstrap(ctx context.Context) (*Snapshot, error) {
+	var snap Snapshot
+	if _, err := c.do(ctx, "GET", "/api/sync/bootstrap", nil, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// Changes returns up to limit changes made after the sync token 'since'.
+// Call it again with the returned SyncToken while HasMore is true.
+func (c *Client) Changes(ctx context.Context, since string, limit int) (*ChangeSet, error) {
+	v := url.Values{}
+	v.Set("since", since)
+	if limit > 0 {
+		v.Set("limit", strconv.Itoa(limit))
+	}
+	var cs ChangeSet
+	if _, err := c.do(ctx, "GET", "/api/sync?"+v.Encode(), nil, &cs); err != nil {
+		return nil, err
+	}
+	return &cs, nil
+}
+
+// List returns an iterator over every resource, sorted by title, fetching
+// pageSize resources at a time
+func (c *Client) List(ctx context.Context, pageSize int) *ResourceIterator {
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+	v := url.Values{}
+	v.Set("limit", strconv.Itoa(pageSize))
+	return &ResourceIterator{ctx: ctx, c: c, next: "/api/resources?" + v.Encode()}
+}
+
+// ResourceIterator steps through a paged list of resources
+type ResourceIterator struct {
+	ctx  context.Context
+	c    *Client
+	next string // Path of the next page, "" once there are no more
+	page []Resource
+	cur  Resource
+	err  error
+}
+
+// Next advances to the next resource, fetching another page when needed.
+// It returns false when there are no more resources or there was an error.
+func (it *ResourceIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for len(it.page) == 0 {
+		if it.next == "" {
+			return false
+		}
+		var page []Resource
+		hdr, err := it.c.do(it.ctx, "GET", it.next, nil, &page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.next = nextLink(hdr.Get("Link"))
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Resource returns the current resource
+func (it *ResourceIterator) Resource() Resource {
+	return it.cur
+}
+
+// Err returns the error that stopped the iterator, if any
+func (it *ResourceIterator) Err() error {
+	return it.err
+}
+
+// do
+// Make a request, retrying if it's rate limited or the server has trouble,
+// and decode the JSON response into 'out'
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) (http.Header, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Accept", "application/json")
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if attempt >= c.maxRetries {
+				return nil, err
+			}
+		} else {
+			retry, err := c.handleResponse(resp, out)
+			if !retry || attempt >= c.maxRetries {
+				return resp.Header, err
+			}
+			if after, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && after >= 0 {
+				wait = time.Duration(after) * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// handleResponse
+// Decode the response, reporting whether it is worth retrying
+func (c *Client) handleResponse(resp *http.Response, out interface{}) (bool, error) {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		if out == nil || resp.StatusCode == http.StatusNoContent {
+			io.Copy(ioutil.Discard, resp.Body)
+			return false, nil
+		}
+		return false, json.NewDecoder(resp.Body).Decode(out)
+	}
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error == "" {
+		apiErr.Error = http.StatusText(resp.StatusCode)
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, &Error{StatusCode: resp.StatusCode, Message: apiErr.Error}
+}
+
+// resourcePath
+// The path to the resource titled 'title'
+func resourcePath(title string) string {
+	return "/api/resources/" + url.PathEscape(title)
+}
+
+// nextLink
+// Pull the rel="next" target out of a Link header
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, p := range parts[1:] {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
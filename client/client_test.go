@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+	"github.com/openwichita/infant-info/web"
+)
+
+// testServer is the directory's web handlers on fresh databases
+type testServer struct {
+	*httptest.Server
+	Web      *web.Server
+	requests int32 // Every request that reached the server
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := store.Open(filepath.Join(dir, "ii.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adm, err := store.OpenAdmin(filepath.Join(dir, "iiAdmin.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := &testServer{}
+	if ts.Web, err = web.New(web.DefaultConfig(), st, adm); err != nil {
+		t.Fatal(err)
+	}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&ts.requests, 1)
+		ts.Web.ServeHTTP(w, req)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// limitedServer answers every GET with the resource WIC, but can be told
+// to turn away the next few requests with a 429
+type limitedServer struct {
+	*httptest.Server
+	requests   int32 // Every request that reached the server
+	limit      int32 // How many more requests to turn away
+	retryAfter string
+}
+
+func newLimitedServer(t *testing.T) *limitedServer {
+	t.Helper()
+	ls := &limitedServer{}
+	ls.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&ls.requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&ls.limit, -1) >= 0 {
+			w.Header().Set("Retry-After", ls.retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, `{"error":"Rate limit exceeded"}`)
+			return
+		}
+		fmt.Fprintln(w, `{"title":"WIC","url":"https://example.org"}`)
+	}))
+	t.Cleanup(ls.Close)
+	return ls
+}
+
+// rateLimit
+// Turn away the next 'n' requests, asking for a wait of 'retryAfter' seconds
+func (ls *limitedServer) rateLimit(n int32, retryAfter string) {
+	ls.retryAfter = retryAfter
+	atomic.StoreInt32(&ls.limit, n)
+}
+
+func (ts *testServer) token(t *testing.T, scopes ...string) string {
+	t.Helper()
+	token, err := ts.Web.Admin.CreateToken("test", scopes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func testResource(title string) Resource {
+	return Resource{Title: title, URL: "https://example.org/" + title}
+}
+
+func TestTokenAuth(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	anon := New(ts.URL)
+	if _, err := anon.Create(ctx, testResource("Healthy Babies")); !isStatus(err, http.StatusUnauthorized) {
+		t.Errorf("Creating without a token: %v", err)
+	}
+
+	creator := New(ts.URL, WithToken(ts.token(t, store.ScopeCreate)))
+	res, err := creator.Create(ctx, testResource("Healthy Babies"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Title != "Healthy Babies" {
+		t.Errorf("Created %q", res.Title)
+	}
+	if err := creator.Delete(ctx, "Healthy Babies"); !isStatus(err, http.StatusUnauthorized) {
+		t.Errorf("Deleting without the scope: %v", err)
+	}
+
+	editor := New(ts.URL, WithToken(ts.token(t, store.ScopeUpdate, store.ScopeDelete)))
+	upd := testResource("Healthy Babies")
+	upd.Description = "Home visits"
+	if res, err = editor.Update(ctx, "Healthy Babies", upd); err != nil {
+		t.Fatal(err)
+	}
+	if res.Description != "Home visits" {
+		t.Errorf("Updated description is %q", res.Description)
+	}
+	if err := editor.Delete(ctx, "Healthy Babies"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := anon.Get(ctx, "Healthy Babies"); !IsNotFound(err) {
+		t.Errorf("Getting a deleted resource: %v", err)
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	ts := newTestServer(t)
+	want := []string{"A/B Clinic", "Breastfeeding", "Car Seats", "Doulas", "Early Head Start", "Safe Sleep", "WIC"}
+	for i := len(want) - 1; i >= 0; i-- {
+		if err := ts.Web.Store.Save("", store.Resource{Title: want[i], URL: "https://example.org"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	it := New(ts.URL).List(context.Background(), 3)
+	var got []string
+	for it.Next() {
+		got = append(got, it.Resource().Title)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Listed %q, wanted %q", got, want)
+	}
+	if n := atomic.LoadInt32(&ts.requests); n != 3 {
+		t.Errorf("Listing took %d requests, wanted 3 pages", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	ts := newLimitedServer(t)
+	// The backoff is far longer than the test, so the retries have to
+	// follow Retry-After
+	c := New(ts.URL, WithRetries(3, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ts.rateLimit(2, "0")
+	res, err := c.Get(ctx, "WIC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Title != "WIC" {
+		t.Errorf("Got %q", res.Title)
+	}
+	if n := atomic.LoadInt32(&ts.requests); n != 3 {
+		t.Errorf("Made %d requests, wanted 2 turned away and 1 that worked", n)
+	}
+
+	// Out of retries
+	ts.rateLimit(10, "0")
+	if _, err := c.Get(ctx, "WIC"); !isStatus(err, http.StatusTooManyRequests) {
+		t.Errorf("After running out of retries: %v", err)
+	}
+}
+
+func TestCancelDuringBackoff(t *testing.T) {
+	ts := newLimitedServer(t)
+	ts.rateLimit(1, "3600")
+	c := New(ts.URL, WithRetries(3, time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.Get(ctx, "WIC")
+	if err != context.Canceled {
+		t.Errorf("Got %v, wanted %v", err, context.Canceled)
+	}
+	if took := time.Since(start); took > 5*time.Second {
+		t.Errorf("Cancelling took %s", took)
+	}
+	if n := atomic.LoadInt32(&ts.requests); n != 1 {
+		t.Errorf("Made %d requests, wanted 1", n)
+	}
+}
+
+func isStatus(err error, status int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == status
+}
@@ -0,0 +1,141 @@
+package client
+
+import "time"
+
+// Resource is a resource in the directory
+type Resource struct {
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	URL          string      `json:"url"`
+	Org          string      `json:"org"`
+	Address      string      `json:"address"`
+	Email        string      `json:"email"`
+	Phone        string      `json:"phone"`
+	Hours        string      `json:"hours"`
+	Fees         []string    `json:"fees"`
+	Languages    []string    `json:"languages"`
+	Tags         []string    `json:"tags"`
+	ServiceAreas []string    `json:"service_areas"`
+	Schedule     Schedule    `json:"schedule"`
+	Cost         Cost        `json:"cost"`
+	Eligibility  Eligibility `json:"eligibility"`
+	OrgID        string      `json:"org_id,omitempty"`       // See Organization
+	LocationIDs  []string    `json:"location_ids,omitempty"` // The address is the first one's
+	Latitude     float64     `json:"latitude,omitempty"`
+	Longitude    float64     `json:"longitude,omitempty"`
+}
+
+// Organization runs resources from one or more locations
+type Organization struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Website      string     `json:"website"`
+	ContactName  string     `json:"contact_name"`
+	ContactEmail string     `json:"contact_email"`
+	ContactPhone string     `json:"contact_phone"`
+	Logo         string     `json:"logo"`
+	Locations    []Location `json:"locations"`
+}
+
+// Location is a place where an organization offers its resources
+type Location struct {
+	ID        string   `json:"id"`
+	OrgID     string   `json:"org_id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Schedule  Schedule `json:"schedule"`
+	Phones    []string `json:"phones"`
+	Latitude  float64  `json:"latitude,omitempty"`
+	Longitude float64  `json:"longitude,omitempty"`
+}
+
+// Cost is what a resource costs and how families can pay for it
+type Cost struct {
+	Free             bool   `json:"free,omitempty"`
+	SlidingScale     bool   `json:"sliding_scale,omitempty"`
+	FlatFee          string `json:"flat_fee,omitempty"` // Like "$25 per visit"
+	Medicaid         bool   `json:"medicaid,omitempty"` // Accepts KanCare/Medicaid
+	CHIP             bool   `json:"chip,omitempty"`
+	PrivateInsurance bool   `json:"private_insurance,omitempty"`
+	Uninsured        bool   `json:"uninsured,omitempty"` // Uninsured families welcome
+	Notes            string `json:"notes,omitempty"`
+}
+
+// Eligibility is who a resource is for, blank fields don't limit anything.
+// Pregnancy is first_trimester, second_trimester, third_trimester or
+// postpartum. Insurance is medicaid, chip, private or uninsured.
+type Eligibility struct {
+	ParentAge AgeRange `json:"parent_age"` // Years
+	ChildAge  AgeRange `json:"child_age"`  // Months
+	Pregnancy []string `json:"pregnancy"`
+	IncomeFPL int      `json:"income_fpl,omitempty"` // Percent of the federal poverty level
+	Residency []string `json:"residency"`            // Like "county:Sedgwick"
+	Insurance []string `json:"insurance"`
+	Notes     string   `json:"notes,omitempty"`
+}
+
+// AgeRange is from Min to Max inclusive, a Max of 0 is no limit
+type AgeRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// Schedule is when a resource is open. Times are 24 hour, like "08:00",
+// and dates are YYYY-MM-DD, in Wichita time.
+type Schedule struct {
+	Always        bool        `json:"always,omitempty"`
+	ByAppointment bool        `json:"by_appointment,omitempty"`
+	Weekly        []Period    `json:"weekly,omitempty"`
+	Exceptions    []Exception `json:"exceptions,omitempty"`
+}
+
+// Period is a time a resource is open every week, Day is mon to sun
+type Period struct {
+	Day   string `json:"day"`
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// Exception replaces the weekly hours from Start to End, no Hours is closed
+type Exception struct {
+	Start string     `json:"start"`
+	End   string     `json:"end,omitempty"`
+	Hours []Interval `json:"hours,omitempty"`
+	Note  string     `json:"note,omitempty"`
+}
+
+// Interval is a time a resource is open on a day
+type Interval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// Change operations
+const (
+	OpUpsert = "upsert"
+	OpDelete = "delete"
+)
+
+// Change is a single change to the directory. Upserts carry the resource
+// as it is now, deletes only have the title.
+type Change struct {
+	Seq      uint64    `json:"seq"`
+	Op       string    `json:"op"`
+	Title    string    `json:"title"`
+	Time     time.Time `json:"time"`
+	Resource *Resource `json:"resource,omitempty"`
+}
+
+// Snapshot is the whole directory and the token to sync changes from
+type Snapshot struct {
+	SyncToken string     `json:"sync_token"`
+	Resources []Resource `json:"resources"`
+}
+
+// ChangeSet is a page of changes from the directory
+type ChangeSet struct {
+	SyncToken string   `json:"sync_token"`
+	HasMore   bool     `json:"has_more"`
+	Changes   []Change `json:"changes"`
+}
@@ -0,0 +1,135 @@
+// Package directory wires the store, web, admin and webhook packages
+// together into a single http.Handler.
+//
+// To run the directory inside another service:
+//
+//	cfg := directory.DefaultConfig()
+//	cfg.BasePath = "/directory"
+//	dir, err := directory.New(cfg)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	dir.Start()
+//	mux.PathPrefix("/directory").Handler(dir)
+package directory
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openwichita/infant-info/admin"
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/rpc"
+	"github.com/openwichita/infant-info/store"
+	"github.com/openwichita/infant-info/transit"
+	"github.com/openwichita/infant-info/web"
+	"github.com/openwichita/infant-info/webhook"
+	"google.golang.org/grpc"
+)
+
+// Config is the web.Config plus where the databases and data files live
+type Config struct {
+	web.Config
+
+	DBPath      string
+	AdminDBPath string
+	ZIPPath     string // ZIP code centroids, see geo.LoadGazetteer
+	GTFSPath    string // Transit feed, reloaded when the file changes
+}
+
+// DefaultConfig
+// Returns the settings used when running on our own
+func DefaultConfig() Config {
+	return Config{
+		Config:      web.DefaultConfig(),
+		DBPath:      "ii.db",
+		AdminDBPath: "iiAdmin.db",
+		ZIPPath:     "data/zipcodes.csv",
+		GTFSPath:    "data/gtfs.zip",
+	}
+}
+
+// Directory is the whole site
+type Directory struct {
+	Store    *store.Store
+	Admin    *store.AdminStore
+	Web      *web.Server
+	Webhooks *webhook.Dispatcher
+	RPC      *rpc.Server
+}
+
+// New
+// Opens the databases and sets up every route
+func New(cfg Config) (*Directory, error) {
+	d := new(Directory)
+	var err error
+	if d.Store, err = store.Open(cfg.DBPath); err != nil {
+		return nil, fmt.Errorf("Error loading database: %s", err)
+	}
+	if d.Admin, err = store.OpenAdmin(cfg.AdminDBPath); err != nil {
+		return nil, fmt.Errorf("Error loading admin database: %s", err)
+	}
+	if d.Web, err = web.New(cfg.Config, d.Store, d.Admin); err != nil {
+		return nil, err
+	}
+	if d.Web.Geo, err = geo.LoadGazetteer(cfg.ZIPPath); err != nil {
+		// Everything but distances still works without it
+		d.Web.PrintOutput(fmt.Sprintf("Error loading ZIP codes: %s\n", err))
+	}
+	d.Store.SetGeocoder(d.Web.Geo)
+	d.Web.Transit = transit.NewWatcher(cfg.GTFSPath, d.Web.PrintOutput)
+	if n, err := d.Store.GeocodeMissing(); err != nil {
+		d.Web.PrintOutput(fmt.Sprintf("Error locating resources: %s\n", err))
+	} else if n > 0 {
+		d.Web.PrintOutput(fmt.Sprintf("Located %d resources\n", n))
+	}
+	d.Webhooks = webhook.New(d.Admin, cfg.Title+" Webhooks", d.Web.PrintOutput)
+	d.Store.OnChange(d.Webhooks.Queue)
+	d.Store.OnChange(d.followRename)
+	admin.Register(d.Web, d.Webhooks)
+	d.RPC = rpc.New(d.Store, d.Web.PrintOutput)
+	d.RPC.Geo = d.Web.Geo
+	return d, nil
+}
+
+// followRename
+// Keep pins pointing at resources that are renamed
+func (d *Directory) followRename(ev store.Event) {
+	if ev.Type != store.EventUpdated || ev.PreviousTitle == "" {
+		return
+	}
+	if err := d.Admin.RenamePins(ev.PreviousTitle, ev.Resource.Title); err != nil {
+		d.Web.PrintOutput(fmt.Sprintf("Error renaming pins: %s\n", err))
+	}
+}
+
+// Start
+// Start the background work: saving API key usage and search statistics,
+// sending webhooks and watching for a new transit feed
+func (d *Directory) Start() {
+	go d.Web.FlushAPIKeyUsage(time.Minute)
+	go d.Web.FlushSearchStats(time.Minute)
+	go d.Webhooks.Run(time.Minute)
+	go d.Web.Transit.Run(time.Minute)
+}
+
+// ServeHTTP
+// Makes the Directory an http.Handler
+func (d *Directory) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	d.Web.ServeHTTP(w, req)
+}
+
+// ServeGRPC
+// Serve the gRPC Directory service on 'addr', like ":8081"
+// Services with their own gRPC server can use RPC.Register instead.
+func (d *Directory) ServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	g := grpc.NewServer()
+	d.RPC.Register(g)
+	return g.Serve(lis)
+}
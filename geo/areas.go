@@ -0,0 +1,111 @@
+package geo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Service Area Kinds
+const (
+	AreaZIP    = "zip"
+	AreaCity   = "city"
+	AreaCounty = "county"
+	AreaState  = "state"
+)
+
+// AreaKinds are the kinds of service area, smallest first
+var AreaKinds = []string{AreaZIP, AreaCity, AreaCounty, AreaState}
+
+// ServiceArea is somewhere a resource serves, written like "zip:67202",
+// "city:Wichita", "county:Sedgwick" or "state:KS"
+type ServiceArea struct {
+	Kind  string
+	Value string
+}
+
+var (
+	zipOnly   = regexp.MustCompile(`^\d{5}$`)
+	stateOnly = regexp.MustCompile(`^[A-Za-z]{2}$`)
+)
+
+// ParseServiceArea
+// Parse a service area. Besides "kind:value", a bare ZIP code like "67202"
+// and a county like "Sedgwick County" are understood.
+func ParseServiceArea(s string) (ServiceArea, error) {
+	s = strings.TrimSpace(s)
+	kind, val := "", s
+	if i := strings.Index(s, ":"); i >= 0 {
+		kind, val = strings.ToLower(strings.TrimSpace(s[:i])), strings.TrimSpace(s[i+1:])
+	} else if zipOnly.MatchString(s) {
+		kind = AreaZIP
+	} else if strings.HasSuffix(strings.ToLower(s), " county") {
+		kind = AreaCounty
+	}
+	if kind == AreaCounty && strings.HasSuffix(strings.ToLower(val), " county") {
+		val = strings.TrimSpace(val[:len(val)-len(" county")])
+	}
+	if val == "" {
+		return ServiceArea{}, fmt.Errorf("Service area is blank: %s", s)
+	}
+	switch kind {
+	case AreaZIP:
+		if !zipOnly.MatchString(val) {
+			return ServiceArea{}, fmt.Errorf("Service area ZIP code should be 5 digits: %s", s)
+		}
+	case AreaState:
+		if !stateOnly.MatchString(val) {
+			return ServiceArea{}, fmt.Errorf("Service area state should be 2 letters, like KS: %s", s)
+		}
+		val = strings.ToUpper(val)
+	case AreaCity, AreaCounty:
+	default:
+		return ServiceArea{}, fmt.Errorf("Service area should start with zip:, city:, county: or state: - %s", s)
+	}
+	return ServiceArea{Kind: kind, Value: val}, nil
+}
+
+// String
+// Returns the area as "kind:value", the reverse of ParseServiceArea
+func (a ServiceArea) String() string {
+	return a.Kind + ":" + a.Value
+}
+
+// Label
+// Describes the area for people, like "Sedgwick County"
+func (a ServiceArea) Label() string {
+	switch a.Kind {
+	case AreaZIP:
+		return "ZIP " + a.Value
+	case AreaCounty:
+		return a.Value + " County"
+	}
+	return a.Value
+}
+
+// Includes
+// Is place 'p' in the area
+func (a ServiceArea) Includes(p Place) bool {
+	switch a.Kind {
+	case AreaZIP:
+		return a.Value == p.ZIP
+	case AreaCity:
+		return strings.EqualFold(a.Value, p.City)
+	case AreaCounty:
+		return strings.EqualFold(a.Value, p.County)
+	case AreaState:
+		return strings.EqualFold(a.Value, p.State)
+	}
+	return false
+}
+
+// Serves
+// Is place 'p' in any of 'areas'. Areas that don't parse are skipped.
+func Serves(areas []string, p Place) bool {
+	for _, s := range areas {
+		if a, err := ParseServiceArea(s); err == nil && a.Includes(p) {
+			return true
+		}
+	}
+	return false
+}
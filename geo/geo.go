@@ -0,0 +1,190 @@
+// Package geo works out where resources and families are, without calling
+// out to any geocoding service. Addresses are located by their ZIP code,
+// using the ZIP code centroids bundled in data/zipcodes.csv.
+package geo
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The Earth's mean radius, for Distance
+const earthRadiusMiles = 3958.8
+
+// Point is a latitude and longitude, in degrees
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// IsZero
+// Is the point unset. Nothing we list is at 0,0.
+func (p Point) IsZero() bool {
+	return p.Lat == 0 && p.Lng == 0
+}
+
+// String
+// Returns the point as "lat,lng", the reverse of ParsePoint
+func (p Point) String() string {
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(p.Lng, 'f', -1, 64)
+}
+
+// ParsePoint
+// Parse coordinates like "37.6872,-97.3301"
+func ParsePoint(s string) (Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("Coordinates should be latitude,longitude: %s", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return Point{}, fmt.Errorf("Invalid latitude: %s", parts[0])
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return Point{}, fmt.Errorf("Invalid longitude: %s", parts[1])
+	}
+	return Point{Lat: lat, Lng: lng}, nil
+}
+
+// Distance
+// Returns the distance between 'a' and 'b' in miles, as the crow flies
+func Distance(a, b Point) float64 {
+	rad := math.Pi / 180
+	dLat := (b.Lat - a.Lat) * rad
+	dLng := (b.Lng - a.Lng) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(h))
+}
+
+var zipPattern = regexp.MustCompile(`\b(\d{5})(?:-\d{4})?\s*$`)
+
+// ZIP
+// Returns the ZIP code at the end of an address, or "" if it doesn't have one
+func ZIP(address string) string {
+	if m := zipPattern.FindStringSubmatch(strings.TrimSpace(address)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Place is a ZIP code and where it is
+type Place struct {
+	ZIP    string
+	City   string
+	County string
+	State  string
+	Point
+}
+
+// Gazetteer looks up places by ZIP code. A nil or empty Gazetteer knows
+// nowhere, so the directory still runs without the data file.
+type Gazetteer struct {
+	places map[string]Place
+}
+
+// LoadGazetteer
+// Read a CSV of zip,city,county,state,lat,lng with a header row.
+// Lines starting with '#' are comments.
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = 6
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	g := &Gazetteer{places: make(map[string]Place)}
+	for i, row := range rows {
+		if i == 0 && row[0] == "zip" {
+			continue
+		}
+		pt, err := ParsePoint(row[4] + "," + row[5])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %s", path, i+1, err)
+		}
+		g.places[row[0]] = Place{
+			ZIP:    row[0],
+			City:   row[1],
+			County: row[2],
+			State:  row[3],
+			Point:  pt,
+		}
+	}
+	return g, nil
+}
+
+// Place
+// Returns the place for a ZIP code
+func (g *Gazetteer) Place(zip string) (Place, bool) {
+	if g == nil {
+		return Place{}, false
+	}
+	p, ok := g.places[zip]
+	return p, ok
+}
+
+// Geocode
+// Returns the coordinates of the ZIP code at the end of 'address'
+func (g *Gazetteer) Geocode(address string) (lat, lng float64, ok bool) {
+	p, ok := g.Place(ZIP(address))
+	return p.Lat, p.Lng, ok
+}
+
+// Locate
+// Work out where someone is from what they typed: a ZIP code or
+// coordinates like "37.6872,-97.3301". Coordinates are given the ZIP code,
+// city, county and state of the nearest known ZIP code, if there is one
+// close enough.
+func (g *Gazetteer) Locate(where string) (Place, error) {
+	where = strings.TrimSpace(where)
+	if strings.Contains(where, ",") {
+		pt, err := ParsePoint(where)
+		if err != nil {
+			return Place{}, err
+		}
+		p, _ := g.Nearest(pt)
+		p.Point = pt
+		return p, nil
+	}
+	zip := ZIP(where)
+	if zip == "" {
+		return Place{}, fmt.Errorf("Enter a ZIP code, like 67202")
+	}
+	p, ok := g.Place(zip)
+	if !ok {
+		return Place{}, fmt.Errorf("We don't know where ZIP code %s is", zip)
+	}
+	return p, nil
+}
+
+// How far a point can be from the center of a ZIP code and still be in it,
+// as far as Nearest is concerned
+const nearestMaxMiles = 10
+
+// Nearest
+// Returns the place whose center is closest to 'pt'
+func (g *Gazetteer) Nearest(pt Point) (Place, bool) {
+	var ret Place
+	if g == nil {
+		return ret, false
+	}
+	best := float64(nearestMaxMiles)
+	for _, p := range g.places {
+		if d := Distance(pt, p.Point); d < best || (d == best && p.ZIP < ret.ZIP) {
+			ret, best = p, d
+		}
+	}
+	return ret, ret.ZIP != ""
+}
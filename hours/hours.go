@@ -0,0 +1,234 @@
+// Package hours keeps when resources are open: a weekly schedule with
+// dated exceptions for holidays and closures, and whether a resource is
+// open at a given time in Wichita.
+//
+// Schedules are written one rule to a line, which is how they are stored
+// and edited in the admin pages:
+//
+//	Mon-Fri 8am-12pm, 1pm-5pm
+//	Sat 9am-12pm
+//	By appointment
+//	2026-12-25 closed # Christmas
+//	2026-12-24 9am-12pm # Christmas Eve
+//	2026-07-06 to 2026-07-10 closed # Staff training
+//
+// "24/7" marks a resource that never closes.
+package hours
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	// Every server needs America/Chicago, whether or not it has zoneinfo
+	_ "time/tzdata"
+)
+
+// Zone is the time zone that schedules are in
+var Zone = loadZone("America/Chicago")
+
+func loadZone(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// DateFormat is how exception dates are written
+const DateFormat = "2006-01-02"
+
+// Schedule is when a resource is open
+type Schedule struct {
+	Always        bool        `json:"always,omitempty"` // Open 24/7
+	ByAppointment bool        `json:"by_appointment,omitempty"`
+	Weekly        []Period    `json:"weekly,omitempty"`
+	Exceptions    []Exception `json:"exceptions,omitempty"`
+}
+
+// Period is a time a resource is open every week. A Close before Open
+// runs past midnight.
+type Period struct {
+	Day   string `json:"day"`   // mon, tue, wed, thu, fri, sat or sun
+	Open  string `json:"open"`  // 24 hour time, like "08:00"
+	Close string `json:"close"` // 24 hour time, like "17:00" or "24:00"
+}
+
+// Exception replaces the weekly schedule from Start to End (inclusive)
+// No Hours means closed.
+type Exception struct {
+	Start string     `json:"start"`         // YYYY-MM-DD
+	End   string     `json:"end,omitempty"` // YYYY-MM-DD, blank for just Start
+	Hours []Interval `json:"hours,omitempty"`
+	Note  string     `json:"note,omitempty"`
+}
+
+// Interval is a time a resource is open on a day
+type Interval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// IsEmpty
+// Has nobody said when the resource is open
+func (s Schedule) IsEmpty() bool {
+	return !s.Always && !s.ByAppointment && len(s.Weekly) == 0 && len(s.Exceptions) == 0
+}
+
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// dayIndex
+// Returns the time.Weekday for a day name like "Mon" or "monday"
+func dayIndex(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	for i, d := range dayNames {
+		if strings.HasPrefix(name, d) {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+
+// parseClock
+// Returns the minutes after midnight for times like "8am", "5:30pm",
+// "08:00", "17:30", "noon" or "24:00"
+func parseClock(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "noon":
+		return 12 * 60, nil
+	case "midnight":
+		return 24 * 60, nil
+	}
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("Invalid time: %s", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+	if ampm := strings.Replace(m[3], ".", "", -1); ampm != "" {
+		if h < 1 || h > 12 {
+			return 0, fmt.Errorf("Invalid time: %s", s)
+		}
+		if h == 12 {
+			h = 0
+		}
+		if ampm == "pm" {
+			h += 12
+		}
+	}
+	if min > 59 || h > 24 || (h == 24 && min > 0) {
+		return 0, fmt.Errorf("Invalid time: %s", s)
+	}
+	return h*60 + min, nil
+}
+
+// formatClock
+// Returns minutes after midnight as 24 hour time, like "08:00"
+func formatClock(min int) string {
+	return fmt.Sprintf("%02d:%02d", min/60, min%60)
+}
+
+// Normalize
+// Check every part of the schedule, returning it with days, times and
+// dates written the standard way and in order
+func (s Schedule) Normalize() (Schedule, error) {
+	ret := Schedule{Always: s.Always, ByAppointment: s.ByAppointment}
+	for _, p := range s.Weekly {
+		d, ok := dayIndex(p.Day)
+		if !ok {
+			return s, fmt.Errorf("Invalid day: %s", p.Day)
+		}
+		iv, err := Interval{Open: p.Open, Close: p.Close}.normalize()
+		if err != nil {
+			return s, err
+		}
+		ret.Weekly = append(ret.Weekly, Period{Day: dayNames[d], Open: iv.Open, Close: iv.Close})
+	}
+	sort.SliceStable(ret.Weekly, func(i, j int) bool {
+		di, _ := dayIndex(ret.Weekly[i].Day)
+		dj, _ := dayIndex(ret.Weekly[j].Day)
+		// Monday first
+		if di, dj = (di+6)%7, (dj+6)%7; di != dj {
+			return di < dj
+		}
+		return ret.Weekly[i].Open < ret.Weekly[j].Open
+	})
+	for _, e := range s.Exceptions {
+		ne := Exception{Start: strings.TrimSpace(e.Start), End: strings.TrimSpace(e.End), Note: strings.TrimSpace(e.Note)}
+		start, err := time.Parse(DateFormat, ne.Start)
+		if err != nil {
+			return s, fmt.Errorf("Invalid date: %s", e.Start)
+		}
+		if ne.End == ne.Start {
+			ne.End = ""
+		}
+		if ne.End != "" {
+			end, err := time.Parse(DateFormat, ne.End)
+			if err != nil {
+				return s, fmt.Errorf("Invalid date: %s", e.End)
+			}
+			if end.Before(start) {
+				return s, fmt.Errorf("Exception ends before it starts: %s to %s", e.Start, e.End)
+			}
+		}
+		for _, iv := range e.Hours {
+			niv, err := iv.normalize()
+			if err != nil {
+				return s, err
+			}
+			ne.Hours = append(ne.Hours, niv)
+		}
+		ret.Exceptions = append(ret.Exceptions, ne)
+	}
+	sort.SliceStable(ret.Exceptions, func(i, j int) bool {
+		return ret.Exceptions[i].Start < ret.Exceptions[j].Start
+	})
+	return ret, nil
+}
+
+func (iv Interval) normalize() (Interval, error) {
+	open, err := parseClock(iv.Open)
+	if err != nil {
+		return iv, err
+	}
+	close, err := parseClock(iv.Close)
+	if err != nil {
+		return iv, err
+	}
+	if open == 24*60 {
+		open = 0
+	}
+	if open == close {
+		return iv, fmt.Errorf("Opens and closes at the same time: %s-%s", iv.Open, iv.Close)
+	}
+	return Interval{Open: formatClock(open), Close: formatClock(close)}, nil
+}
+
+// minutes
+// Returns when the interval opens and closes in minutes after midnight,
+// with a close past midnight after 24:00
+func (iv Interval) minutes() (int, int) {
+	open, _ := parseClock(iv.Open)
+	close, _ := parseClock(iv.Close)
+	if close <= open {
+		close += 24 * 60
+	}
+	return open, close
+}
+
+func hasAMPM(s string) bool {
+	s = strings.ToLower(s)
+	return strings.Contains(s, "m") || s == "noon"
+}
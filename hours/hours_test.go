@@ -0,0 +1,152 @@
+package hours
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // Schedule.String, blank for an error
+	}{
+		{"Mon-Fri 8am-12pm, 1pm-5pm", "Mon-Fri 8am-12pm, 1pm-5pm"},
+		{"Daily 9-5", "Daily 9am-5pm"},
+		{"Sat, Sun 10am-2pm", "Sat-Sun 10am-2pm"},
+		{"monday 8:30a.m.-noon", "Mon 8:30am-12pm"},
+		{"Fri 8pm-2am", "Fri 8pm-2am"},
+		{"Fri-Mon 9am–1pm", "Mon 9am-1pm\nFri-Sun 9am-1pm"},
+		{"\n24/7\n\n", "24/7"},
+		{"By appointment\n2026-12-25 closed # Christmas", "By appointment\n2026-12-25 closed # Christmas"},
+		{"2026-07-06 to 2026-07-10 closed # Staff training", "2026-07-06 to 2026-07-10 closed # Staff training"},
+		{"2026-12-24 9am-12pm # Christmas Eve\nMon 8-5", "Mon 8am-5pm\n2026-12-24 9am-12pm # Christmas Eve"},
+		{"Funday 8am-5pm", ""},
+		{"Mon", ""},
+		{"Mon 8am", ""},
+		{"Mon 13pm-5pm", ""},
+		{"Mon 8am-8am", ""},
+		{"2026-07-10 to 2026-07-06 closed", ""},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.text)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Parse(%q) = %q, wanted an error", tt.text, s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %s", tt.text, err)
+		} else if got := s.String(); got != tt.want {
+			t.Errorf("Parse(%q) = %q, wanted %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Schedule
+		want string // The result as JSON, blank for an error
+	}{
+		{
+			"weekly",
+			Schedule{Weekly: []Period{{"Friday", "9am", "5pm"}, {"mon", "13:00", "17:00"}, {"Mon", "8:00", "12:00"}}},
+			`{"weekly":[{"day":"mon","open":"08:00","close":"12:00"},{"day":"mon","open":"13:00","close":"17:00"},{"day":"fri","open":"09:00","close":"17:00"}]}`,
+		},
+		{
+			"midnight",
+			Schedule{Weekly: []Period{{"sat", "24:00", "2am"}, {"fri", "8pm", "midnight"}}},
+			`{"weekly":[{"day":"fri","open":"20:00","close":"24:00"},{"day":"sat","open":"00:00","close":"02:00"}]}`,
+		},
+		{
+			"exceptions",
+			Schedule{Exceptions: []Exception{
+				{Start: "2026-12-31", End: "2026-12-31", Note: " New Year's Eve "},
+				{Start: "2026-12-24", Hours: []Interval{{"9am", "noon"}}},
+			}},
+			`{"exceptions":[{"start":"2026-12-24","hours":[{"open":"09:00","close":"12:00"}]},{"start":"2026-12-31","note":"New Year's Eve"}]}`,
+		},
+		{"bad day", Schedule{Weekly: []Period{{"xyz", "8am", "5pm"}}}, ""},
+		{"bad time", Schedule{Weekly: []Period{{"mon", "8am", "25:00"}}}, ""},
+		{"bad date", Schedule{Exceptions: []Exception{{Start: "12/25/2026"}}}, ""},
+		{"backwards", Schedule{Exceptions: []Exception{{Start: "2026-12-25", End: "2026-12-24"}}}, ""},
+	}
+	for _, tt := range tests {
+		s, err := tt.in.Normalize()
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %v, wanted an error", tt.name, s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+		} else if got, _ := json.Marshal(s); string(got) != tt.want {
+			t.Errorf("%s: got %s, wanted %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAfternoonClose(t *testing.T) {
+	tests := []struct {
+		open, close string
+		want        string
+	}{
+		{"8", "5", "17:00"},
+		{"11", "1", "13:00"},
+		{"1", "5", "5"},
+		{"8am", "5", "5"},
+		{"8", "5am", "5am"},
+		{"20:00", "02:00", "02:00"},
+		{"9", "02:00", "02:00"},
+		{"x", "5", "5"},
+	}
+	for _, tt := range tests {
+		if got := afternoonClose(Interval{Open: tt.open, Close: tt.close}); got.Close != tt.want || got.Open != tt.open {
+			t.Errorf("afternoonClose(%s-%s) = %s-%s, wanted %s-%s", tt.open, tt.close, got.Open, got.Close, tt.open, tt.want)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	// Monday, October 19th 2026
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, 10, day, hour, min, 0, 0, Zone)
+	}
+	weekdays := "Mon-Fri 8am-5pm"
+	tests := []struct {
+		schedule string
+		now      time.Time
+		open     bool
+		label    string
+	}{
+		{weekdays, at(19, 10, 0), true, "Open now until 5pm"},
+		{weekdays, at(19, 8, 0), true, "Open now until 5pm"},
+		{weekdays, at(19, 17, 0), false, "Opens tomorrow at 8am"},
+		{weekdays, at(19, 7, 30), false, "Opens at 8am"},
+		{weekdays, at(23, 18, 0), false, "Opens Mon at 8am"},
+		{weekdays + "\n2026-10-19 closed # Staff training", at(19, 10, 0), false, "Closed today (Staff training), opens tomorrow at 8am"},
+		{weekdays + "\n2026-10-19 10am-2pm", at(19, 9, 0), false, "Opens at 10am"},
+		{"Fri 8pm-2am", at(24, 1, 0), true, "Open now until 2am"},
+		{"Mon 8pm-midnight\nTue 12am-2am", at(19, 21, 0), true, "Open now until 2am"},
+		{"24/7", at(19, 3, 0), true, "Open 24 hours"},
+		{"By appointment", at(19, 10, 0), false, "By appointment"},
+		{"2026-10-19 to 2026-10-30 closed", at(19, 10, 0), false, "Closed today"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.schedule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := s.Status(tt.now)
+		if !got.Known || got.Open != tt.open || got.Label != tt.label {
+			t.Errorf("%q at %s = %+v, wanted %v %q", tt.schedule, tt.now.Format("Mon 15:04"), got, tt.open, tt.label)
+		}
+	}
+
+	if got := (Schedule{}).Status(at(19, 10, 0)); got.Known {
+		t.Errorf("An empty schedule is %+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package hours
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// How far ahead Status looks for the next opening, in days
+const lookAheadDays = 8
+
+// Status is whether a resource is open at a time, for people to read
+type Status struct {
+	Known bool   // Whether the schedule says anything
+	Open  bool   // Open at the time asked about
+	Label string // Like "Open now until 5pm" or "Opens tomorrow at 8am"
+}
+
+// span is a time a resource is open
+type span struct {
+	start, end time.Time
+}
+
+// on
+// Returns the hours for 'day' and the note of the exception that sets them,
+// if any. Exceptions replace the weekly hours for their days.
+func (s Schedule) on(day time.Time) ([]Interval, *Exception) {
+	date := day.Format(DateFormat)
+	for i := range s.Exceptions {
+		e := &s.Exceptions[i]
+		end := e.End
+		if end == "" {
+			end = e.Start
+		}
+		if date >= e.Start && date <= end {
+			return e.Hours, e
+		}
+	}
+	ret := make([]Interval, 0, 2)
+	for _, p := range s.Weekly {
+		if d, ok := dayIndex(p.Day); ok && d == day.Weekday() {
+			ret = append(ret, Interval{Open: p.Open, Close: p.Close})
+		}
+	}
+	return ret, nil
+}
+
+// spans
+// Returns when the resource is open from the day before 'from' for 'days'
+// days, in order with touching spans joined, so "until midnight" followed
+// by "from midnight" is one span
+func (s Schedule) spans(from time.Time, days int) []span {
+	ret := make([]span, 0, 0)
+	y, m, d := from.Date()
+	for i := -1; i < days; i++ {
+		ivs, _ := s.on(time.Date(y, m, d+i, 12, 0, 0, 0, Zone))
+		for _, iv := range ivs {
+			open, close := iv.minutes()
+			ret = append(ret, span{
+				start: time.Date(y, m, d+i, 0, open, 0, 0, Zone),
+				end:   time.Date(y, m, d+i, 0, close, 0, 0, Zone),
+			})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].start.Before(ret[j].start) })
+	joined := make([]span, 0, len(ret))
+	for _, sp := range ret {
+		if n := len(joined); n > 0 && !sp.start.After(joined[n-1].end) {
+			if sp.end.After(joined[n-1].end) {
+				joined[n-1].end = sp.end
+			}
+			continue
+		}
+		joined = append(joined, sp)
+	}
+	return joined
+}
+
+// Status
+// Works out whether the resource is open at 'now', and if not when it
+// opens next, in Wichita time
+func (s Schedule) Status(now time.Time) Status {
+	if s.IsEmpty() {
+		return Status{}
+	}
+	now = now.In(Zone)
+	if s.Always {
+		return Status{Known: true, Open: true, Label: "Open 24 hours"}
+	}
+	for _, sp := range s.spans(now, lookAheadDays) {
+		if sp.end.Before(now) || sp.end.Equal(now) {
+			continue
+		}
+		if !sp.start.After(now) {
+			return Status{Known: true, Open: true, Label: "Open now until " + timeLabel(sp.end, now)}
+		}
+		label := "Opens " + dayPhrase(sp.start, now) + "at " + clockLabel(sp.start.Hour()*60+sp.start.Minute())
+		if ivs, e := s.on(now); e != nil && len(ivs) == 0 {
+			label = closedToday(e) + ", " + lowerFirst(label)
+		}
+		return Status{Known: true, Label: label}
+	}
+	if _, e := s.on(now); e != nil {
+		return Status{Known: true, Label: closedToday(e)}
+	}
+	if s.ByAppointment {
+		return Status{Known: true, Label: "By appointment"}
+	}
+	return Status{Known: true, Label: "Closed"}
+}
+
+func closedToday(e *Exception) string {
+	if e.Note != "" {
+		return "Closed today (" + e.Note + ")"
+	}
+	return "Closed today"
+}
+
+// dayPhrase
+// Returns "", "tomorrow " or "Mon " for when 't' is from 'now'
+func dayPhrase(t, now time.Time) string {
+	ty, tm, td := t.Date()
+	ny, nm, nd := now.Date()
+	switch {
+	case ty == ny && tm == nm && td == nd:
+		return ""
+	case time.Date(ny, nm, nd+1, 0, 0, 0, 0, Zone).Format(DateFormat) == t.Format(DateFormat):
+		return "tomorrow "
+	}
+	return t.Weekday().String()[:3] + " "
+}
+
+// timeLabel
+// Returns a time for people to read, with the day if it isn't today or
+// just after midnight tonight
+func timeLabel(t, now time.Time) string {
+	clock := clockLabel(t.Hour()*60 + t.Minute())
+	if clock == "12am" {
+		clock = "midnight"
+	}
+	if t.Sub(now) < 24*time.Hour {
+		return clock
+	}
+	return t.Weekday().String()[:3] + " " + clock
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
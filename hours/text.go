@@ -0,0 +1,243 @@
+package hours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Parse
+// Read a schedule written one rule to a line, see the package comment.
+// Blank lines are skipped.
+func Parse(text string) (Schedule, error) {
+	var s Schedule
+	for _, line := range strings.Split(text, "\n") {
+		note := ""
+		if i := strings.Index(line, "#"); i >= 0 {
+			line, note = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case line == "":
+			continue
+		case lower == "24/7" || lower == "open 24/7" || lower == "24 hours":
+			s.Always = true
+			continue
+		case lower == "by appointment":
+			s.ByAppointment = true
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if _, err := time.Parse(DateFormat, fields[0]); err == nil {
+			e := Exception{Start: fields[0], Note: note}
+			rest := fields[1:]
+			if len(rest) >= 2 && strings.EqualFold(rest[0], "to") {
+				e.End, rest = rest[1], rest[2:]
+			}
+			ivs, err := parseIntervals(strings.Join(rest, " "))
+			if err != nil {
+				return s, fmt.Errorf("%s: %s", line, err)
+			}
+			e.Hours = ivs
+			s.Exceptions = append(s.Exceptions, e)
+			continue
+		}
+
+		// Days, then times: "Mon-Fri 8am-5pm", "Sat, Sun 9am-12pm"
+		i := 0
+		for i < len(fields) && !startsWithDigit(fields[i]) && !isTimeWord(fields[i]) {
+			i++
+		}
+		days, err := parseDays(strings.Join(fields[:i], " "))
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", line, err)
+		}
+		ivs, err := parseIntervals(strings.Join(fields[i:], " "))
+		if err != nil {
+			return s, fmt.Errorf("%s: %s", line, err)
+		}
+		for _, d := range days {
+			for _, iv := range ivs {
+				s.Weekly = append(s.Weekly, Period{Day: dayNames[d], Open: iv.Open, Close: iv.Close})
+			}
+		}
+	}
+	return s.Normalize()
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+func isTimeWord(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "closed") || strings.HasPrefix(s, "noon") || strings.HasPrefix(s, "midnight")
+}
+
+// parseDays
+// Returns the days in a list like "Mon-Fri", "Sat, Sun", "Mon-Wed, Fri" or "Daily"
+func parseDays(s string) ([]time.Weekday, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "":
+		return nil, fmt.Errorf("Which days?")
+	case "daily", "every day":
+		return []time.Weekday{1, 2, 3, 4, 5, 6, 0}, nil
+	case "weekdays":
+		return []time.Weekday{1, 2, 3, 4, 5}, nil
+	case "weekends":
+		return []time.Weekday{6, 0}, nil
+	}
+	ret := make([]time.Weekday, 0, 7)
+	for _, part := range strings.Split(s, ",") {
+		ends := strings.SplitN(part, "-", 2)
+		from, ok := dayIndex(ends[0])
+		if !ok {
+			return nil, fmt.Errorf("Invalid day: %s", strings.TrimSpace(ends[0]))
+		}
+		to := from
+		if len(ends) == 2 {
+			if to, ok = dayIndex(ends[1]); !ok {
+				return nil, fmt.Errorf("Invalid day: %s", strings.TrimSpace(ends[1]))
+			}
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			ret = append(ret, d)
+			if d == to {
+				break
+			}
+		}
+	}
+	return ret, nil
+}
+
+// parseIntervals
+// Returns the times in a list like "8am-12pm, 1pm-5pm". "closed" is none.
+func parseIntervals(s string) ([]Interval, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "closed") {
+		return nil, nil
+	}
+	if s == "" {
+		return nil, fmt.Errorf("What times? Use \"closed\" for none")
+	}
+	ret := make([]Interval, 0, 2)
+	for _, part := range strings.Split(s, ",") {
+		ends := strings.Split(strings.Replace(part, "–", "-", -1), "-")
+		if len(ends) != 2 {
+			return nil, fmt.Errorf("Times should be like 8am-5pm: %s", strings.TrimSpace(part))
+		}
+		ret = append(ret, afternoonClose(Interval{Open: strings.TrimSpace(ends[0]), Close: strings.TrimSpace(ends[1])}))
+	}
+	return ret, nil
+}
+
+// afternoonClose
+// People write "8-5" for 8am to 5pm, so a close before the open with no am
+// or pm, both in the morning, is moved to the afternoon. A close written
+// like "02:00" is already 24 hour time and is left alone. This is only for
+// text, times in a Schedule are 24 hour and a close before the open there
+// runs past midnight.
+func afternoonClose(iv Interval) Interval {
+	open, err := parseClock(iv.Open)
+	if err != nil {
+		return iv
+	}
+	close, err := parseClock(iv.Close)
+	if err != nil {
+		return iv
+	}
+	if close < open && open <= 12*60 && close < 12*60 && !hasAMPM(iv.Open) && !hasAMPM(iv.Close) &&
+		!strings.HasPrefix(iv.Close, "0") {
+		iv.Close = formatClock(close + 12*60)
+	}
+	return iv
+}
+
+// String
+// Returns the schedule one rule to a line, the reverse of Parse. Days with
+// the same hours are put together, like "Mon-Fri 8am-5pm".
+func (s Schedule) String() string {
+	lines := make([]string, 0, 0)
+	if s.Always {
+		lines = append(lines, "24/7")
+	}
+	// Monday first
+	var week [7]string
+	for _, p := range s.Weekly {
+		d, ok := dayIndex(p.Day)
+		if !ok {
+			continue
+		}
+		i := (int(d) + 6) % 7
+		if week[i] != "" {
+			week[i] += ", "
+		}
+		week[i] += Interval{Open: p.Open, Close: p.Close}.String()
+	}
+	for i := 0; i < 7; {
+		j := i
+		for j+1 < 7 && week[j+1] == week[i] {
+			j++
+		}
+		if week[i] != "" {
+			days := dayLabel(time.Weekday((i + 1) % 7))
+			if i == 0 && j == 6 {
+				days = "Daily"
+			} else if j > i {
+				days += "-" + dayLabel(time.Weekday((j+1)%7))
+			}
+			lines = append(lines, days+" "+week[i])
+		}
+		i = j + 1
+	}
+	if s.ByAppointment {
+		lines = append(lines, "By appointment")
+	}
+	for _, e := range s.Exceptions {
+		line := e.Start
+		if e.End != "" {
+			line += " to " + e.End
+		}
+		if len(e.Hours) == 0 {
+			line += " closed"
+		} else {
+			ivs := make([]string, 0, len(e.Hours))
+			for _, iv := range e.Hours {
+				ivs = append(ivs, iv.String())
+			}
+			line += " " + strings.Join(ivs, ", ")
+		}
+		if e.Note != "" {
+			line += " # " + e.Note
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String
+// Returns the interval like "8am-5:30pm"
+func (iv Interval) String() string {
+	open, _ := parseClock(iv.Open)
+	close, _ := parseClock(iv.Close)
+	return clockLabel(open) + "-" + clockLabel(close)
+}
+
+// dayLabel
+// Returns a day like "Mon"
+func dayLabel(d time.Weekday) string {
+	return d.String()[:3]
+}
+
+// clockLabel
+// Returns minutes after midnight for people to read, like "8am" or "5:30pm"
+func clockLabel(min int) string {
+	if min == 24*60 {
+		return "midnight"
+	}
+	t := time.Date(2000, 1, 1, min/60, min%60, 0, 0, time.UTC)
+	return strings.Replace(t.Format("3:04pm"), ":00", "", 1)
+}
@@ -0,0 +1,1460 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: directorypb/directory.proto
+
+// The resource directory over gRPC
+// Regenerate the Go code from the rpc directory with 'go generate'
+
+package directorypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResourceChange_Op int32
+
+const (
+	ResourceChange_OP_UNSPECIFIED ResourceChange_Op = 0
+	ResourceChange_OP_UPSERT      ResourceChange_Op = 1
+	ResourceChange_OP_DELETE      ResourceChange_Op = 2
+)
+
+// Enum value maps for ResourceChange_Op.
+var (
+	ResourceChange_Op_name = map[int32]string{
+		0: "OP_UNSPECIFIED",
+		1: "OP_UPSERT",
+		2: "OP_DELETE",
+	}
+	ResourceChange_Op_value = map[string]int32{
+		"OP_UNSPECIFIED": 0,
+		"OP_UPSERT":      1,
+		"OP_DELETE":      2,
+	}
+)
+
+func (x ResourceChange_Op) Enum() *ResourceChange_Op {
+	p := new(ResourceChange_Op)
+	*p = x
+	return p
+}
+
+func (x ResourceChange_Op) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResourceChange_Op) Descriptor() protoreflect.EnumDescriptor {
+	return file_directorypb_directory_proto_enumTypes[0].Descriptor()
+}
+
+func (ResourceChange_Op) Type() protoreflect.EnumType {
+	return &file_directorypb_directory_proto_enumTypes[0]
+}
+
+func (x ResourceChange_Op) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResourceChange_Op.Descriptor instead.
+func (ResourceChange_Op) EnumDescriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type Resource struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Title       string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Url         string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Org         string                 `protobuf:"bytes,4,opt,name=org,proto3" json:"org,omitempty"`
+	Address     string                 `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
+	Email       string                 `protobuf:"bytes,6,opt,name=email,proto3" json:"email,omitempty"`
+	Phone       string                 `protobuf:"bytes,7,opt,name=phone,proto3" json:"phone,omitempty"`
+	Hours       string                 `protobuf:"bytes,8,opt,name=hours,proto3" json:"hours,omitempty"`
+	Fees        []string               `protobuf:"bytes,9,rep,name=fees,proto3" json:"fees,omitempty"`
+	Languages   []string               `protobuf:"bytes,10,rep,name=languages,proto3" json:"languages,omitempty"`
+	Tags        []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Both 0 when the resource hasn't been located
+	Latitude  float64 `protobuf:"fixed64,12,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,13,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	// Where it serves, like "county:Sedgwick". None means anywhere.
+	ServiceAreas []string `protobuf:"bytes,14,rep,name=service_areas,json=serviceAreas,proto3" json:"service_areas,omitempty"`
+	// When it's open, unset when nobody has said
+	Schedule *Schedule `protobuf:"bytes,15,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// What it costs and how families can pay
+	Cost *Cost `protobuf:"bytes,16,opt,name=cost,proto3" json:"cost,omitempty"`
+	// Who it's for
+	Eligibility *Eligibility `protobuf:"bytes,17,opt,name=eligibility,proto3" json:"eligibility,omitempty"`
+	// The organization that runs it and the locations it's offered at, from
+	// /api/orgs. The address and coordinates are the first location's.
+	OrgId         string   `protobuf:"bytes,18,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	LocationIds   []string `protobuf:"bytes,19,rep,name=location_ids,json=locationIds,proto3" json:"location_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resource) Reset() {
+	*x = Resource{}
+	mi := &file_directorypb_directory_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resource) ProtoMessage() {}
+
+func (x *Resource) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resource.ProtoReflect.Descriptor instead.
+func (*Resource) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Resource) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Resource) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Resource) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Resource) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *Resource) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Resource) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Resource) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Resource) GetHours() string {
+	if x != nil {
+		return x.Hours
+	}
+	return ""
+}
+
+func (x *Resource) GetFees() []string {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+func (x *Resource) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *Resource) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Resource) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Resource) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Resource) GetServiceAreas() []string {
+	if x != nil {
+		return x.ServiceAreas
+	}
+	return nil
+}
+
+func (x *Resource) GetSchedule() *Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
+func (x *Resource) GetCost() *Cost {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *Resource) GetEligibility() *Eligibility {
+	if x != nil {
+		return x.Eligibility
+	}
+	return nil
+}
+
+func (x *Resource) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *Resource) GetLocationIds() []string {
+	if x != nil {
+		return x.LocationIds
+	}
+	return nil
+}
+
+type Cost struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Free  bool                   `protobuf:"varint,1,opt,name=free,proto3" json:"free,omitempty"`
+	// Based on income
+	SlidingScale bool `protobuf:"varint,2,opt,name=sliding_scale,json=slidingScale,proto3" json:"sliding_scale,omitempty"`
+	// Like "$25 per visit"
+	FlatFee string `protobuf:"bytes,3,opt,name=flat_fee,json=flatFee,proto3" json:"flat_fee,omitempty"`
+	// Accepts KanCare/Medicaid
+	Medicaid         bool `protobuf:"varint,4,opt,name=medicaid,proto3" json:"medicaid,omitempty"`
+	Chip             bool `protobuf:"varint,5,opt,name=chip,proto3" json:"chip,omitempty"`
+	PrivateInsurance bool `protobuf:"varint,6,opt,name=private_insurance,json=privateInsurance,proto3" json:"private_insurance,omitempty"`
+	// Uninsured families welcome
+	Uninsured     bool   `protobuf:"varint,7,opt,name=uninsured,proto3" json:"uninsured,omitempty"`
+	Notes         string `protobuf:"bytes,8,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cost) Reset() {
+	*x = Cost{}
+	mi := &file_directorypb_directory_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cost) ProtoMessage() {}
+
+func (x *Cost) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cost.ProtoReflect.Descriptor instead.
+func (*Cost) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Cost) GetFree() bool {
+	if x != nil {
+		return x.Free
+	}
+	return false
+}
+
+func (x *Cost) GetSlidingScale() bool {
+	if x != nil {
+		return x.SlidingScale
+	}
+	return false
+}
+
+func (x *Cost) GetFlatFee() string {
+	if x != nil {
+		return x.FlatFee
+	}
+	return ""
+}
+
+func (x *Cost) GetMedicaid() bool {
+	if x != nil {
+		return x.Medicaid
+	}
+	return false
+}
+
+func (x *Cost) GetChip() bool {
+	if x != nil {
+		return x.Chip
+	}
+	return false
+}
+
+func (x *Cost) GetPrivateInsurance() bool {
+	if x != nil {
+		return x.PrivateInsurance
+	}
+	return false
+}
+
+func (x *Cost) GetUninsured() bool {
+	if x != nil {
+		return x.Uninsured
+	}
+	return false
+}
+
+func (x *Cost) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+// Eligibility is who a resource is for. Blank fields don't limit anything.
+type Eligibility struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Years, like 0-19 for teen parents
+	ParentAge *Eligibility_AgeRange `protobuf:"bytes,1,opt,name=parent_age,json=parentAge,proto3" json:"parent_age,omitempty"`
+	// Months, like 0-11 for under 1 year
+	ChildAge *Eligibility_AgeRange `protobuf:"bytes,2,opt,name=child_age,json=childAge,proto3" json:"child_age,omitempty"`
+	// first_trimester, second_trimester, third_trimester or postpartum
+	Pregnancy []string `protobuf:"bytes,3,rep,name=pregnancy,proto3" json:"pregnancy,omitempty"`
+	// Household income at or below this percent of the federal poverty level
+	IncomeFpl int32 `protobuf:"varint,4,opt,name=income_fpl,json=incomeFpl,proto3" json:"income_fpl,omitempty"`
+	// Where the family has to live, like "county:Sedgwick"
+	Residency []string `protobuf:"bytes,5,rep,name=residency,proto3" json:"residency,omitempty"`
+	// medicaid, chip, private or uninsured, the family needs one
+	Insurance     []string `protobuf:"bytes,6,rep,name=insurance,proto3" json:"insurance,omitempty"`
+	Notes         string   `protobuf:"bytes,7,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Eligibility) Reset() {
+	*x = Eligibility{}
+	mi := &file_directorypb_directory_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Eligibility) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Eligibility) ProtoMessage() {}
+
+func (x *Eligibility) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Eligibility.ProtoReflect.Descriptor instead.
+func (*Eligibility) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Eligibility) GetParentAge() *Eligibility_AgeRange {
+	if x != nil {
+		return x.ParentAge
+	}
+	return nil
+}
+
+func (x *Eligibility) GetChildAge() *Eligibility_AgeRange {
+	if x != nil {
+		return x.ChildAge
+	}
+	return nil
+}
+
+func (x *Eligibility) GetPregnancy() []string {
+	if x != nil {
+		return x.Pregnancy
+	}
+	return nil
+}
+
+func (x *Eligibility) GetIncomeFpl() int32 {
+	if x != nil {
+		return x.IncomeFpl
+	}
+	return 0
+}
+
+func (x *Eligibility) GetResidency() []string {
+	if x != nil {
+		return x.Residency
+	}
+	return nil
+}
+
+func (x *Eligibility) GetInsurance() []string {
+	if x != nil {
+		return x.Insurance
+	}
+	return nil
+}
+
+func (x *Eligibility) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+// Schedule is when a resource is open. Times are 24 hour, like "08:00", and
+// a close before the open runs past midnight.
+type Schedule struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Open every day and night
+	Always        bool                  `protobuf:"varint,1,opt,name=always,proto3" json:"always,omitempty"`
+	ByAppointment bool                  `protobuf:"varint,2,opt,name=by_appointment,json=byAppointment,proto3" json:"by_appointment,omitempty"`
+	Weekly        []*Schedule_Period    `protobuf:"bytes,3,rep,name=weekly,proto3" json:"weekly,omitempty"`
+	Exceptions    []*Schedule_Exception `protobuf:"bytes,4,rep,name=exceptions,proto3" json:"exceptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_directorypb_directory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Schedule) GetAlways() bool {
+	if x != nil {
+		return x.Always
+	}
+	return false
+}
+
+func (x *Schedule) GetByAppointment() bool {
+	if x != nil {
+		return x.ByAppointment
+	}
+	return false
+}
+
+func (x *Schedule) GetWeekly() []*Schedule_Period {
+	if x != nil {
+		return x.Weekly
+	}
+	return nil
+}
+
+func (x *Schedule) GetExceptions() []*Schedule_Exception {
+	if x != nil {
+		return x.Exceptions
+	}
+	return nil
+}
+
+type ListResourcesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to 50, at most 500
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token from the last response
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResourcesRequest) Reset() {
+	*x = ListResourcesRequest{}
+	mi := &file_directorypb_directory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResourcesRequest) ProtoMessage() {}
+
+func (x *ListResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResourcesRequest.ProtoReflect.Descriptor instead.
+func (*ListResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListResourcesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListResourcesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListResourcesResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Resources []*Resource            `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	// Blank on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResourcesResponse) Reset() {
+	*x = ListResourcesResponse{}
+	mi := &file_directorypb_directory_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResourcesResponse) ProtoMessage() {}
+
+func (x *ListResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResourcesResponse.ProtoReflect.Descriptor instead.
+func (*ListResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListResourcesResponse) GetResources() []*Resource {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *ListResourcesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetResourceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResourceRequest) Reset() {
+	*x = GetResourceRequest{}
+	mi := &file_directorypb_directory_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResourceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResourceRequest) ProtoMessage() {}
+
+func (x *GetResourceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResourceRequest.ProtoReflect.Descriptor instead.
+func (*GetResourceRequest) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetResourceRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+type SearchResourcesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Text to look for in the title, description, org and tags
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Resources must have all of these tags
+	Tags []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	Org  string   `protobuf:"bytes,3,opt,name=org,proto3" json:"org,omitempty"`
+	// Resources must offer one of these languages
+	Languages []string `protobuf:"bytes,4,rep,name=languages,proto3" json:"languages,omitempty"`
+	// Resources must have one of these fees
+	Fees      []string `protobuf:"bytes,5,rep,name=fees,proto3" json:"fees,omitempty"`
+	PageSize  int32    `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string   `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Where the family is, a ZIP code or "lat,lng", used with within
+	Near string `protobuf:"bytes,8,opt,name=near,proto3" json:"near,omitempty"`
+	// Resources must be this many miles from near
+	Within float64 `protobuf:"fixed64,9,opt,name=within,proto3" json:"within,omitempty"`
+	// Leave out resources that don't serve near
+	Serves bool `protobuf:"varint,10,opt,name=serves,proto3" json:"serves,omitempty"`
+	// Only resources that are open now
+	OpenNow bool `protobuf:"varint,11,opt,name=open_now,json=openNow,proto3" json:"open_now,omitempty"`
+	// Resources must have one of these, like "free" or "Accepts CHIP"
+	Cost          []string `protobuf:"bytes,12,rep,name=cost,proto3" json:"cost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResourcesRequest) Reset() {
+	*x = SearchResourcesRequest{}
+	mi := &file_directorypb_directory_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResourcesRequest) ProtoMessage() {}
+
+func (x *SearchResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResourcesRequest.ProtoReflect.Descriptor instead.
+func (*SearchResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchResourcesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchResourcesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SearchResourcesRequest) GetOrg() string {
+	if x != nil {
+		return x.Org
+	}
+	return ""
+}
+
+func (x *SearchResourcesRequest) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *SearchResourcesRequest) GetFees() []string {
+	if x != nil {
+		return x.Fees
+	}
+	return nil
+}
+
+func (x *SearchResourcesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchResourcesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchResourcesRequest) GetNear() string {
+	if x != nil {
+		return x.Near
+	}
+	return ""
+}
+
+func (x *SearchResourcesRequest) GetWithin() float64 {
+	if x != nil {
+		return x.Within
+	}
+	return 0
+}
+
+func (x *SearchResourcesRequest) GetServes() bool {
+	if x != nil {
+		return x.Serves
+	}
+	return false
+}
+
+func (x *SearchResourcesRequest) GetOpenNow() bool {
+	if x != nil {
+		return x.OpenNow
+	}
+	return false
+}
+
+func (x *SearchResourcesRequest) GetCost() []string {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+type SearchResourcesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Resources     []*Resource            `protobuf:"bytes,1,rep,name=resources,proto3" json:"resources,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// How many resources matched, across every page
+	TotalSize     int32 `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResourcesResponse) Reset() {
+	*x = SearchResourcesResponse{}
+	mi := &file_directorypb_directory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResourcesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResourcesResponse) ProtoMessage() {}
+
+func (x *SearchResourcesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResourcesResponse.ProtoReflect.Descriptor instead.
+func (*SearchResourcesResponse) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchResourcesResponse) GetResources() []*Resource {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *SearchResourcesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *SearchResourcesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+type WatchResourcesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Replay the changes after this sequence before streaming new ones. This
+	// can be the sync_token from /api/sync or the seq of the last change seen.
+	// Leave it at 0 to only get changes saved from now on.
+	Since         uint64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResourcesRequest) Reset() {
+	*x = WatchResourcesRequest{}
+	mi := &file_directorypb_directory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResourcesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResourcesRequest) ProtoMessage() {}
+
+func (x *WatchResourcesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResourcesRequest.ProtoReflect.Descriptor instead.
+func (*WatchResourcesRequest) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchResourcesRequest) GetSince() uint64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type ResourceChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seq   uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Op    ResourceChange_Op      `protobuf:"varint,2,opt,name=op,proto3,enum=infantinfo.directory.ResourceChange_Op" json:"op,omitempty"`
+	Title string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	// Only set for upserts
+	Resource      *Resource `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceChange) Reset() {
+	*x = ResourceChange{}
+	mi := &file_directorypb_directory_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceChange) ProtoMessage() {}
+
+func (x *ResourceChange) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceChange.ProtoReflect.Descriptor instead.
+func (*ResourceChange) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResourceChange) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ResourceChange) GetOp() ResourceChange_Op {
+	if x != nil {
+		return x.Op
+	}
+	return ResourceChange_OP_UNSPECIFIED
+}
+
+func (x *ResourceChange) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ResourceChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ResourceChange) GetResource() *Resource {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+// From min to max inclusive, a max of 0 means no limit
+type Eligibility_AgeRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           int32                  `protobuf:"varint,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           int32                  `protobuf:"varint,2,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Eligibility_AgeRange) Reset() {
+	*x = Eligibility_AgeRange{}
+	mi := &file_directorypb_directory_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Eligibility_AgeRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Eligibility_AgeRange) ProtoMessage() {}
+
+func (x *Eligibility_AgeRange) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Eligibility_AgeRange.ProtoReflect.Descriptor instead.
+func (*Eligibility_AgeRange) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *Eligibility_AgeRange) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Eligibility_AgeRange) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+// Period is a time it's open every week
+type Schedule_Period struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// mon, tue, wed, thu, fri, sat or sun
+	Day           string `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Open          string `protobuf:"bytes,2,opt,name=open,proto3" json:"open,omitempty"`
+	Close         string `protobuf:"bytes,3,opt,name=close,proto3" json:"close,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule_Period) Reset() {
+	*x = Schedule_Period{}
+	mi := &file_directorypb_directory_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule_Period) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule_Period) ProtoMessage() {}
+
+func (x *Schedule_Period) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule_Period.ProtoReflect.Descriptor instead.
+func (*Schedule_Period) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *Schedule_Period) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *Schedule_Period) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Schedule_Period) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+// Exception replaces the weekly hours from start to end, YYYY-MM-DD.
+// No hours means closed.
+type Schedule_Exception struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Start string                 `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	// Blank for just the start date
+	End           string               `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	Hours         []*Schedule_Interval `protobuf:"bytes,3,rep,name=hours,proto3" json:"hours,omitempty"`
+	Note          string               `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule_Exception) Reset() {
+	*x = Schedule_Exception{}
+	mi := &file_directorypb_directory_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule_Exception) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule_Exception) ProtoMessage() {}
+
+func (x *Schedule_Exception) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule_Exception.ProtoReflect.Descriptor instead.
+func (*Schedule_Exception) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *Schedule_Exception) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *Schedule_Exception) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *Schedule_Exception) GetHours() []*Schedule_Interval {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+func (x *Schedule_Exception) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type Schedule_Interval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Open          string                 `protobuf:"bytes,1,opt,name=open,proto3" json:"open,omitempty"`
+	Close         string                 `protobuf:"bytes,2,opt,name=close,proto3" json:"close,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule_Interval) Reset() {
+	*x = Schedule_Interval{}
+	mi := &file_directorypb_directory_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule_Interval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule_Interval) ProtoMessage() {}
+
+func (x *Schedule_Interval) ProtoReflect() protoreflect.Message {
+	mi := &file_directorypb_directory_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule_Interval.ProtoReflect.Descriptor instead.
+func (*Schedule_Interval) Descriptor() ([]byte, []int) {
+	return file_directorypb_directory_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *Schedule_Interval) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Schedule_Interval) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+var File_directorypb_directory_proto protoreflect.FileDescriptor
+
+const file_directorypb_directory_proto_rawDesc = "" +
+	"\n" +
+	"\x1bdirectorypb/directory.proto\x12\x14infantinfo.directory\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd2\x04\n" +
+	"\bResource\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x10\n" +
+	"\x03org\x18\x04 \x01(\tR\x03org\x12\x18\n" +
+	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05email\x18\x06 \x01(\tR\x05email\x12\x14\n" +
+	"\x05phone\x18\a \x01(\tR\x05phone\x12\x14\n" +
+	"\x05hours\x18\b \x01(\tR\x05hours\x12\x12\n" +
+	"\x04fees\x18\t \x03(\tR\x04fees\x12\x1c\n" +
+	"\tlanguages\x18\n" +
+	" \x03(\tR\tlanguages\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12\x1a\n" +
+	"\blatitude\x18\f \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\r \x01(\x01R\tlongitude\x12#\n" +
+	"\rservice_areas\x18\x0e \x03(\tR\fserviceAreas\x12:\n" +
+	"\bschedule\x18\x0f \x01(\v2\x1e.infantinfo.directory.ScheduleR\bschedule\x12.\n" +
+	"\x04cost\x18\x10 \x01(\v2\x1a.infantinfo.directory.CostR\x04cost\x12C\n" +
+	"\veligibility\x18\x11 \x01(\v2!.infantinfo.directory.EligibilityR\veligibility\x12\x15\n" +
+	"\x06org_id\x18\x12 \x01(\tR\x05orgId\x12!\n" +
+	"\flocation_ids\x18\x13 \x03(\tR\vlocationIds\"\xeb\x01\n" +
+	"\x04Cost\x12\x12\n" +
+	"\x04free\x18\x01 \x01(\bR\x04free\x12#\n" +
+	"\rsliding_scale\x18\x02 \x01(\bR\fslidingScale\x12\x19\n" +
+	"\bflat_fee\x18\x03 \x01(\tR\aflatFee\x12\x1a\n" +
+	"\bmedicaid\x18\x04 \x01(\bR\bmedicaid\x12\x12\n" +
+	"\x04chip\x18\x05 \x01(\bR\x04chip\x12+\n" +
+	"\x11private_insurance\x18\x06 \x01(\bR\x10privateInsurance\x12\x1c\n" +
+	"\tuninsured\x18\a \x01(\bR\tuninsured\x12\x14\n" +
+	"\x05notes\x18\b \x01(\tR\x05notes\"\xe0\x02\n" +
+	"\vEligibility\x12I\n" +
+	"\n" +
+	"parent_age\x18\x01 \x01(\v2*.infantinfo.directory.Eligibility.AgeRangeR\tparentAge\x12G\n" +
+	"\tchild_age\x18\x02 \x01(\v2*.infantinfo.directory.Eligibility.AgeRangeR\bchildAge\x12\x1c\n" +
+	"\tpregnancy\x18\x03 \x03(\tR\tpregnancy\x12\x1d\n" +
+	"\n" +
+	"income_fpl\x18\x04 \x01(\x05R\tincomeFpl\x12\x1c\n" +
+	"\tresidency\x18\x05 \x03(\tR\tresidency\x12\x1c\n" +
+	"\tinsurance\x18\x06 \x03(\tR\tinsurance\x12\x14\n" +
+	"\x05notes\x18\a \x01(\tR\x05notes\x1a.\n" +
+	"\bAgeRange\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x05R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x05R\x03max\"\xd7\x03\n" +
+	"\bSchedule\x12\x16\n" +
+	"\x06always\x18\x01 \x01(\bR\x06always\x12%\n" +
+	"\x0eby_appointment\x18\x02 \x01(\bR\rbyAppointment\x12=\n" +
+	"\x06weekly\x18\x03 \x03(\v2%.infantinfo.directory.Schedule.PeriodR\x06weekly\x12H\n" +
+	"\n" +
+	"exceptions\x18\x04 \x03(\v2(.infantinfo.directory.Schedule.ExceptionR\n" +
+	"exceptions\x1aD\n" +
+	"\x06Period\x12\x10\n" +
+	"\x03day\x18\x01 \x01(\tR\x03day\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\tR\x04open\x12\x14\n" +
+	"\x05close\x18\x03 \x01(\tR\x05close\x1a\x86\x01\n" +
+	"\tException\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\tR\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\tR\x03end\x12=\n" +
+	"\x05hours\x18\x03 \x03(\v2'.infantinfo.directory.Schedule.IntervalR\x05hours\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\x1a4\n" +
+	"\bInterval\x12\x12\n" +
+	"\x04open\x18\x01 \x01(\tR\x04open\x12\x14\n" +
+	"\x05close\x18\x02 \x01(\tR\x05close\"R\n" +
+	"\x14ListResourcesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"}\n" +
+	"\x15ListResourcesResponse\x12<\n" +
+	"\tresources\x18\x01 \x03(\v2\x1e.infantinfo.directory.ResourceR\tresources\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"*\n" +
+	"\x12GetResourceRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\"\xb5\x02\n" +
+	"\x16SearchResourcesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12\x10\n" +
+	"\x03org\x18\x03 \x01(\tR\x03org\x12\x1c\n" +
+	"\tlanguages\x18\x04 \x03(\tR\tlanguages\x12\x12\n" +
+	"\x04fees\x18\x05 \x03(\tR\x04fees\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\x12\x12\n" +
+	"\x04near\x18\b \x01(\tR\x04near\x12\x16\n" +
+	"\x06within\x18\t \x01(\x01R\x06within\x12\x16\n" +
+	"\x06serves\x18\n" +
+	" \x01(\bR\x06serves\x12\x19\n" +
+	"\bopen_now\x18\v \x01(\bR\aopenNow\x12\x12\n" +
+	"\x04cost\x18\f \x03(\tR\x04cost\"\x9e\x01\n" +
+	"\x17SearchResourcesResponse\x12<\n" +
+	"\tresources\x18\x01 \x03(\v2\x1e.infantinfo.directory.ResourceR\tresources\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"-\n" +
+	"\x15WatchResourcesRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x04R\x05since\"\x95\x02\n" +
+	"\x0eResourceChange\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x127\n" +
+	"\x02op\x18\x02 \x01(\x0e2'.infantinfo.directory.ResourceChange.OpR\x02op\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12.\n" +
+	"\x04time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12:\n" +
+	"\bresource\x18\x05 \x01(\v2\x1e.infantinfo.directory.ResourceR\bresource\"6\n" +
+	"\x02Op\x12\x12\n" +
+	"\x0eOP_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tOP_UPSERT\x10\x01\x12\r\n" +
+	"\tOP_DELETE\x10\x022\xa5\x03\n" +
+	"\tDirectory\x12h\n" +
+	"\rListResources\x12*.infantinfo.directory.ListResourcesRequest\x1a+.infantinfo.directory.ListResourcesResponse\x12W\n" +
+	"\vGetResource\x12(.infantinfo.directory.GetResourceRequest\x1a\x1e.infantinfo.directory.Resource\x12n\n" +
+	"\x0fSearchResources\x12,.infantinfo.directory.SearchResourcesRequest\x1a-.infantinfo.directory.SearchResourcesResponse\x12e\n" +
+	"\x0eWatchResources\x12+.infantinfo.directory.WatchResourcesRequest\x1a$.infantinfo.directory.ResourceChange0\x01B4Z2github.com/openwichita/infant-info/rpc/directorypbb\x06proto3"
+
+var (
+	file_directorypb_directory_proto_rawDescOnce sync.Once
+	file_directorypb_directory_proto_rawDescData []byte
+)
+
+func file_directorypb_directory_proto_rawDescGZIP() []byte {
+	file_directorypb_directory_proto_rawDescOnce.Do(func() {
+		file_directorypb_directory_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_directorypb_directory_proto_rawDesc), len(file_directorypb_directory_proto_rawDesc)))
+	})
+	return file_directorypb_directory_proto_rawDescData
+}
+
+var file_directorypb_directory_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_directorypb_directory_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_directorypb_directory_proto_goTypes = []any{
+	(ResourceChange_Op)(0),          // 0: infantinfo.directory.ResourceChange.Op
+	(*Resource)(nil),                // 1: infantinfo.directory.Resource
+	(*Cost)(nil),                    // 2: infantinfo.directory.Cost
+	(*Eligibility)(nil),             // 3: infantinfo.directory.Eligibility
+	(*Schedule)(nil),                // 4: infantinfo.directory.Schedule
+	(*ListResourcesRequest)(nil),    // 5: infantinfo.directory.ListResourcesRequest
+	(*ListResourcesResponse)(nil),   // 6: infantinfo.directory.ListResourcesResponse
+	(*GetResourceRequest)(nil),      // 7: infantinfo.directory.GetResourceRequest
+	(*SearchResourcesRequest)(nil),  // 8: infantinfo.directory.SearchResourcesRequest
+	(*SearchResourcesResponse)(nil), // 9: infantinfo.directory.SearchResourcesResponse
+	(*WatchResourcesRequest)(nil),   // 10: infantinfo.directory.WatchResourcesRequest
+	(*ResourceChange)(nil),          // 11: infantinfo.directory.ResourceChange
+	(*Eligibility_AgeRange)(nil),    // 12: infantinfo.directory.Eligibility.AgeRange
+	(*Schedule_Period)(nil),         // 13: infantinfo.directory.Schedule.Period
+	(*Schedule_Exception)(nil),      // 14: infantinfo.directory.Schedule.Exception
+	(*Schedule_Interval)(nil),       // 15: infantinfo.directory.Schedule.Interval
+	(*timestamppb.Timestamp)(nil),   // 16: google.protobuf.Timestamp
+}
+var file_directorypb_directory_proto_depIdxs = []int32{
+	4,  // 0: infantinfo.directory.Resource.schedule:type_name -> infantinfo.directory.Schedule
+	2,  // 1: infantinfo.directory.Resource.cost:type_name -> infantinfo.directory.Cost
+	3,  // 2: infantinfo.directory.Resource.eligibility:type_name -> infantinfo.directory.Eligibility
+	12, // 3: infantinfo.directory.Eligibility.parent_age:type_name -> infantinfo.directory.Eligibility.AgeRange
+	12, // 4: infantinfo.directory.Eligibility.child_age:type_name -> infantinfo.directory.Eligibility.AgeRange
+	13, // 5: infantinfo.directory.Schedule.weekly:type_name -> infantinfo.directory.Schedule.Period
+	14, // 6: infantinfo.directory.Schedule.exceptions:type_name -> infantinfo.directory.Schedule.Exception
+	1,  // 7: infantinfo.directory.ListResourcesResponse.resources:type_name -> infantinfo.directory.Resource
+	1,  // 8: infantinfo.directory.SearchResourcesResponse.resources:type_name -> infantinfo.directory.Resource
+	0,  // 9: infantinfo.directory.ResourceChange.op:type_name -> infantinfo.directory.ResourceChange.Op
+	16, // 10: infantinfo.directory.ResourceChange.time:type_name -> google.protobuf.Timestamp
+	1,  // 11: infantinfo.directory.ResourceChange.resource:type_name -> infantinfo.directory.Resource
+	15, // 12: infantinfo.directory.Schedule.Exception.hours:type_name -> infantinfo.directory.Schedule.Interval
+	5,  // 13: infantinfo.directory.Directory.ListResources:input_type -> infantinfo.directory.ListResourcesRequest
+	7,  // 14: infantinfo.directory.Directory.GetResource:input_type -> infantinfo.directory.GetResourceRequest
+	8,  // 15: infantinfo.directory.Directory.SearchResources:input_type -> infantinfo.directory.SearchResourcesRequest
+	10, // 16: infantinfo.directory.Directory.WatchResources:input_type -> infantinfo.directory.WatchResourcesRequest
+	6,  // 17: infantinfo.directory.Directory.ListResources:output_type -> infantinfo.directory.ListResourcesResponse
+	1,  // 18: infantinfo.directory.Directory.GetResource:output_type -> infantinfo.directory.Resource
+	9,  // 19: infantinfo.directory.Directory.SearchResources:output_type -> infantinfo.directory.SearchResourcesResponse
+	11, // 20: infantinfo.directory.Directory.WatchResources:output_type -> infantinfo.directory.ResourceChange
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_directorypb_directory_proto_init() }
+func file_directorypb_directory_proto_init() {
+	if File_directorypb_directory_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_directorypb_directory_proto_rawDesc), len(file_directorypb_directory_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_directorypb_directory_proto_goTypes,
+		DependencyIndexes: file_directorypb_directory_proto_depIdxs,
+		EnumInfos:         file_directorypb_directory_proto_enumTypes,
+		MessageInfos:      file_directorypb_directory_proto_msgTypes,
+	}.Build()
+	File_directorypb_directory_proto = out.File
+	file_directorypb_directory_proto_goTypes = nil
+	file_directorypb_directory_proto_depIdxs = nil
+}
@@ -0,0 +1,312 @@
+// Package rpc serves the resource directory over gRPC, see
+// directorypb/directory.proto for the service definition.
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative directorypb/directory.proto
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/hours"
+	"github.com/openwichita/infant-info/rpc/directorypb"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Default and maximum page size for List and Search
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// How many changes a watcher reads from the change log at a time
+const watchBatchSize = 200
+
+// Server implements directorypb.DirectoryServer on top of a store.Store
+type Server struct {
+	directorypb.UnimplementedDirectoryServer
+
+	store  *store.Store
+	output func(string)
+
+	// Geo finds where a search is near, see geo.Gazetteer.Locate
+	Geo *geo.Gazetteer
+
+	mu       sync.Mutex
+	watchers map[chan struct{}]bool
+}
+
+// New
+// Returns a Server for 'st'. Progress is written to 'output', which may be nil.
+func New(st *store.Store, output func(string)) *Server {
+	if output == nil {
+		output = func(string) {}
+	}
+	s := &Server{
+		store:    st,
+		output:   output,
+		watchers: make(map[chan struct{}]bool),
+	}
+	st.OnChange(s.wakeWatchers)
+	return s
+}
+
+// Register
+// Add the Directory service to a gRPC server
+func (s *Server) Register(g *grpc.Server) {
+	directorypb.RegisterDirectoryServer(g, s)
+}
+
+// ListResources
+// Returns a page of resources, sorted by title
+func (s *Server) ListResources(ctx context.Context, req *directorypb.ListResourcesRequest) (*directorypb.ListResourcesResponse, error) {
+	s.output("gRPC Request: ListResources\n")
+	resources, err := s.store.Resources()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	page, next, err := pageResources(resources, req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	return &directorypb.ListResourcesResponse{Resources: page, NextPageToken: next}, nil
+}
+
+// GetResource
+// Returns a single resource
+func (s *Server) GetResource(ctx context.Context, req *directorypb.GetResourceRequest) (*directorypb.Resource, error) {
+	s.output(fmt.Sprintf("gRPC Request: GetResource %s\n", req.GetTitle()))
+	res, err := s.store.Resource(req.GetTitle())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProto(res), nil
+}
+
+// SearchResources
+// Returns a page of the resources that match the request, sorted by title
+func (s *Server) SearchResources(ctx context.Context, req *directorypb.SearchResourcesRequest) (*directorypb.SearchResourcesResponse, error) {
+	s.output(fmt.Sprintf("gRPC Request: SearchResources %q\n", req.GetQuery()))
+	resources, err := s.store.Resources()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	f := search.Filter{
+		Query:     req.GetQuery(),
+		Tags:      req.GetTags(),
+		Org:       req.GetOrg(),
+		Languages: req.GetLanguages(),
+		Fees:      req.GetFees(),
+		Near:      req.GetNear(),
+		Within:    req.GetWithin(),
+		Serves:    req.GetServes(),
+	}
+	if req.GetOpenNow() {
+		f.Hours = []string{search.HoursOpenNow}
+	}
+	for _, c := range req.GetCost() {
+		f.Cost = append(f.Cost, store.CostLabel(c))
+	}
+	if f.Near != "" {
+		place, err := s.Geo.Locate(f.Near)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		f.Origin = &place
+	}
+	matches := f.Apply(resources)
+	page, next, err := pageResources(matches, req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	return &directorypb.SearchResourcesResponse{
+		Resources:     page,
+		NextPageToken: next,
+		TotalSize:     int32(len(matches)),
+	}, nil
+}
+
+// WatchResources
+// Streams changes from the change log, first any after req.Since and then
+// each one as it is saved, until the client goes away
+func (s *Server) WatchResources(req *directorypb.WatchResourcesRequest, stream directorypb.Directory_WatchResourcesServer) error {
+	s.output(fmt.Sprintf("gRPC Request: WatchResources since %d\n", req.GetSince()))
+	wake := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers[wake] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, wake)
+		s.mu.Unlock()
+	}()
+
+	since := req.GetSince()
+	if since == 0 {
+		var err error
+		if since, err = s.store.Sequence(); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	} else {
+		// Replay what has already happened
+		wake <- struct{}{}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-wake:
+		}
+		for more := true; more; {
+			var changes []store.Change
+			var err error
+			if changes, since, more, err = s.store.Changes(since, watchBatchSize); err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			for i := range changes {
+				if err := stream.Send(changeToProto(changes[i])); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// wakeWatchers
+// Let every watcher know that there are new changes to send
+func (s *Server) wakeWatchers(ev store.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.watchers {
+		select {
+		case w <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// pageResources
+// Sort 'resources' by title and return the page after 'token'
+func pageResources(resources []store.Resource, size int32, token string) ([]*directorypb.Resource, string, error) {
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Title < resources[j].Title })
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+	start := 0
+	if token != "" {
+		after, err := decodePageToken(token)
+		if err != nil {
+			return nil, "", status.Error(codes.InvalidArgument, err.Error())
+		}
+		start = sort.Search(len(resources), func(i int) bool { return resources[i].Title > after })
+	}
+	ret := make([]*directorypb.Resource, 0, size)
+	for i := start; i < len(resources) && len(ret) < int(size); i++ {
+		ret = append(ret, toProto(resources[i]))
+	}
+	next := ""
+	if start+len(ret) < len(resources) {
+		next = encodePageToken(ret[len(ret)-1].Title)
+	}
+	return ret, next, nil
+}
+
+func encodePageToken(title string) string {
+	return base64.URLEncoding.EncodeToString([]byte("resource:" + title))
+}
+
+func decodePageToken(token string) (string, error) {
+	b, err := base64.URLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(b), "resource:") {
+		return "", fmt.Errorf("Invalid page token: %s", token)
+	}
+	return strings.TrimPrefix(string(b), "resource:"), nil
+}
+
+func toProto(res store.Resource) *directorypb.Resource {
+	return &directorypb.Resource{
+		Title:        res.Title,
+		Description:  res.Description,
+		Url:          res.URL,
+		Org:          res.Org,
+		Address:      res.Address,
+		Email:        res.Email,
+		Phone:        res.Phone,
+		Hours:        res.Hours,
+		Fees:         res.Fees,
+		Languages:    res.Languages,
+		Tags:         res.Tags,
+		Latitude:     res.Latitude,
+		Longitude:    res.Longitude,
+		ServiceAreas: res.ServiceAreas,
+		Schedule:     scheduleToProto(res.Schedule),
+		Cost: &directorypb.Cost{
+			Free:             res.Cost.Free,
+			SlidingScale:     res.Cost.SlidingScale,
+			FlatFee:          res.Cost.FlatFee,
+			Medicaid:         res.Cost.Medicaid,
+			Chip:             res.Cost.CHIP,
+			PrivateInsurance: res.Cost.PrivateInsurance,
+			Uninsured:        res.Cost.Uninsured,
+			Notes:            res.Cost.Notes,
+		},
+		Eligibility: &directorypb.Eligibility{
+			ParentAge: &directorypb.Eligibility_AgeRange{Min: int32(res.Eligibility.ParentAge.Min), Max: int32(res.Eligibility.ParentAge.Max)},
+			ChildAge:  &directorypb.Eligibility_AgeRange{Min: int32(res.Eligibility.ChildAge.Min), Max: int32(res.Eligibility.ChildAge.Max)},
+			Pregnancy: res.Eligibility.Pregnancy,
+			IncomeFpl: int32(res.Eligibility.IncomeFPL),
+			Residency: res.Eligibility.Residency,
+			Insurance: res.Eligibility.Insurance,
+			Notes:     res.Eligibility.Notes,
+		},
+		OrgId:       res.OrgID,
+		LocationIds: res.LocationIDs,
+	}
+}
+
+func scheduleToProto(s hours.Schedule) *directorypb.Schedule {
+	if s.IsEmpty() {
+		return nil
+	}
+	ret := &directorypb.Schedule{Always: s.Always, ByAppointment: s.ByAppointment}
+	for _, p := range s.Weekly {
+		ret.Weekly = append(ret.Weekly, &directorypb.Schedule_Period{Day: p.Day, Open: p.Open, Close: p.Close})
+	}
+	for _, e := range s.Exceptions {
+		pe := &directorypb.Schedule_Exception{Start: e.Start, End: e.End, Note: e.Note}
+		for _, iv := range e.Hours {
+			pe.Hours = append(pe.Hours, &directorypb.Schedule_Interval{Open: iv.Open, Close: iv.Close})
+		}
+		ret.Exceptions = append(ret.Exceptions, pe)
+	}
+	return ret
+}
+
+func changeToProto(ch store.Change) *directorypb.ResourceChange {
+	ret := &directorypb.ResourceChange{
+		Seq:   ch.Seq,
+		Op:    directorypb.ResourceChange_OP_UPSERT,
+		Title: ch.Title,
+		Time:  timestamppb.New(ch.Time),
+	}
+	if ch.Op == store.ChangeDelete {
+		ret.Op = directorypb.ResourceChange_OP_DELETE
+	}
+	if ch.Resource != nil {
+		ret.Resource = toProto(*ch.Resource)
+	}
+	return ret
+}
@@ -0,0 +1,35 @@
+package search
+
+import (
+	"github.com/openwichita/infant-info/store"
+)
+
+// MatchCrisis
+// Returns the first crisis message with a term that is in 'text'. Every
+// word of a term has to be there, in any order and allowing for typos,
+// so "my baby is not breathing" matches "baby not breathing".
+func MatchCrisis(text string, crises []store.Crisis) (store.Crisis, bool) {
+	words := Words(text)
+	if len(words) == 0 {
+		return store.Crisis{}, false
+	}
+	for _, c := range crises {
+		for _, term := range c.Terms {
+			if termWords := Words(term); len(termWords) > 0 && hasAllWords(words, termWords) {
+				return c, true
+			}
+		}
+	}
+	return store.Crisis{}, false
+}
+
+// hasAllWords
+// Is every one of 'want' in 'words', or close enough to it
+func hasAllWords(words, want []string) bool {
+	for _, w := range want {
+		if !containsString(words, w) && !fuzzyContains(words, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+package search
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/store"
+)
+
+// Filter Parameters
+// The URL query parameters that hold a Filter, so that a filtered page can
+// be bookmarked or shared. Tags, languages, fees, costs and areas can repeat.
+const (
+	ParamQuery    = "q"
+	ParamOrg      = "org"
+	ParamTag      = "tag"
+	ParamLanguage = "lang"
+	ParamFee      = "fee"
+	ParamCost     = "cost" // See store.CostOptions for the labels
+	ParamArea     = "area"
+	ParamNear     = "near"   // A ZIP code or "lat,lng"
+	ParamWithin   = "within" // Miles from Near
+	ParamServes   = "serves" // "1" to only show resources that serve Near
+	ParamHours    = "hours"  // See HoursOpenNow and friends
+)
+
+// ParseFilter
+// Build a Filter from URL query values, the reverse of Filter.Values
+// Near is left for the caller to locate, see Filter.Origin.
+func ParseFilter(v url.Values) Filter {
+	f := Filter{
+		Query:     strings.TrimSpace(v.Get(ParamQuery)),
+		Org:       strings.TrimSpace(v.Get(ParamOrg)),
+		Tags:      store.CleanList(v[ParamTag]),
+		Languages: store.CleanList(v[ParamLanguage]),
+		Fees:      store.CleanList(v[ParamFee]),
+		Cost:      store.CleanList(v[ParamCost]),
+		Areas:     store.CleanList(v[ParamArea]),
+		Hours:     store.CleanList(v[ParamHours]),
+		Near:      strings.TrimSpace(v.Get(ParamNear)),
+		Serves:    v.Get(ParamServes) == "1",
+	}
+	if miles, err := strconv.ParseFloat(v.Get(ParamWithin), 64); err == nil && miles > 0 {
+		f.Within = miles
+	}
+	return f
+}
+
+// Values
+// Returns the filter as URL query values, leaving out anything blank
+func (f Filter) Values() url.Values {
+	v := make(url.Values)
+	if f.Query != "" {
+		v.Set(ParamQuery, f.Query)
+	}
+	if f.Org != "" {
+		v.Set(ParamOrg, f.Org)
+	}
+	for _, t := range f.Tags {
+		v.Add(ParamTag, t)
+	}
+	for _, l := range f.Languages {
+		v.Add(ParamLanguage, l)
+	}
+	for _, fee := range f.Fees {
+		v.Add(ParamFee, fee)
+	}
+	for _, c := range f.Cost {
+		v.Add(ParamCost, c)
+	}
+	for _, a := range f.Areas {
+		v.Add(ParamArea, a)
+	}
+	for _, h := range f.Hours {
+		v.Add(ParamHours, h)
+	}
+	if f.Near != "" {
+		v.Set(ParamNear, f.Near)
+	}
+	if f.Within > 0 {
+		v.Set(ParamWithin, strconv.FormatFloat(f.Within, 'f', -1, 64))
+	}
+	if f.Serves {
+		v.Set(ParamServes, "1")
+	}
+	return v
+}
+
+// IsEmpty
+// Does the filter let everything through
+func (f Filter) IsEmpty() bool {
+	return len(f.Values()) == 0
+}
+
+// Facet is one way of narrowing down results, like "Languages"
+type Facet struct {
+	Name   string
+	Param  string // The URL query parameter for its values
+	Values []FacetValue
+}
+
+// FacetValue is a value of a facet and how many results have it
+type FacetValue struct {
+	Value    string
+	Count    int
+	Selected bool // Whether the filter already uses this value
+}
+
+// Facets
+// Count the tags, languages, fees, costs, areas and hours of 'matches', which should be
+// the results of 'f'. Values the filter already uses are always included,
+// even when nothing has them.
+func (f Filter) Facets(matches []store.Resource) []Facet {
+	return []Facet{
+		buildFacet("Tags", ParamTag, f.Tags, matches, func(r store.Resource) []string { return r.Tags }),
+		buildFacet("Languages", ParamLanguage, f.Languages, matches, func(r store.Resource) []string { return r.Languages }),
+		buildFacet("Fees", ParamFee, f.Fees, matches, func(r store.Resource) []string { return r.Fees }),
+		buildFacet("Cost", ParamCost, f.Cost, matches, func(r store.Resource) []string { return r.Cost.Labels() }),
+		buildFacet("Area", ParamArea, f.Areas, matches, func(r store.Resource) []string {
+			if a := Area(r); a != "" {
+				return []string{a}
+			}
+			return nil
+		}),
+		buildFacet("Hours", ParamHours, f.Hours, matches, func(r store.Resource) []string {
+			return HoursValues(r, f.now())
+		}),
+	}
+}
+
+// buildFacet
+// Count the values that 'vals' returns for each resource, ignoring case
+func buildFacet(name, param string, selected []string, matches []store.Resource, vals func(store.Resource) []string) Facet {
+	ret := Facet{Name: name, Param: param}
+	idx := make(map[string]int)
+	add := func(v string) int {
+		k := strings.ToLower(v)
+		i, ok := idx[k]
+		if !ok {
+			i = len(ret.Values)
+			idx[k] = i
+			ret.Values = append(ret.Values, FacetValue{Value: v, Selected: ContainsFold(selected, v)})
+		}
+		return i
+	}
+	for i := range matches {
+		seen := make(map[int]bool)
+		for _, v := range vals(matches[i]) {
+			if j := add(v); !seen[j] {
+				seen[j] = true
+				ret.Values[j].Count++
+			}
+		}
+	}
+	// Added last so they're shown the way the resources spell them
+	for _, v := range selected {
+		add(v)
+	}
+	sort.Slice(ret.Values, func(i, j int) bool {
+		if ret.Values[i].Count != ret.Values[j].Count {
+			return ret.Values[i].Count > ret.Values[j].Count
+		}
+		return strings.ToLower(ret.Values[i].Value) < strings.ToLower(ret.Values[j].Value)
+	})
+	return ret
+}
+
+// Area
+// Returns the area a resource is in, which for now is the ZIP code at the
+// end of its address, or "" if it doesn't have one
+func Area(res store.Resource) string {
+	return geo.ZIP(res.Address)
+}
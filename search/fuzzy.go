@@ -0,0 +1,248 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// Distance
+// Returns the Levenshtein edit distance between 'a' and 'b', ignoring case
+func Distance(a, b string) int {
+	ra := []rune(strings.ToLower(a))
+	rb := []rune(strings.ToLower(b))
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// AllowedEdits
+// How many typos a word of this length may have and still match.
+// Short words have to be exact, or everything would match them.
+func AllowedEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// Words
+// Split 'text' into lower case words
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchText
+// The text of a resource that a query is matched against
+func searchText(res store.Resource) string {
+	return strings.ToLower(strings.Join([]string{
+		res.Title, res.Description, res.Org, strings.Join(res.Tags, " "),
+	}, " "))
+}
+
+// MatchesQuery
+// Does 'res' match the search 'query'. Either the whole query is in the
+// resource's text, or every word of it is, allowing for a few typos in each.
+// A query with no words in it, like "!!!", only matches if it's in the text.
+func MatchesQuery(res store.Resource, query string) bool {
+	text := searchText(res)
+	q := strings.ToLower(strings.TrimSpace(query))
+	if strings.Contains(text, q) {
+		return true
+	}
+	terms := Words(q)
+	if len(terms) == 0 {
+		return false
+	}
+	words := Words(text)
+	for _, w := range terms {
+		if !strings.Contains(text, w) && !fuzzyContains(words, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyContains
+// Is there a word in 'words' within the allowed edits of 'w'
+func fuzzyContains(words []string, w string) bool {
+	max := AllowedEdits(w)
+	if max == 0 {
+		return false
+	}
+	for _, t := range words {
+		if Distance(w, t) <= max {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest
+// Returns 'query' with each word that isn't in any resource replaced by the
+// closest word that is, for "Did you mean ...?". Returns "" if there is
+// nothing to correct. Suggestions allow one more typo than MatchesQuery does,
+// so they can find what the search itself missed. Words in 'extra', like the
+// ones from a Thesaurus, are known too.
+func Suggest(query string, resources []store.Resource, extra []string) string {
+	vocab := make(map[string]int)
+	for i := range resources {
+		for _, w := range Words(searchText(resources[i])) {
+			vocab[w]++
+		}
+	}
+	for _, w := range extra {
+		vocab[w]++
+	}
+	words := Words(query)
+	changed := false
+	for i, w := range words {
+		if vocab[w] > 0 {
+			continue
+		}
+		best, bestDist := "", AllowedEdits(w)+2
+		for v, n := range vocab {
+			d := Distance(w, v)
+			if d < bestDist || (d == bestDist && best != "" && (n > vocab[best] || (n == vocab[best] && v < best))) {
+				best, bestDist = v, d
+			}
+		}
+		if best != "" {
+			words[i] = best
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// Completion Kinds
+const (
+	CompleteTitle = "title"
+	CompleteOrg   = "org"
+	CompleteTag   = "tag"
+)
+
+// Completion is an autocomplete suggestion
+type Completion struct {
+	Text string `json:"text"`
+	Kind string `json:"kind"` // title, org or tag
+}
+
+// Complete
+// Returns up to 'limit' resource titles, organizations and tags for what has
+// been typed so far. Ones that start with 'prefix' come first, then ones with a
+// word that starts with it, then ones that contain it, then close misspellings.
+func Complete(prefix string, resources []store.Resource, limit int) []Completion {
+	ret := make([]Completion, 0, 0)
+	p := strings.ToLower(strings.TrimSpace(prefix))
+	if p == "" {
+		return ret
+	}
+	type scored struct {
+		Completion
+		score int
+	}
+	found := make([]scored, 0, 0)
+	seen := make(map[string]bool)
+	try := func(text, kind string) {
+		key := kind + ":" + strings.ToLower(text)
+		if text == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if sc := completionScore(p, text); sc >= 0 {
+			found = append(found, scored{Completion{Text: text, Kind: kind}, sc})
+		}
+	}
+	for i := range resources {
+		try(resources[i].Title, CompleteTitle)
+		try(resources[i].Org, CompleteOrg)
+		for _, t := range resources[i].Tags {
+			try(t, CompleteTag)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score < found[j].score
+		}
+		return strings.ToLower(found[i].Text) < strings.ToLower(found[j].Text)
+	})
+	for i := range found {
+		if len(ret) >= limit {
+			break
+		}
+		ret = append(ret, found[i].Completion)
+	}
+	return ret
+}
+
+// completionScore
+// How well 'text' completes the lower case 'prefix', lower is better and
+// -1 means not at all
+func completionScore(prefix, text string) int {
+	t := strings.ToLower(text)
+	if strings.HasPrefix(t, prefix) {
+		return 0
+	}
+	words := Words(t)
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			return 1
+		}
+	}
+	if strings.Contains(t, prefix) {
+		return 2
+	}
+	// Compare the typed word with the start of each word, for typos
+	// made before the word is finished
+	last := Words(prefix)
+	if len(last) == 0 {
+		return -1
+	}
+	typed := last[len(last)-1]
+	max := AllowedEdits(typed)
+	if max == 0 {
+		return -1
+	}
+	for _, w := range words {
+		rw := []rune(w)
+		if n := len([]rune(typed)); len(rw) > n {
+			rw = rw[:n]
+		}
+		if Distance(typed, string(rw)) <= max {
+			return 3
+		}
+	}
+	return -1
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+var fuzzyResources = []store.Resource{
+	{Title: "Diaper Bank", Description: "Free diapers for families", Org: "Catholic Charities", Tags: []string{"Diapers", "Baby Supplies"}},
+	{Title: "Safe Sleep", Description: "Cribs and classes", Org: "Health Department", Tags: []string{"Sleep", "Baby Supplies"}},
+	{Title: "Parents as Teachers", Description: "Home visits", Org: "Health Department"},
+	{Title: "Breastfeeding Support", Description: "Immunizations and lactation help", Org: "Health Department"},
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"WIC", "wic", 0},
+		{"diaper", "diapers", 1},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, wanted %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, wanted %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"diaper bank", true},
+		{"Free Diapers", true},
+		{"bank diaper", true},
+		{"diapars", true},
+		{"diapres", false},
+		{"dipaer bnak", false},
+		{"baby formula", false},
+		{"!!!", false},
+	}
+	for _, tt := range tests {
+		if got := MatchesQuery(fuzzyResources[0], tt.query); got != tt.want {
+			t.Errorf("MatchesQuery(%q) = %v, wanted %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"diaper bank", ""},
+		{"diapr bank", "diaper bank"},
+		{"breastfeding", "breastfeeding"},
+		{"imunizations", "immunizations"},
+		{"wik", "wic"},
+		{"xyzzy", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.query, fuzzyResources, []string{"wic"}); got != tt.want {
+			t.Errorf("Suggest(%q) = %q, wanted %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		prefix string
+		limit  int
+		want   string
+	}{
+		{"dia", 10, "title:Diaper Bank, tag:Diapers"},
+		{"sle", 10, "tag:Sleep, title:Safe Sleep"},
+		{"Health", 10, "org:Health Department"},
+		{"dep", 10, "org:Health Department"},
+		{"upp", 10, "tag:Baby Supplies, title:Breastfeeding Support"},
+		{"teachr", 10, "title:Parents as Teachers"},
+		{"ba", 1, "tag:Baby Supplies"},
+		{"  ", 10, ""},
+	}
+	for _, tt := range tests {
+		got := make([]string, 0, 0)
+		for _, c := range Complete(tt.prefix, fuzzyResources, tt.limit) {
+			got = append(got, c.Kind+":"+c.Text)
+		}
+		if strings.Join(got, ", ") != tt.want {
+			t.Errorf("Complete(%q) = %v, wanted %s", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// How much a search term matching each part of a resource adds to its relevance
+const (
+	scoreTitle       = 3
+	scoreTag         = 2
+	scoreOrg         = 1.5
+	scoreDescription = 1
+	scoreTypo        = 0.5 // Only matched allowing for typos
+	scoreWholeTitle  = 5   // The whole query is the title
+)
+
+// NormalizeQuery
+// Returns 'query' as lower case words, the way pins are matched
+func NormalizeQuery(query string) string {
+	return strings.Join(Words(query), " ")
+}
+
+// Relevance
+// How well 'res' matches the filter's query, higher is better
+func Relevance(res store.Resource, f Filter) float64 {
+	if f.Query == "" {
+		return 0
+	}
+	terms := f.Expansion
+	if len(terms) == 0 {
+		for _, w := range Words(f.Query) {
+			terms = append(terms, ExpandedTerm{Term: w})
+		}
+	}
+	score := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for _, p := range append([]string{term.Term}, term.Alternatives...) {
+			if s := phraseScore(res, p); s > best {
+				best = s
+			}
+		}
+		score += best
+	}
+	if NormalizeQuery(res.Title) == NormalizeQuery(f.Query) {
+		score += scoreWholeTitle
+	}
+	return score
+}
+
+// phraseScore
+// How well the lower case 'phrase' matches the best part of 'res'
+func phraseScore(res store.Resource, phrase string) float64 {
+	switch {
+	case strings.Contains(strings.ToLower(res.Title), phrase):
+		return scoreTitle
+	case ContainsFold(res.Tags, phrase):
+		return scoreTag
+	case strings.Contains(strings.ToLower(res.Org), phrase):
+		return scoreOrg
+	case strings.Contains(strings.ToLower(res.Description), phrase):
+		return scoreDescription
+	case MatchesQuery(res, phrase):
+		return scoreTypo
+	}
+	return 0
+}
+
+// Promotions are the pins and boosts that apply to a page of results
+type Promotions struct {
+	pinned map[string]int // Title -> order, in the order they were pinned
+	boosts map[string]float64
+}
+
+// NewPromotions
+// Pick the active pins that are for 'query' or any of 'tags'
+func NewPromotions(pins []store.Pin, query string, tags []string, now time.Time) Promotions {
+	p := Promotions{
+		pinned: make(map[string]int),
+		boosts: make(map[string]float64),
+	}
+	q := NormalizeQuery(query)
+	sort.SliceStable(pins, func(i, j int) bool { return pins[i].Created.Before(pins[j].Created) })
+	for _, pin := range pins {
+		if !pin.IsActive(now) {
+			continue
+		}
+		if pin.Query != "" && (q == "" || NormalizeQuery(pin.Query) != q) {
+			continue
+		}
+		if pin.Tag != "" && !ContainsFold(tags, pin.Tag) && !strings.EqualFold(pin.Tag, q) {
+			continue
+		}
+		if _, ok := p.pinned[pin.Resource]; pin.Pinned && !ok {
+			p.pinned[pin.Resource] = len(p.pinned)
+		}
+		p.boosts[pin.Resource] += pin.Boost
+	}
+	return p
+}
+
+// IsPinned
+// Is the resource pinned to the top
+func (p Promotions) IsPinned(title string) bool {
+	_, ok := p.pinned[title]
+	return ok
+}
+
+// Sort
+// Order 'resources' with the pinned ones first, then by distance when the
+// filter has an Origin (unknown distances last), then by relevance to the
+// filter's query plus any boost, then by title
+func (p Promotions) Sort(resources []store.Resource, f Filter) {
+	scores := make(map[string]float64, len(resources))
+	miles := make(map[string]float64, len(resources))
+	for i := range resources {
+		scores[resources[i].Title] = Relevance(resources[i], f) + p.boosts[resources[i].Title]
+		if d, ok := f.Distance(resources[i]); ok {
+			miles[resources[i].Title] = d
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		ti, tj := resources[i].Title, resources[j].Title
+		pi, iPinned := p.pinned[ti]
+		pj, jPinned := p.pinned[tj]
+		if iPinned != jPinned {
+			return iPinned
+		}
+		if iPinned {
+			return pi < pj
+		}
+		if f.Origin != nil {
+			di, iKnown := miles[ti]
+			dj, jKnown := miles[tj]
+			if iKnown != jKnown {
+				return iKnown
+			}
+			if di != dj {
+				return di < dj
+			}
+		}
+		if scores[ti] != scores[tj] {
+			return scores[ti] > scores[tj]
+		}
+		return strings.ToLower(ti) < strings.ToLower(tj)
+	})
+}
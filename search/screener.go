@@ -0,0 +1,253 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/store"
+)
+
+// PovertyGuideline is the federal poverty guideline for the 48 contiguous
+// states, which Kansas uses, for working out the percent of the poverty
+// level a household's income is. Update it when HHS publishes a new one.
+var PovertyGuideline = struct {
+	Year      int
+	Base      float64 // For one person
+	PerPerson float64 // For each person after the first
+}{2025, 15650, 5500}
+
+// Answers are what a family has said about themselves in the screener
+// Anything not answered is left blank, and can't rule a resource out.
+type Answers struct {
+	DueDate   time.Time  // Blank when not expecting
+	BirthDate time.Time  // The youngest child's, blank for none
+	ParentAge int        // Years, 0 for not given
+	Place     *geo.Place // Where they live
+	Insurance string     // An Insurance Status, blank for not sure
+	Household int        // People in the household, 0 for not given
+	Income    float64    // Yearly household income in dollars, when HasIncome
+	HasIncome bool
+	Language  string
+
+	// Now is the day the answers are for, time.Now() when it isn't set
+	Now time.Time
+}
+
+// Screening is how well a resource fits a family's Answers
+type Screening struct {
+	Resource store.Resource
+	Matches  []string // What fits, like "pregnant" or "Sedgwick County"
+	Unknown  []string // What couldn't be checked with the answers given
+	Misses   []string // What rules the resource out
+	Cautions []string // What doesn't fit but doesn't rule it out, like language
+	Miles    float64  // From Answers.Place, when HasMiles
+	HasMiles bool
+}
+
+func (a Answers) now() time.Time {
+	if a.Now.IsZero() {
+		return time.Now()
+	}
+	return a.Now
+}
+
+// PregnancyStage
+// Returns the Pregnancy Stage the family is in, using the due date for the
+// trimester and the baby's age for postpartum (under a year), or "" for none
+func (a Answers) PregnancyStage() string {
+	now := a.now()
+	if !a.DueDate.IsZero() && a.DueDate.After(now.AddDate(0, 0, -14)) {
+		weeks := 40 - int(a.DueDate.Sub(now).Hours()/24/7)
+		switch {
+		case weeks < 14:
+			return store.PregnancyFirst
+		case weeks < 28:
+			return store.PregnancySecond
+		}
+		return store.PregnancyThird
+	}
+	if months, ok := a.ChildMonths(); ok && months < 12 {
+		return store.PregnancyPostpartum
+	}
+	return ""
+}
+
+// ChildMonths
+// Returns how many whole months old the youngest child is, if known
+func (a Answers) ChildMonths() (int, bool) {
+	if a.BirthDate.IsZero() {
+		return 0, false
+	}
+	now := a.now()
+	by, bm, bd := a.BirthDate.Date()
+	ny, nm, nd := now.Date()
+	months := (ny-by)*12 + int(nm-bm)
+	if nd < bd {
+		months--
+	}
+	if months < 0 {
+		return 0, false
+	}
+	return months, true
+}
+
+// PercentFPL
+// Returns the household's income as a percent of the poverty guideline, if
+// both the household size and income were given
+func (a Answers) PercentFPL() (int, bool) {
+	if a.Household < 1 || !a.HasIncome {
+		return 0, false
+	}
+	line := PovertyGuideline.Base + PovertyGuideline.PerPerson*float64(a.Household-1)
+	return int(a.Income / line * 100), true
+}
+
+// Screen
+// Check 'res' against the answers. A resource with any Misses isn't for
+// the family.
+func (a Answers) Screen(res store.Resource) Screening {
+	sc := Screening{Resource: res}
+	e := res.Eligibility
+
+	// Pregnant in one of the stages or a child in the age range
+	if len(e.Pregnancy) > 0 || !e.ChildAge.IsEmpty() {
+		stage := a.PregnancyStage()
+		months, hasChild := a.ChildMonths()
+		switch {
+		case stage != "" && e.HasPregnancy(stage) && stage == store.PregnancyPostpartum:
+			sc.Matches = append(sc.Matches, "postpartum")
+		case stage != "" && e.HasPregnancy(stage):
+			sc.Matches = append(sc.Matches, "pregnant")
+		case hasChild && !e.ChildAge.IsEmpty() && e.ChildAge.Includes(months):
+			sc.Matches = append(sc.Matches, "child's age")
+		case a.DueDate.IsZero() && !hasChild:
+			sc.Unknown = append(sc.Unknown, "pregnancy or child's age")
+		default:
+			sc.Misses = append(sc.Misses, "pregnancy or child's age")
+		}
+	}
+	if !e.ParentAge.IsEmpty() {
+		sc.check(a.ParentAge > 0, e.ParentAge.Includes(a.ParentAge), "parent's age")
+	}
+	if e.IncomeFPL > 0 {
+		pct, ok := a.PercentFPL()
+		sc.check(ok, pct <= e.IncomeFPL, "income")
+	}
+	if len(e.Residency) > 0 {
+		sc.check(a.Place != nil, a.Place != nil && geo.Serves(e.Residency, *a.Place), a.placeLabel())
+	}
+	if len(e.Insurance) > 0 {
+		sc.check(a.Insurance != "", e.HasInsurance(a.Insurance), insuranceLabel(a.Insurance))
+	}
+	if a.Place != nil && len(res.ServiceAreas) > 0 {
+		if geo.Serves(res.ServiceAreas, *a.Place) {
+			sc.Matches = append(sc.Matches, "serves your area")
+		} else {
+			sc.Misses = append(sc.Misses, "doesn't serve your area")
+		}
+	}
+	if a.Language != "" && len(res.Languages) > 0 {
+		if ContainsFold(res.Languages, a.Language) {
+			sc.Matches = append(sc.Matches, a.Language)
+		} else {
+			sc.Cautions = append(sc.Cautions, "doesn't list "+a.Language)
+		}
+	}
+	if a.Place != nil && (res.Latitude != 0 || res.Longitude != 0) {
+		sc.Miles, sc.HasMiles = geo.Distance(a.Place.Point, geo.Point{Lat: res.Latitude, Lng: res.Longitude}), true
+	}
+	return sc
+}
+
+// check
+// Record one requirement, as unknown when it wasn't 'answered'
+func (sc *Screening) check(answered, fits bool, what string) {
+	switch {
+	case !answered:
+		sc.Unknown = append(sc.Unknown, what)
+	case fits:
+		sc.Matches = append(sc.Matches, what)
+	default:
+		sc.Misses = append(sc.Misses, what)
+	}
+}
+
+func (a Answers) placeLabel() string {
+	if a.Place == nil {
+		return "where you live"
+	}
+	if a.Place.County != "" {
+		return a.Place.County + " County"
+	}
+	return a.Place.City
+}
+
+func insuranceLabel(key string) string {
+	for _, o := range store.InsuranceStatuses {
+		if o.Key == key {
+			return o.Label
+		}
+	}
+	return "insurance"
+}
+
+// ScreenAll
+// Returns the resources that the family may be able to use, best first:
+// the most matches, then the fewest cautions and unknowns, then the closest
+func (a Answers) ScreenAll(resources []store.Resource) []Screening {
+	ret := make([]Screening, 0, len(resources))
+	for _, res := range resources {
+		if sc := a.Screen(res); len(sc.Misses) == 0 {
+			ret = append(ret, sc)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		si := len(ret[i].Matches) - len(ret[i].Cautions)
+		sj := len(ret[j].Matches) - len(ret[j].Cautions)
+		if si != sj {
+			return si > sj
+		}
+		if len(ret[i].Unknown) != len(ret[j].Unknown) {
+			return len(ret[i].Unknown) < len(ret[j].Unknown)
+		}
+		if ret[i].HasMiles != ret[j].HasMiles {
+			return ret[i].HasMiles
+		}
+		if ret[i].HasMiles && ret[i].Miles != ret[j].Miles {
+			return ret[i].Miles < ret[j].Miles
+		}
+		return strings.ToLower(ret[i].Resource.Title) < strings.ToLower(ret[j].Resource.Title)
+	})
+	return ret
+}
+
+// Summary
+// Describes the answers for people to read, so a family can check them
+func (a Answers) Summary() []string {
+	ret := make([]string, 0, 6)
+	if stage := a.PregnancyStage(); stage != "" && stage != store.PregnancyPostpartum {
+		ret = append(ret, fmt.Sprintf("Due %s (%s)", a.DueDate.Format("Jan 2, 2006"), strings.Replace(stage, "_", " ", -1)))
+	}
+	if months, ok := a.ChildMonths(); ok {
+		ret = append(ret, fmt.Sprintf("Youngest child is %d months old", months))
+	}
+	if a.ParentAge > 0 {
+		ret = append(ret, fmt.Sprintf("Parent is %d", a.ParentAge))
+	}
+	if a.Place != nil {
+		ret = append(ret, "Lives in "+a.placeLabel())
+	}
+	if a.Insurance != "" {
+		ret = append(ret, "Has "+insuranceLabel(a.Insurance))
+	}
+	if pct, ok := a.PercentFPL(); ok {
+		ret = append(ret, fmt.Sprintf("Income is about %d%% of the poverty level for %d people", pct, a.Household))
+	}
+	if a.Language != "" {
+		ret = append(ret, "Speaks "+a.Language)
+	}
+	return ret
+}
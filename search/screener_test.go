@@ -0,0 +1,161 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/store"
+)
+
+var (
+	screenNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	wichita   = &geo.Place{ZIP: "67214", City: "Wichita", County: "Sedgwick", State: "KS", Point: geo.Point{Lat: 37.6948, Lng: -97.3129}}
+	elDorado  = &geo.Place{ZIP: "67042", City: "El Dorado", County: "Butler", State: "KS", Point: geo.Point{Lat: 37.8172, Lng: -96.8623}}
+)
+
+// daysFromNow is a date relative to screenNow
+func daysFromNow(days int) time.Time {
+	return screenNow.AddDate(0, 0, days)
+}
+
+func TestPregnancyStage(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer Answers
+		want   string
+	}{
+		{"due in 30 weeks", Answers{DueDate: daysFromNow(30 * 7)}, store.PregnancyFirst},
+		{"due in 20 weeks", Answers{DueDate: daysFromNow(20 * 7)}, store.PregnancySecond},
+		{"due in 4 weeks", Answers{DueDate: daysFromNow(4 * 7)}, store.PregnancyThird},
+		{"overdue", Answers{DueDate: daysFromNow(-10)}, store.PregnancyThird},
+		{"born a month ago", Answers{DueDate: daysFromNow(-30), BirthDate: daysFromNow(-30)}, store.PregnancyPostpartum},
+		{"a toddler", Answers{BirthDate: daysFromNow(-2 * 365)}, ""},
+		{"nothing", Answers{}, ""},
+	}
+	for _, tt := range tests {
+		tt.answer.Now = screenNow
+		if got := tt.answer.PregnancyStage(); got != tt.want {
+			t.Errorf("%s: got %q, wanted %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChildMonths(t *testing.T) {
+	tests := []struct {
+		birth time.Time
+		want  int
+		ok    bool
+	}{
+		{time.Date(2026, 9, 19, 0, 0, 0, 0, time.UTC), 1, true},
+		{time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC), 0, true},
+		{time.Date(2025, 10, 19, 0, 0, 0, 0, time.UTC), 12, true},
+		{time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), 0, false},
+		{time.Time{}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Answers{BirthDate: tt.birth, Now: screenNow}.ChildMonths()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ChildMonths(%s) = %d %v, wanted %d %v", tt.birth.Format("2006-01-02"), got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPercentFPL(t *testing.T) {
+	tests := []struct {
+		household int
+		income    float64
+		hasIncome bool
+		want      int
+		ok        bool
+	}{
+		{1, PovertyGuideline.Base, true, 100, true},
+		{2, (PovertyGuideline.Base + PovertyGuideline.PerPerson) / 2, true, 50, true},
+		{4, 2 * (PovertyGuideline.Base + 3*PovertyGuideline.PerPerson), true, 200, true},
+		{3, 0, true, 0, true},
+		{0, 20000, true, 0, false},
+		{3, 0, false, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Answers{Household: tt.household, Income: tt.income, HasIncome: tt.hasIncome}.PercentFPL()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("PercentFPL(%d, %v) = %d %v, wanted %d %v", tt.household, tt.income, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScreen(t *testing.T) {
+	teen := store.Resource{Eligibility: store.Eligibility{ParentAge: store.AgeRange{Max: 19}}}
+	infants := store.Resource{Eligibility: store.Eligibility{
+		Pregnancy: []string{store.PregnancyFirst, store.PregnancySecond, store.PregnancyThird},
+		ChildAge:  store.AgeRange{Max: 11},
+	}}
+	postpartum := store.Resource{Eligibility: store.Eligibility{Pregnancy: []string{store.PregnancyPostpartum}}}
+	income := store.Resource{Eligibility: store.Eligibility{IncomeFPL: 185}}
+	county := store.Resource{Eligibility: store.Eligibility{Residency: []string{"county:Sedgwick"}}}
+	medicaid := store.Resource{Eligibility: store.Eligibility{Insurance: []string{store.InsuranceMedicaid}}}
+	city := store.Resource{ServiceAreas: []string{"city:Wichita"}}
+	spanish := store.Resource{Languages: []string{"Spanish"}}
+
+	tests := []struct {
+		name   string
+		res    store.Resource
+		answer Answers
+		want   string // Matches|Unknown|Misses|Cautions
+	}{
+		{"teen parent", teen, Answers{ParentAge: 17}, "parent's age|||"},
+		{"adult parent", teen, Answers{ParentAge: 25}, "||parent's age|"},
+		{"parent's age not given", teen, Answers{}, "|parent's age||"},
+		{"pregnant", infants, Answers{DueDate: daysFromNow(20 * 7)}, "pregnant|||"},
+		{"infant", infants, Answers{BirthDate: daysFromNow(-180)}, "child's age|||"},
+		{"toddler", infants, Answers{BirthDate: daysFromNow(-2 * 365)}, "||pregnancy or child's age|"},
+		{"no pregnancy or child", infants, Answers{}, "|pregnancy or child's age||"},
+		{"postpartum", postpartum, Answers{BirthDate: daysFromNow(-60)}, "postpartum|||"},
+		{"pregnant, not postpartum", postpartum, Answers{DueDate: daysFromNow(20 * 7)}, "||pregnancy or child's age|"},
+		{"low income", income, Answers{Household: 3, Income: 40000, HasIncome: true}, "income|||"},
+		{"high income", income, Answers{Household: 3, Income: 60000, HasIncome: true}, "||income|"},
+		{"income not given", income, Answers{Household: 3}, "|income||"},
+		{"lives in the county", county, Answers{Place: wichita}, "Sedgwick County|||"},
+		{"lives elsewhere", county, Answers{Place: elDorado}, "||Butler County|"},
+		{"home not given", county, Answers{}, "|where you live||"},
+		{"has Medicaid", medicaid, Answers{Insurance: store.InsuranceMedicaid}, "KanCare/Medicaid|||"},
+		{"has private insurance", medicaid, Answers{Insurance: store.InsurancePrivate}, "||Private insurance|"},
+		{"insurance not given", medicaid, Answers{}, "|insurance||"},
+		{"in the service area", city, Answers{Place: wichita}, "serves your area|||"},
+		{"outside the service area", city, Answers{Place: elDorado}, "||doesn't serve your area|"},
+		{"speaks the language", spanish, Answers{Language: "spanish"}, "spanish|||"},
+		{"doesn't speak the language", spanish, Answers{Language: "English"}, "|||doesn't list English"},
+	}
+	for _, tt := range tests {
+		tt.answer.Now = screenNow
+		sc := tt.answer.Screen(tt.res)
+		got := strings.Join([]string{
+			strings.Join(sc.Matches, ", "), strings.Join(sc.Unknown, ", "),
+			strings.Join(sc.Misses, ", "), strings.Join(sc.Cautions, ", "),
+		}, "|")
+		if got != tt.want {
+			t.Errorf("%s: got %q, wanted %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScreenAll(t *testing.T) {
+	resources := []store.Resource{
+		{Title: "Far", Latitude: elDorado.Lat, Longitude: elDorado.Lng, Eligibility: store.Eligibility{ParentAge: store.AgeRange{Max: 19}}},
+		{Title: "Near", Latitude: wichita.Lat, Longitude: wichita.Lng, Eligibility: store.Eligibility{ParentAge: store.AgeRange{Max: 19}}},
+		{Title: "Anyone"},
+		{Title: "Adults", Eligibility: store.Eligibility{ParentAge: store.AgeRange{Min: 20}}},
+		{Title: "Spanish", Languages: []string{"Spanish"}},
+		{Title: "Insured", Eligibility: store.Eligibility{Insurance: []string{store.InsurancePrivate}}},
+	}
+	a := Answers{ParentAge: 17, Place: wichita, Language: "English", Now: screenNow}
+	got := make([]string, 0, 0)
+	for _, sc := range a.ScreenAll(resources) {
+		got = append(got, sc.Resource.Title)
+	}
+	// Matches first, nearest first, then unknowns, then cautions
+	if want := "Near, Far, Anyone, Insured, Spanish"; strings.Join(got, ", ") != want {
+		t.Errorf("Got %v, wanted %s", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+// Package search narrows down the resources in the directory.
+package search
+
+import (
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/store"
+)
+
+// Filter is the set of arguments used to narrow down resources
+// Blank fields don't filter anything.
+type Filter struct {
+	Query     string   // Allows for typos, see MatchesQuery
+	Tags      []string // Resources must have all of these
+	Org       string
+	Languages []string // Resources must offer one of these
+	Fees      []string // Resources must have one of these
+	Cost      []string // Resources must have one of these, see store.Cost.Labels
+	Areas     []string // Resources must be in one of these, see Area
+	Hours     []string // Resources must have one of these, see HoursValues
+	Near      string   // Where the family is, a ZIP code or "lat,lng"
+	Within    float64  // Resources must be this many miles from Origin
+	Serves    bool     // Resources must serve Origin, see geo.Serves
+
+	// Now is the time for the Hours, time.Now() when it isn't set
+	Now time.Time
+
+	// Origin is where Near is, found by the caller with geo.Gazetteer.Locate
+	// Results are sorted by their distance from it.
+	Origin *geo.Place
+
+	// Expansion is Query with synonyms, see Thesaurus.Expand
+	// When it is set it is matched instead of Query.
+	Expansion Expansion
+}
+
+// Matches
+// Does 'res' pass every part of the filter
+func (f Filter) Matches(res store.Resource) bool {
+	if f.Org != "" && !strings.EqualFold(f.Org, res.Org) {
+		return false
+	}
+	for _, t := range f.Tags {
+		if !ContainsFold(res.Tags, t) {
+			return false
+		}
+	}
+	if len(f.Languages) > 0 && !ContainsAnyFold(res.Languages, f.Languages) {
+		return false
+	}
+	if len(f.Fees) > 0 && !ContainsAnyFold(res.Fees, f.Fees) {
+		return false
+	}
+	if len(f.Cost) > 0 && !ContainsAnyFold(res.Cost.Labels(), f.Cost) {
+		return false
+	}
+	if len(f.Areas) > 0 && !ContainsFold(f.Areas, Area(res)) {
+		return false
+	}
+	if f.Origin != nil && f.Within > 0 {
+		if miles, ok := f.Distance(res); !ok || miles > f.Within {
+			return false
+		}
+	}
+	if f.Serves && f.OutOfArea(res) {
+		return false
+	}
+	if len(f.Hours) > 0 && !ContainsAnyFold(HoursValues(res, f.now()), f.Hours) {
+		return false
+	}
+	if len(f.Expansion) > 0 {
+		if !f.Expansion.Matches(res) {
+			return false
+		}
+	} else if f.Query != "" && !MatchesQuery(res, f.Query) {
+		return false
+	}
+	return true
+}
+
+// Distance
+// Returns how many miles 'res' is from the filter's Origin, if both are known
+func (f Filter) Distance(res store.Resource) (float64, bool) {
+	if f.Origin == nil || (res.Latitude == 0 && res.Longitude == 0) {
+		return 0, false
+	}
+	return geo.Distance(f.Origin.Point, geo.Point{Lat: res.Latitude, Lng: res.Longitude}), true
+}
+
+// OutOfArea
+// Does 'res' say where it serves, and not include the filter's Origin.
+// Resources that don't say are given the benefit of the doubt.
+func (f Filter) OutOfArea(res store.Resource) bool {
+	if f.Origin == nil || f.Origin.ZIP == "" || len(res.ServiceAreas) == 0 {
+		return false
+	}
+	return !geo.Serves(res.ServiceAreas, *f.Origin)
+}
+
+func (f Filter) now() time.Time {
+	if f.Now.IsZero() {
+		return time.Now()
+	}
+	return f.Now
+}
+
+// Hours facet values
+const (
+	HoursOpenNow       = "Open now"
+	HoursAlways        = "Open 24/7"
+	HoursByAppointment = "By appointment"
+)
+
+// HoursValues
+// Returns the Hours facet values that 'res' has at 'now'
+func HoursValues(res store.Resource, now time.Time) []string {
+	ret := make([]string, 0, 0)
+	if res.Schedule.Status(now).Open {
+		ret = append(ret, HoursOpenNow)
+	}
+	if res.Schedule.Always {
+		ret = append(ret, HoursAlways)
+	}
+	if res.Schedule.ByAppointment {
+		ret = append(ret, HoursByAppointment)
+	}
+	return ret
+}
+
+// Apply
+// Returns the resources that match the filter, in the same order
+func (f Filter) Apply(resources []store.Resource) []store.Resource {
+	ret := make([]store.Resource, 0, 0)
+	for i := range resources {
+		if f.Matches(resources[i]) {
+			ret = append(ret, resources[i])
+		}
+	}
+	return ret
+}
+
+// ContainsFold
+// Is 'val' in 'list', ignoring case
+func ContainsFold(list []string, val string) bool {
+	for i := range list {
+		if strings.EqualFold(list[i], val) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsAnyFold
+// Are any of 'vals' in 'list', ignoring case
+func ContainsAnyFold(list, vals []string) bool {
+	for i := range vals {
+		if ContainsFold(list, vals[i]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedQuery is recorded in place of queries that look like they hold
+// someone's personal details
+const RedactedQuery = "(redacted)"
+
+// The longest query, in letters, kept in the search statistics
+const maxStatsQuery = 100
+
+var (
+	emailPattern  = regexp.MustCompile(`\S+@\S+\.\S+`)
+	digitsPattern = regexp.MustCompile(`(?:\d[\s().-]?){7,}`)
+)
+
+// StatsQuery
+// Returns 'query' as it is kept in the search statistics: lower case words
+// only, and RedactedQuery if it looks like an email address, phone number or
+// other long number
+func StatsQuery(query string) string {
+	if emailPattern.MatchString(query) || digitsPattern.MatchString(query) {
+		return RedactedQuery
+	}
+	q := NormalizeQuery(query)
+	if r := []rune(q); len(r) > maxStatsQuery {
+		q = strings.TrimSpace(string(r[:maxStatsQuery]))
+	}
+	return q
+}
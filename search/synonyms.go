@@ -0,0 +1,156 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// The longest phrase, in words, that a Thesaurus looks for in a query
+const maxPhraseWords = 6
+
+// Thesaurus expands search queries with the synonyms and acronyms kept by
+// the admins, so "food stamps" also finds SNAP and "WIC" also finds
+// "Women Infants and Children".
+type Thesaurus struct {
+	related  map[string][]string // Phrase -> the phrases that mean the same
+	acronyms map[string]bool     // Acronyms only match whole words
+}
+
+// NewThesaurus
+// Build a Thesaurus from the stored synonyms
+func NewThesaurus(syns []store.Synonym) *Thesaurus {
+	t := &Thesaurus{
+		related:  make(map[string][]string),
+		acronyms: make(map[string]bool),
+	}
+	for _, syn := range syns {
+		group := make([]string, 0, len(syn.Terms)+1)
+		if a := phraseKey(syn.Acronym); a != "" {
+			t.acronyms[a] = true
+			group = append(group, a)
+		}
+		for _, term := range syn.Terms {
+			if p := phraseKey(term); p != "" {
+				group = append(group, p)
+			}
+		}
+		for _, p := range group {
+			for _, q := range group {
+				if p != q && !containsString(t.related[p], q) {
+					t.related[p] = append(t.related[p], q)
+				}
+			}
+		}
+	}
+	return t
+}
+
+// phraseKey
+// Lower case 'phrase' with its punctuation dropped, as it's looked up
+func phraseKey(phrase string) string {
+	return strings.Join(Words(phrase), " ")
+}
+
+func containsString(list []string, val string) bool {
+	for i := range list {
+		if list[i] == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Words
+// Returns every word used in the Thesaurus
+func (t *Thesaurus) Words() []string {
+	ret := make([]string, 0, 0)
+	seen := make(map[string]bool)
+	for p := range t.related {
+		for _, w := range strings.Fields(p) {
+			if !seen[w] {
+				seen[w] = true
+				ret = append(ret, w)
+			}
+		}
+	}
+	return ret
+}
+
+// ExpandedTerm is a part of a query and the phrases that can stand in for it
+type ExpandedTerm struct {
+	Term         string
+	Alternatives []string
+	acronyms     map[string]bool
+}
+
+// Expansion is a query broken into terms, a resource has to match every term
+type Expansion []ExpandedTerm
+
+// Expand
+// Break 'query' into terms, looking for the longest known phrase at each
+// word. Words that aren't in the Thesaurus are terms of their own.
+func (t *Thesaurus) Expand(query string) Expansion {
+	words := Words(query)
+	ret := make(Expansion, 0, len(words))
+	for i := 0; i < len(words); {
+		n := maxPhraseWords
+		if i+n > len(words) {
+			n = len(words) - i
+		}
+		for ; n > 1; n-- {
+			if _, ok := t.related[strings.Join(words[i:i+n], " ")]; ok {
+				break
+			}
+		}
+		term := strings.Join(words[i:i+n], " ")
+		ret = append(ret, ExpandedTerm{
+			Term:         term,
+			Alternatives: t.related[term],
+			acronyms:     t.acronyms,
+		})
+		i += n
+	}
+	return ret
+}
+
+// Matches
+// Does 'res' match every term, or one of its alternatives
+func (e Expansion) Matches(res store.Resource) bool {
+	for _, term := range e {
+		if !term.Matches(res) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches
+// Does 'res' match the term or one of its alternatives
+func (et ExpandedTerm) Matches(res store.Resource) bool {
+	for _, p := range append([]string{et.Term}, et.Alternatives...) {
+		if et.acronyms[p] {
+			// "wic" shouldn't find "Wichita"
+			if containsString(Words(searchText(res)), p) {
+				return true
+			}
+		} else if MatchesQuery(res, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// String
+// Describes the expansion, like: (wic OR women infants and children) AND formula
+func (e Expansion) String() string {
+	parts := make([]string, 0, len(e))
+	for _, term := range e {
+		if len(term.Alternatives) == 0 {
+			parts = append(parts, term.Term)
+			continue
+		}
+		parts = append(parts, "("+strings.Join(append([]string{term.Term}, term.Alternatives...), " OR ")+")")
+	}
+	return strings.Join(parts, " AND ")
+}
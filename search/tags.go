@@ -0,0 +1,135 @@
+package search
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// TagQuery is a set of tag groups, as used in /browse/ paths.
+// A resource matches when it has at least one tag from every group, so
+// "food,housing" is food AND housing and "food+housing" is food OR housing.
+// They can be mixed: "food+meals,housing" is (food OR meals) AND housing.
+type TagQuery [][]string
+
+// ParseTagQuery
+// Parse a tag path like "food+meals,housing" into a TagQuery.
+// Blank and repeated tags are dropped.
+func ParseTagQuery(path string) TagQuery {
+	ret := make(TagQuery, 0, 0)
+	for _, grp := range strings.Split(path, ",") {
+		tags := make([]string, 0, 0)
+		for _, t := range strings.Split(grp, "+") {
+			if t = strings.TrimSpace(t); t != "" && !ContainsFold(tags, t) && !ret.Has(t) {
+				tags = append(tags, t)
+			}
+		}
+		if len(tags) > 0 {
+			ret = append(ret, tags)
+		}
+	}
+	return ret
+}
+
+// String
+// Returns the query as a tag path, the reverse of ParseTagQuery
+func (q TagQuery) String() string {
+	grps := make([]string, 0, len(q))
+	for _, grp := range q {
+		tags := make([]string, 0, len(grp))
+		for _, t := range grp {
+			tags = append(tags, url.PathEscape(t))
+		}
+		grps = append(grps, strings.Join(tags, "+"))
+	}
+	return strings.Join(grps, ",")
+}
+
+// Matches
+// Does 'res' have a tag from every group
+func (q TagQuery) Matches(res store.Resource) bool {
+	for _, grp := range q {
+		if !ContainsAnyFold(res.Tags, grp) {
+			return false
+		}
+	}
+	return true
+}
+
+// Has
+// Is 'tag' anywhere in the query
+func (q TagQuery) Has(tag string) bool {
+	for _, grp := range q {
+		if ContainsFold(grp, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags
+// Returns every tag in the query
+func (q TagQuery) Tags() []string {
+	ret := make([]string, 0, 0)
+	for _, grp := range q {
+		ret = append(ret, grp...)
+	}
+	return ret
+}
+
+// With
+// Returns a copy of the query that also requires 'tag'
+func (q TagQuery) With(tag string) TagQuery {
+	if q.Has(tag) {
+		return q
+	}
+	ret := make(TagQuery, 0, len(q)+1)
+	ret = append(ret, q...)
+	return append(ret, []string{tag})
+}
+
+// Without
+// Returns a copy of the query with 'tag' taken out, dropping its group if
+// that leaves it empty
+func (q TagQuery) Without(tag string) TagQuery {
+	ret := make(TagQuery, 0, len(q))
+	for _, grp := range q {
+		tags := make([]string, 0, len(grp))
+		for _, t := range grp {
+			if !strings.EqualFold(t, tag) {
+				tags = append(tags, t)
+			}
+		}
+		if len(tags) > 0 {
+			ret = append(ret, tags)
+		}
+	}
+	return ret
+}
+
+// TagCount is a tag and how many resources have it
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// CountTags
+// Returns every tag used by 'resources' with its resource count, sorted by name
+func CountTags(resources []store.Resource) []TagCount {
+	counts := make(map[string]int)
+	for i := range resources {
+		for _, t := range resources[i].Tags {
+			counts[t]++
+		}
+	}
+	ret := make([]TagCount, 0, len(counts))
+	for k, v := range counts {
+		ret = append(ret, TagCount{Name: k, Count: v})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.ToLower(ret[i].Name) < strings.ToLower(ret[j].Name)
+	})
+	return ret
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/br0xen/bolt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminStore is the admin database
+type AdminStore struct {
+	path string
+
+	mu sync.Mutex // Held while the db is open
+	db *bolt.DB
+}
+
+// OpenAdmin
+// Returns an AdminStore for the database file at 'path', creating it if needed
+func OpenAdmin(path string) (*AdminStore, error) {
+	s := &AdminStore{path: path}
+	if err := s.loadAdminDatabase(); err != nil {
+		return nil, err
+	}
+	s.closeAdminDatabase()
+	return s, nil
+}
+
+// Admin Model Functions
+// All admin accounts are stored in the admin boltdb like so
+// users		(bucket)
+// |- <email address 1> (bucket)
+// | \-password		(pair)
+// |
+// |- <email address 2> (bucket)
+// | \-password		(pair)
+//
+// API tokens, API keys, webhooks, search synonyms, search statistics, pins,
+// crisis messages, site settings and timeline milestones live alongside them,
+// see tokens.go, apikeys.go, webhooks.go, synonyms.go, searchstats.go,
+// pins.go, crisis.go and milestones.go
+func (s *AdminStore) loadAdminDatabase() error {
+	s.mu.Lock()
+	var err error
+	s.db, err = bolt.Open(s.path, 0600, nil)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	// Make sure that all of the top level buckets exist
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		for _, bkt := range []string{"users", "tokens", "apikeys", "webhooks", "deliveries", "synonyms", "searchstats", "pins", "crisis", "settings", "milestones"} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bkt)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		s.closeAdminDatabase()
+		return err
+	}
+	return nil
+}
+
+func (s *AdminStore) closeAdminDatabase() error {
+	defer s.mu.Unlock()
+	return s.db.Close()
+}
+
+// Users
+// Returns a slice of all of the admin email addresses
+func (s *AdminStore) Users() ([]string, error) {
+	u := make([]string, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return u, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		err := b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				u = append(u, string(k))
+			}
+			return nil
+		})
+		return err
+	})
+	s.closeAdminDatabase()
+	return u, err
+}
+
+// IsUser
+// Returns an error if 'email' isn't an admin account
+func (s *AdminStore) IsUser(email string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		if userBucket := b.Bucket([]byte(email)); userBucket != nil {
+			return nil
+		}
+		return fmt.Errorf("Invalid User")
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// CheckCredentials
+// Returns an error unless 'password' is correct for the account 'email'
+func (s *AdminStore) CheckCredentials(email, password string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		if userBucket := b.Bucket([]byte(email)); userBucket != nil {
+			if pw := userBucket.Get([]byte("password")); pw != nil {
+				return bcrypt.CompareHashAndPassword(pw, []byte(password))
+			}
+		}
+		return fmt.Errorf("Invalid User")
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// SaveUser
+// Create an admin account, or change the password of an existing one
+func (s *AdminStore) SaveUser(email, password string) error {
+	cryptPW, cryptError := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if cryptError != nil {
+		return cryptError
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		var newB *bolt.Bucket
+		var err error
+		if newB, err = b.CreateBucketIfNotExists([]byte(email)); err != nil {
+			return err
+		}
+		if err := newB.Put([]byte("password"), cryptPW); err != nil {
+			return err
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// DeleteUser
+// Remove an admin account
+func (s *AdminStore) DeleteUser(email string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		return b.DeleteBucket([]byte(email))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// CheckFirstRun
+// Check if there is an admin account.
+func (s *AdminStore) CheckFirstRun() error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("users"))
+		// Make sure that we have a bucket in users
+		foundOne := false
+		err := b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				if userBucket := b.Bucket(k); userBucket != nil {
+					// It's a user bucket
+					// Does the bucket have a 'password' key
+					if pw := userBucket.Get([]byte("password")); pw != nil {
+						// We have a 'password' key, call it good
+						foundOne = true
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("Couldn't find an Admin User")
+		})
+		if !foundOne {
+			return fmt.Errorf("Couldn't find an Admin User")
+		}
+		return err
+	})
+	s.closeAdminDatabase()
+	return err
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// APIKey identifies a third-party app reading the directory so that
+// it can be given its own rate limit and daily quota.
+type APIKey struct {
+	ID       string // sha256 of the key, the key itself is never stored
+	Name     string
+	Contact  string
+	Rate     int // Requests per minute
+	Quota    int // Requests per day, 0 for unlimited
+	Created  time.Time
+	Revoked  time.Time
+	Requests int // Total requests made
+	Limited  int // Total requests turned away
+	LastUsed time.Time
+	Day      string // The day that DayCount is for (2006-01-02)
+	DayCount int
+}
+
+// IsRevoked
+// Has the key been revoked
+func (k APIKey) IsRevoked() bool {
+	return !k.Revoked.IsZero()
+}
+
+// API Key Model Functions
+// All API keys are stored in the admin boltdb like so
+// apikeys		(bucket)
+// |- <sha256 of key 1> (bucket)
+// | |-name			(pair)
+// | |-contact		(pair)
+// | |-rate			(pair) (requests per minute)
+// | |-quota		(pair) (requests per day)
+// | |-created		(pair) (RFC3339)
+// | |-revoked		(pair) (RFC3339, only once revoked)
+// | |-requests		(pair)
+// | |-limited		(pair)
+// | |-lastused		(pair) (RFC3339)
+// | |-day			(pair) (2006-01-02)
+// | \-daycount		(pair)
+// |
+// \- <sha256 of key 2> (bucket)
+//   ...
+//
+// The usage counters are kept in memory by the rate limiter and written
+// here periodically, see web/ratelimit.go
+
+// CreateAPIKey
+// Generates a new key, saves the hash of it and returns the key.
+// This is the only time that the key itself is available.
+func (s *AdminStore) CreateAPIKey(name, contact string, rate, quota int) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(raw)
+	if err := s.loadAdminDatabase(); err != nil {
+		return "", err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("apikeys"))
+		newB, err := b.CreateBucket([]byte(HashToken(key)))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"name":     name,
+			"contact":  contact,
+			"rate":     strconv.Itoa(rate),
+			"quota":    strconv.Itoa(quota),
+			"created":  time.Now().Format(time.RFC3339),
+			"requests": "0",
+			"limited":  "0",
+		} {
+			if err := newB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// APIKeys
+// Returns all of the API keys, including revoked ones
+func (s *AdminStore) APIKeys() ([]APIKey, error) {
+	ret := make([]APIKey, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("apikeys"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToAPIKey(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+func bucketToAPIKey(id string, kB *bolt.Bucket) APIKey {
+	ret := APIKey{ID: id}
+	ret.Name = string(kB.Get([]byte("name")))
+	ret.Contact = string(kB.Get([]byte("contact")))
+	ret.Rate, _ = strconv.Atoi(string(kB.Get([]byte("rate"))))
+	ret.Quota, _ = strconv.Atoi(string(kB.Get([]byte("quota"))))
+	ret.Created, _ = time.Parse(time.RFC3339, string(kB.Get([]byte("created"))))
+	ret.Revoked, _ = time.Parse(time.RFC3339, string(kB.Get([]byte("revoked"))))
+	ret.Requests, _ = strconv.Atoi(string(kB.Get([]byte("requests"))))
+	ret.Limited, _ = strconv.Atoi(string(kB.Get([]byte("limited"))))
+	ret.LastUsed, _ = time.Parse(time.RFC3339, string(kB.Get([]byte("lastused"))))
+	ret.Day = string(kB.Get([]byte("day")))
+	ret.DayCount, _ = strconv.Atoi(string(kB.Get([]byte("daycount"))))
+	return ret
+}
+
+// RevokeAPIKey
+// Marks a key as revoked. The record is kept so its usage can still be seen.
+func (s *AdminStore) RevokeAPIKey(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		kB := tx.Bucket([]byte("apikeys")).Bucket([]byte(id))
+		if kB == nil {
+			return fmt.Errorf("Invalid Key")
+		}
+		return kB.Put([]byte("revoked"), []byte(time.Now().Format(time.RFC3339)))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// SaveAPIKeyUsage
+// Write the usage counters for the given keys
+func (s *AdminStore) SaveAPIKeyUsage(keys []APIKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("apikeys"))
+		for i := range keys {
+			kB := b.Bucket([]byte(keys[i].ID))
+			if kB == nil {
+				continue
+			}
+			for k, v := range map[string]string{
+				"requests": strconv.Itoa(keys[i].Requests),
+				"limited":  strconv.Itoa(keys[i].Limited),
+				"lastused": keys[i].LastUsed.Format(time.RFC3339),
+				"day":      keys[i].Day,
+				"daycount": strconv.Itoa(keys[i].DayCount),
+			} {
+				if err := kB.Put([]byte(k), []byte(v)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
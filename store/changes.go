@@ -0,0 +1,191 @@
+package store
+
+import (
+	"encoding/binary"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// Change Operations
+const (
+	ChangeUpsert = "upsert"
+	ChangeDelete = "delete"
+)
+
+// Change is an entry in the change log
+type Change struct {
+	Seq      uint64    `json:"seq"`
+	Op       string    `json:"op"`
+	Title    string    `json:"title"`
+	Time     time.Time `json:"time"`
+	Resource *Resource `json:"resource,omitempty"` // Only for upserts
+}
+
+// Every save and delete of a resource is recorded in a change log so that
+// offline copies of the directory can catch up by asking for everything
+// after the last sequence they saw. Only the latest change for each title
+// is kept, so deletions stay around as tombstones and the log never grows
+// past the number of titles that have ever existed.
+// changes			(bucket)
+// \- <sequence>	(bucket) (big endian uint64, so they sort in order)
+//   |-op			(pair) (upsert/delete)
+//   |-title		(pair)
+//   \-time			(pair) (RFC3339)
+// changeindex		(bucket)
+// \- <title>		(pair) (the sequence of its latest change)
+
+// initChanges
+// Make sure that the change log exists. When it is first created every
+// existing resource is logged, so they are included in any sync.
+func initChanges(tx *bolt.Tx) error {
+	if tx.Bucket([]byte("changes")) != nil {
+		return nil
+	}
+	if _, err := tx.CreateBucket([]byte("changes")); err != nil {
+		return err
+	}
+	if _, err := tx.CreateBucket([]byte("changeindex")); err != nil {
+		return err
+	}
+	titles := make([]string, 0, 0)
+	err := tx.Bucket([]byte("resources")).ForEach(func(k, v []byte) error {
+		if v == nil {
+			titles = append(titles, string(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range titles {
+		if err := recordChange(tx, ChangeUpsert, titles[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordChange
+// Log a change to 'title', replacing any earlier change to it
+func recordChange(tx *bolt.Tx, op, title string) error {
+	b := tx.Bucket([]byte("changes"))
+	idx := tx.Bucket([]byte("changeindex"))
+	if prev := idx.Get([]byte(title)); prev != nil {
+		if err := b.DeleteBucket(prev); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := sequenceKey(seq)
+	cB, err := b.CreateBucket(key)
+	if err != nil {
+		return err
+	}
+	if err := cB.Put([]byte("op"), []byte(op)); err != nil {
+		return err
+	}
+	if err := cB.Put([]byte("title"), []byte(title)); err != nil {
+		return err
+	}
+	if err := cB.Put([]byte("time"), []byte(time.Now().Format(time.RFC3339))); err != nil {
+		return err
+	}
+	return idx.Put([]byte(title), key)
+}
+
+// Changes
+// Returns up to 'limit' changes after sequence 'since', oldest first, along
+// with the sequence to ask for next time and whether there are more.
+func (s *Store) Changes(since uint64, limit int) ([]Change, uint64, bool, error) {
+	ret := make([]Change, 0, 0)
+	next := since
+	more := false
+	if err := s.loadDatabase(); err != nil {
+		return ret, next, more, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("changes"))
+		resB := tx.Bucket([]byte("resources"))
+		c := b.Cursor()
+		for k, v := c.Seek(sequenceKey(since + 1)); k != nil; k, v = c.Next() {
+			if v != nil {
+				continue
+			}
+			if len(ret) >= limit {
+				more = true
+				break
+			}
+			cB := b.Bucket(k)
+			ch := Change{
+				Seq:   binary.BigEndian.Uint64(k),
+				Op:    string(cB.Get([]byte("op"))),
+				Title: string(cB.Get([]byte("title"))),
+			}
+			ch.Time, _ = time.Parse(time.RFC3339, string(cB.Get([]byte("time"))))
+			if ch.Op == ChangeUpsert {
+				if rB := resB.Bucket([]byte(ch.Title)); rB != nil {
+					res := bucketToResource(ch.Title, rB)
+					ch.Resource = &res
+				}
+			}
+			ret = append(ret, ch)
+			next = ch.Seq
+		}
+		if !more {
+			next = b.Sequence()
+		}
+		return nil
+	})
+	s.closeDatabase()
+	return ret, next, more, err
+}
+
+// Snapshot
+// Returns every resource and the sequence of the latest change, read
+// together so that syncing from the sequence misses nothing.
+func (s *Store) Snapshot() ([]Resource, uint64, error) {
+	ret := make([]Resource, 0, 0)
+	var seq uint64
+	if err := s.loadDatabase(); err != nil {
+		return ret, seq, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seq = tx.Bucket([]byte("changes")).Sequence()
+		b := tx.Bucket([]byte("resources"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				ret = append(ret, bucketToResource(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeDatabase()
+	return ret, seq, err
+}
+
+// Sequence
+// Returns the sequence of the latest change
+func (s *Store) Sequence() (uint64, error) {
+	var seq uint64
+	if err := s.loadDatabase(); err != nil {
+		return seq, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seq = tx.Bucket([]byte("changes")).Sequence()
+		return nil
+	})
+	s.closeDatabase()
+	return seq, err
+}
+
+// sequenceKey
+// Bolt keys for sequences are big endian so that they sort in order
+func sequenceKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cost is what a resource costs and how families can pay for it
+type Cost struct {
+	Free             bool   `json:"free,omitempty"`
+	SlidingScale     bool   `json:"sliding_scale,omitempty"` // Based on income
+	FlatFee          string `json:"flat_fee,omitempty"`      // Like "$25 per visit"
+	Medicaid         bool   `json:"medicaid,omitempty"`      // Accepts KanCare/Medicaid
+	CHIP             bool   `json:"chip,omitempty"`
+	PrivateInsurance bool   `json:"private_insurance,omitempty"`
+	Uninsured        bool   `json:"uninsured,omitempty"` // Uninsured families welcome
+	Notes            string `json:"notes,omitempty"`
+}
+
+// Cost Options
+// The parts of a Cost that are yes or no, as they are stored and posted
+// from the admin form
+const (
+	CostFree             = "free"
+	CostSlidingScale     = "sliding_scale"
+	CostFlatFee          = "flat_fee"
+	CostMedicaid         = "medicaid"
+	CostCHIP             = "chip"
+	CostPrivateInsurance = "private_insurance"
+	CostUninsured        = "uninsured"
+)
+
+// CostOption is a Cost Option and how it's shown to families
+type CostOption struct {
+	Key   string
+	Label string
+}
+
+// CostOptions are the Cost Options in the order they're shown
+var CostOptions = []CostOption{
+	{CostFree, "Free"},
+	{CostSlidingScale, "Sliding scale"},
+	{CostFlatFee, "Flat fee"},
+	{CostMedicaid, "Accepts KanCare/Medicaid"},
+	{CostCHIP, "Accepts CHIP"},
+	{CostPrivateInsurance, "Accepts private insurance"},
+	{CostUninsured, "Uninsured welcome"},
+}
+
+// IsEmpty
+// Has nobody said what the resource costs
+func (c Cost) IsEmpty() bool {
+	return len(c.Options()) == 0 && c.Notes == ""
+}
+
+// Has
+// Does the cost include the option 'key'
+func (c Cost) Has(key string) bool {
+	switch key {
+	case CostFree:
+		return c.Free
+	case CostSlidingScale:
+		return c.SlidingScale
+	case CostFlatFee:
+		return c.FlatFee != ""
+	case CostMedicaid:
+		return c.Medicaid
+	case CostCHIP:
+		return c.CHIP
+	case CostPrivateInsurance:
+		return c.PrivateInsurance
+	case CostUninsured:
+		return c.Uninsured
+	}
+	return false
+}
+
+// Options
+// Returns the keys of the Cost Options that the cost includes
+func (c Cost) Options() []string {
+	ret := make([]string, 0, len(CostOptions))
+	for _, o := range CostOptions {
+		if c.Has(o.Key) {
+			ret = append(ret, o.Key)
+		}
+	}
+	return ret
+}
+
+// Labels
+// Returns the labels of the Cost Options that the cost includes
+func (c Cost) Labels() []string {
+	ret := make([]string, 0, len(CostOptions))
+	for _, o := range CostOptions {
+		if c.Has(o.Key) {
+			ret = append(ret, o.Label)
+		}
+	}
+	return ret
+}
+
+// ParseCost
+// Build a cost from Cost Option keys (or labels), along with the flat fee
+// and notes. The flat fee option is implied by giving a flat fee.
+func ParseCost(options []string, flatFee, notes string) (Cost, error) {
+	c := Cost{FlatFee: strings.TrimSpace(flatFee), Notes: strings.TrimSpace(notes)}
+	for _, opt := range CleanList(options) {
+		key, ok := costOptionKey(opt)
+		if !ok {
+			return c, fmt.Errorf("Invalid cost option: %s", opt)
+		}
+		switch key {
+		case CostFree:
+			c.Free = true
+		case CostSlidingScale:
+			c.SlidingScale = true
+		case CostFlatFee:
+			if c.FlatFee == "" {
+				return c, fmt.Errorf("Flat fee needs an amount, like \"$25 per visit\"")
+			}
+		case CostMedicaid:
+			c.Medicaid = true
+		case CostCHIP:
+			c.CHIP = true
+		case CostPrivateInsurance:
+			c.PrivateInsurance = true
+		case CostUninsured:
+			c.Uninsured = true
+		}
+	}
+	return c, nil
+}
+
+func costOptionKey(s string) (string, bool) {
+	for _, o := range CostOptions {
+		if strings.EqualFold(s, o.Key) || strings.EqualFold(s, o.Label) {
+			return o.Key, true
+		}
+	}
+	return "", false
+}
+
+// CostLabel
+// Returns the label for a Cost Option key
+func CostLabel(key string) string {
+	for _, o := range CostOptions {
+		if o.Key == key {
+			return o.Label
+		}
+	}
+	return key
+}
+
+// ValidateCost
+// Make sure that a cost makes sense
+func ValidateCost(c Cost) error {
+	if c.Free && c.FlatFee != "" {
+		return fmt.Errorf("A resource can't be free and have a flat fee")
+	}
+	return nil
+}
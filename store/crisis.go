@@ -0,0 +1,204 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/br0xen/bolt"
+)
+
+// Crisis is an emergency message that is shown at the top of the page when
+// someone searches for one of its terms, like "suicide" or "not breathing"
+type Crisis struct {
+	ID      string
+	Terms   []string
+	Message string
+	Phone   string // Hotline number, shown as a link to call it
+	Link    string // Optional page with more help
+}
+
+// Crisis Model Functions
+// Crisis messages and site settings are stored in the admin boltdb like so
+// crisis		(bucket)
+// \- <id> (bucket) (a sequence number)
+//   |-terms		(pair) (csv)
+//   |-message	(pair)
+//   |-phone		(pair)
+//   \-link		(pair)
+// settings		(bucket)
+// \- <key>		(pair)
+
+// Settings
+// Keys for the site settings kept in the admin database
+const (
+	SettingBannerMessage = "banner.message"
+	SettingBannerStatus  = "banner.status"
+	SettingBannerLink    = "banner.link"
+)
+
+// ValidateCrisis
+// Make sure that a crisis message has something to match and something to say
+func ValidateCrisis(c Crisis) error {
+	if len(c.Terms) == 0 {
+		return fmt.Errorf("Crisis message needs at least one term")
+	}
+	if c.Message == "" {
+		return fmt.Errorf("Crisis message is required")
+	}
+	return nil
+}
+
+// SaveCrisis
+// Save a crisis message, a new ID is given to it if it doesn't have one yet
+func (s *AdminStore) SaveCrisis(c Crisis) (Crisis, error) {
+	c.Terms = CleanList(c.Terms)
+	c.Message = strings.TrimSpace(c.Message)
+	c.Phone = strings.TrimSpace(c.Phone)
+	c.Link = strings.TrimSpace(c.Link)
+	if err := ValidateCrisis(c); err != nil {
+		return c, err
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return c, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("crisis"))
+		if c.ID == "" {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			c.ID = strconv.FormatUint(seq, 10)
+		}
+		cB, err := b.CreateBucketIfNotExists([]byte(c.ID))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"terms":   strings.Join(c.Terms, ","),
+			"message": c.Message,
+			"phone":   c.Phone,
+			"link":    c.Link,
+		} {
+			if err := cB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return c, err
+}
+
+// Crises
+// Returns every crisis message, in the order they were added
+func (s *AdminStore) Crises() ([]Crisis, error) {
+	ret := make([]Crisis, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("crisis"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToCrisis(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	sort.Slice(ret, func(i, j int) bool {
+		ni, _ := strconv.Atoi(ret[i].ID)
+		nj, _ := strconv.Atoi(ret[j].ID)
+		return ni < nj
+	})
+	return ret, err
+}
+
+// Crisis
+// Returns a single crisis message
+func (s *AdminStore) Crisis(id string) (Crisis, error) {
+	var ret Crisis
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cB := tx.Bucket([]byte("crisis")).Bucket([]byte(id))
+		if cB == nil {
+			return fmt.Errorf("Crisis message not found: %s", id)
+		}
+		ret = bucketToCrisis(id, cB)
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// DeleteCrisis
+// Remove a crisis message
+func (s *AdminStore) DeleteCrisis(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("crisis")).DeleteBucket([]byte(id))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+func bucketToCrisis(id string, cB *bolt.Bucket) Crisis {
+	ret := Crisis{ID: id}
+	if rVal := cB.Get([]byte("terms")); len(rVal) > 0 {
+		ret.Terms = strings.Split(string(rVal), ",")
+	}
+	ret.Message = string(cB.Get([]byte("message")))
+	ret.Phone = string(cB.Get([]byte("phone")))
+	ret.Link = string(cB.Get([]byte("link")))
+	return ret
+}
+
+// Settings
+// Returns the values of the site settings in 'keys', missing ones are blank
+func (s *AdminStore) Settings(keys ...string) (map[string]string, error) {
+	ret := make(map[string]string)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("settings"))
+		for _, k := range keys {
+			ret[k] = string(b.Get([]byte(k)))
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// SaveSettings
+// Save site settings, blank values remove the setting
+func (s *AdminStore) SaveSettings(settings map[string]string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("settings"))
+		for k, v := range settings {
+			var err error
+			if v = strings.TrimSpace(v); v == "" {
+				err = b.Delete([]byte(k))
+			} else {
+				err = b.Put([]byte(k), []byte(v))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
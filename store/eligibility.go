@@ -0,0 +1,319 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openwichita/infant-info/geo"
+)
+
+// Eligibility is who a resource is for. Blank fields don't limit anything.
+// A family that is pregnant in one of the Pregnancy stages or has a child in
+// the ChildAge range qualifies when both are given, since programs are
+// usually for "pregnant women or children under 1".
+type Eligibility struct {
+	ParentAge AgeRange `json:"parent_age"` // Years, like 0-19 for teen parents
+	ChildAge  AgeRange `json:"child_age"`  // Months, like 0-11 for under 1 year
+	Pregnancy []string `json:"pregnancy"`  // Pregnancy Stages
+	// Household income at or below this percent of the federal poverty level
+	IncomeFPL int `json:"income_fpl,omitempty"`
+	// Where the family has to live, like "county:Sedgwick", see geo.ServiceArea
+	Residency []string `json:"residency"`
+	Insurance []string `json:"insurance"` // Insurance Statuses, the family needs one
+	Notes     string   `json:"notes,omitempty"`
+}
+
+// AgeRange is from Min to Max inclusive. A Max of 0 means no limit.
+type AgeRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// Pregnancy Stages
+const (
+	PregnancyFirst      = "first_trimester"
+	PregnancySecond     = "second_trimester"
+	PregnancyThird      = "third_trimester"
+	PregnancyPostpartum = "postpartum"
+)
+
+// Insurance Statuses
+const (
+	InsuranceMedicaid  = "medicaid"
+	InsuranceCHIP      = "chip"
+	InsurancePrivate   = "private"
+	InsuranceUninsured = "uninsured"
+)
+
+// EligibilityOption is a Pregnancy Stage or Insurance Status and how it's
+// shown to families
+type EligibilityOption struct {
+	Key   string
+	Label string
+}
+
+// PregnancyStages are the Pregnancy Stages in order
+var PregnancyStages = []EligibilityOption{
+	{PregnancyFirst, "First trimester"},
+	{PregnancySecond, "Second trimester"},
+	{PregnancyThird, "Third trimester"},
+	{PregnancyPostpartum, "Postpartum"},
+}
+
+// InsuranceStatuses are the Insurance Statuses in order
+var InsuranceStatuses = []EligibilityOption{
+	{InsuranceMedicaid, "KanCare/Medicaid"},
+	{InsuranceCHIP, "CHIP"},
+	{InsurancePrivate, "Private insurance"},
+	{InsuranceUninsured, "Uninsured"},
+}
+
+// IsEmpty
+// Is the resource for everyone, or has nobody said
+func (e Eligibility) IsEmpty() bool {
+	return e.ParentAge.IsEmpty() && e.ChildAge.IsEmpty() && len(e.Pregnancy) == 0 &&
+		e.IncomeFPL == 0 && len(e.Residency) == 0 && len(e.Insurance) == 0 && e.Notes == ""
+}
+
+// HasPregnancy
+// Does the resource take families in Pregnancy Stage 'key'
+func (e Eligibility) HasPregnancy(key string) bool {
+	return containsString(e.Pregnancy, key)
+}
+
+// HasInsurance
+// Does the resource take families with Insurance Status 'key'
+func (e Eligibility) HasInsurance(key string) bool {
+	return containsString(e.Insurance, key)
+}
+
+// Requirements
+// Returns who the resource is for, for people to read, like
+// "Pregnant or children under 1 year" and "Parents 19 and under"
+func (e Eligibility) Requirements() []string {
+	ret := make([]string, 0, 5)
+	var who []string
+	if len(e.Pregnancy) == len(PregnancyStages) {
+		who = append(who, "Pregnant or postpartum")
+	} else if len(e.Pregnancy) > 0 {
+		who = append(who, "Pregnant ("+strings.ToLower(optionLabels(PregnancyStages, e.Pregnancy))+")")
+	}
+	if !e.ChildAge.IsEmpty() {
+		who = append(who, "children "+e.ChildAge.monthsLabel())
+	}
+	if len(who) > 0 {
+		s := strings.Join(who, " or ")
+		ret = append(ret, strings.ToUpper(s[:1])+s[1:])
+	}
+	if !e.ParentAge.IsEmpty() {
+		ret = append(ret, "Parents "+e.ParentAge.yearsLabel())
+	}
+	if e.IncomeFPL > 0 {
+		ret = append(ret, fmt.Sprintf("Income at or below %d%% of the poverty level", e.IncomeFPL))
+	}
+	if len(e.Residency) > 0 {
+		places := make([]string, 0, len(e.Residency))
+		for _, r := range e.Residency {
+			if a, err := geo.ParseServiceArea(r); err == nil {
+				places = append(places, a.Label())
+			}
+		}
+		ret = append(ret, "Lives in "+strings.Join(places, " or "))
+	}
+	if len(e.Insurance) > 0 {
+		ret = append(ret, "Has "+optionLabels(InsuranceStatuses, e.Insurance))
+	}
+	return ret
+}
+
+// optionLabels
+// Returns the labels for 'keys', like "CHIP or Uninsured"
+func optionLabels(opts []EligibilityOption, keys []string) string {
+	ret := make([]string, 0, len(keys))
+	for _, o := range opts {
+		if containsString(keys, o.Key) {
+			ret = append(ret, o.Label)
+		}
+	}
+	return strings.Join(ret, " or ")
+}
+
+// ValidateEligibility
+// Make sure that eligibility uses known stages, statuses and areas
+func ValidateEligibility(e Eligibility) error {
+	if err := e.ParentAge.validate("Parent age"); err != nil {
+		return err
+	}
+	if err := e.ChildAge.validate("Child age"); err != nil {
+		return err
+	}
+	for _, p := range e.Pregnancy {
+		if !hasOption(PregnancyStages, p) {
+			return fmt.Errorf("Invalid pregnancy stage: %s", p)
+		}
+	}
+	if e.IncomeFPL < 0 {
+		return fmt.Errorf("Income limit can't be negative: %d", e.IncomeFPL)
+	}
+	for _, r := range e.Residency {
+		if _, err := geo.ParseServiceArea(r); err != nil {
+			return err
+		}
+	}
+	for _, i := range e.Insurance {
+		if !hasOption(InsuranceStatuses, i) {
+			return fmt.Errorf("Invalid insurance status: %s", i)
+		}
+	}
+	return nil
+}
+
+// normalize
+// Returns the eligibility with its lists cleaned up and areas written the
+// standard way. It should already be valid.
+func (e Eligibility) normalize() Eligibility {
+	e.Pregnancy = optionKeys(PregnancyStages, e.Pregnancy)
+	e.Insurance = optionKeys(InsuranceStatuses, e.Insurance)
+	e.Residency = CleanList(e.Residency)
+	for i := range e.Residency {
+		a, _ := geo.ParseServiceArea(e.Residency[i])
+		e.Residency[i] = a.String()
+	}
+	e.Notes = strings.TrimSpace(e.Notes)
+	return e
+}
+
+func hasOption(opts []EligibilityOption, key string) bool {
+	for _, o := range opts {
+		if o.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// optionKeys
+// Returns the keys of 'opts' that are in 'keys', in order
+func optionKeys(opts []EligibilityOption, keys []string) []string {
+	ret := make([]string, 0, len(keys))
+	for _, o := range opts {
+		if containsString(keys, o.Key) {
+			ret = append(ret, o.Key)
+		}
+	}
+	return ret
+}
+
+// ParseAgeRange
+// Read an age range like "0-11", "18-" (18 and up), "-19" (up to 19) or
+// "2" (just 2). Blank is no range.
+func ParseAgeRange(s string) (AgeRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return AgeRange{}, nil
+	}
+	ends := strings.SplitN(s, "-", 2)
+	if len(ends) == 1 {
+		ends = append(ends, ends[0])
+	}
+	var r AgeRange
+	var err error
+	if v := strings.TrimSpace(ends[0]); v != "" {
+		if r.Min, err = strconv.Atoi(v); err != nil {
+			return r, fmt.Errorf("Invalid age range: %s", s)
+		}
+	}
+	if v := strings.TrimSpace(ends[1]); v != "" {
+		if r.Max, err = strconv.Atoi(v); err != nil {
+			return r, fmt.Errorf("Invalid age range: %s", s)
+		}
+	}
+	return r, nil
+}
+
+// String
+// Returns the range the way ParseAgeRange reads it
+func (r AgeRange) String() string {
+	switch {
+	case r.IsEmpty():
+		return ""
+	case r.Max == 0:
+		return strconv.Itoa(r.Min) + "-"
+	case r.Min == 0:
+		return "-" + strconv.Itoa(r.Max)
+	}
+	return strconv.Itoa(r.Min) + "-" + strconv.Itoa(r.Max)
+}
+
+// IsEmpty
+// Does the range let any age through
+func (r AgeRange) IsEmpty() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// Includes
+// Is 'age' in the range
+func (r AgeRange) Includes(age int) bool {
+	return age >= r.Min && (r.Max == 0 || age <= r.Max)
+}
+
+func (r AgeRange) validate(name string) error {
+	if r.Min < 0 || r.Max < 0 {
+		return fmt.Errorf("%s can't be negative: %s", name, r)
+	}
+	if r.Max > 0 && r.Max < r.Min {
+		return fmt.Errorf("%s ends before it starts: %s", name, r)
+	}
+	return nil
+}
+
+// yearsLabel
+// Describes a range of years, like "19 and under"
+func (r AgeRange) yearsLabel() string {
+	switch {
+	case r.Max == 0:
+		return fmt.Sprintf("%d and over", r.Min)
+	case r.Min == 0:
+		return fmt.Sprintf("%d and under", r.Max)
+	}
+	return fmt.Sprintf("%d to %d", r.Min, r.Max)
+}
+
+// monthsLabel
+// Describes a range of months, using years where they fit, like "under 1 year"
+func (r AgeRange) monthsLabel() string {
+	age := func(months int) string {
+		if months%12 == 0 && months > 0 {
+			if months == 12 {
+				return "1 year"
+			}
+			return fmt.Sprintf("%d years", months/12)
+		}
+		if months == 1 {
+			return "1 month"
+		}
+		return fmt.Sprintf("%d months", months)
+	}
+	switch {
+	case r.Max == 0:
+		return age(r.Min) + " and older"
+	case r.Min == 0:
+		return "under " + age(r.Max+1)
+	case r.Min == r.Max:
+		return age(r.Min) + " old"
+	}
+	if r.Min%12 == 0 && (r.Max+1)%12 == 0 {
+		return fmt.Sprintf("%d to %d years old", r.Min/12, (r.Max+1)/12-1)
+	}
+	return fmt.Sprintf("%d to %d months old", r.Min, r.Max)
+}
+
+func containsString(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
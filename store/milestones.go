@@ -0,0 +1,231 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// Milestone is something to do or know at a point in a pregnancy or the
+// baby's first year, like "Week 28: sign up for a childbirth class", with
+// the resources that help
+type Milestone struct {
+	ID          string
+	Stage       string // MilestonePregnancy or MilestoneBaby
+	Week        int    // Week of pregnancy, or weeks old
+	Title       string
+	Description string
+	Tags        []string // Resources with any of these tags are linked
+	Resources   []string // Titles of resources to link
+}
+
+// Milestone Stages
+const (
+	MilestonePregnancy = "pregnancy"
+	MilestoneBaby      = "baby"
+)
+
+// Weeks in a full term pregnancy, the due date is the start of week 41
+const pregnancyWeeks = 40
+
+// Milestones can be this many weeks into a pregnancy or baby's life
+const (
+	maxPregnancyWeek = 42
+	maxBabyWeek      = 104
+)
+
+// Milestone Model Functions
+// Timeline milestones are stored in the admin boltdb like so
+// milestones		(bucket)
+// \- <id> (bucket) (a sequence number)
+//   |-stage		(pair)
+//   |-week		(pair)
+//   |-title		(pair)
+//   |-description	(pair)
+//   |-tags		(pair) (csv)
+//   \-resources	(pair) (one title to a line)
+
+// ValidateMilestone
+// Make sure that a milestone has a title and a week that makes sense
+func ValidateMilestone(m Milestone) error {
+	if m.Title == "" {
+		return fmt.Errorf("Milestone title is required")
+	}
+	switch m.Stage {
+	case MilestonePregnancy:
+		if m.Week < 1 || m.Week > maxPregnancyWeek {
+			return fmt.Errorf("Pregnancy milestones should be in weeks 1 to %d: %d", maxPregnancyWeek, m.Week)
+		}
+	case MilestoneBaby:
+		if m.Week < 0 || m.Week > maxBabyWeek {
+			return fmt.Errorf("Baby milestones should be 0 to %d weeks old: %d", maxBabyWeek, m.Week)
+		}
+	default:
+		return fmt.Errorf("Invalid milestone stage: %s", m.Stage)
+	}
+	return nil
+}
+
+// Date
+// Returns the day the milestone falls on for a baby due (or born) on 'due'
+func (m Milestone) Date(due time.Time) time.Time {
+	if m.Stage == MilestoneBaby {
+		return due.AddDate(0, 0, 7*m.Week)
+	}
+	return due.AddDate(0, 0, -7*(pregnancyWeeks-m.Week+1))
+}
+
+// When
+// Describes when the milestone is, like "Week 12" or "2 months old"
+func (m Milestone) When() string {
+	if m.Stage == MilestonePregnancy {
+		return fmt.Sprintf("Week %d", m.Week)
+	}
+	switch {
+	case m.Week == 0:
+		return "Newborn"
+	case m.Week == 1:
+		return "1 week old"
+	case m.Week < 8 || m.Week%4 != 0 && m.Week < 52:
+		return fmt.Sprintf("%d weeks old", m.Week)
+	}
+	// Roughly, 2 months is 8 weeks, 6 months is 26 and a year is 52
+	months := int(float64(m.Week)*12/52 + 0.5)
+	if months == 12 {
+		return "1 year old"
+	}
+	return fmt.Sprintf("%d months old", months)
+}
+
+// order
+// Returns where the milestone falls counting from the start of pregnancy
+func (m Milestone) order() int {
+	if m.Stage == MilestoneBaby {
+		return pregnancyWeeks + 1 + m.Week
+	}
+	return m.Week
+}
+
+// SaveMilestone
+// Save a milestone, a new ID is given to it if it doesn't have one yet
+func (s *AdminStore) SaveMilestone(m Milestone) (Milestone, error) {
+	m.Stage = strings.ToLower(strings.TrimSpace(m.Stage))
+	m.Title = strings.TrimSpace(m.Title)
+	m.Description = strings.TrimSpace(m.Description)
+	m.Tags = CleanList(m.Tags)
+	m.Resources = CleanList(m.Resources)
+	if err := ValidateMilestone(m); err != nil {
+		return m, err
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return m, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("milestones"))
+		if m.ID == "" {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			m.ID = strconv.FormatUint(seq, 10)
+		}
+		mB, err := b.CreateBucketIfNotExists([]byte(m.ID))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"stage":       m.Stage,
+			"week":        strconv.Itoa(m.Week),
+			"title":       m.Title,
+			"description": m.Description,
+			"tags":        strings.Join(m.Tags, ","),
+			"resources":   strings.Join(m.Resources, "\n"),
+		} {
+			if err := mB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return m, err
+}
+
+// Milestones
+// Returns every milestone, in the order they happen
+func (s *AdminStore) Milestones() ([]Milestone, error) {
+	ret := make([]Milestone, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("milestones"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToMilestone(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].order() != ret[j].order() {
+			return ret[i].order() < ret[j].order()
+		}
+		ni, _ := strconv.Atoi(ret[i].ID)
+		nj, _ := strconv.Atoi(ret[j].ID)
+		return ni < nj
+	})
+	return ret, err
+}
+
+// Milestone
+// Returns a single milestone
+func (s *AdminStore) Milestone(id string) (Milestone, error) {
+	var ret Milestone
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		mB := tx.Bucket([]byte("milestones")).Bucket([]byte(id))
+		if mB == nil {
+			return fmt.Errorf("Milestone not found: %s", id)
+		}
+		ret = bucketToMilestone(id, mB)
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// DeleteMilestone
+// Remove a milestone
+func (s *AdminStore) DeleteMilestone(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("milestones")).DeleteBucket([]byte(id))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+func bucketToMilestone(id string, mB *bolt.Bucket) Milestone {
+	ret := Milestone{ID: id}
+	ret.Stage = string(mB.Get([]byte("stage")))
+	ret.Week, _ = strconv.Atoi(string(mB.Get([]byte("week"))))
+	ret.Title = string(mB.Get([]byte("title")))
+	ret.Description = string(mB.Get([]byte("description")))
+	if rVal := mB.Get([]byte("tags")); len(rVal) > 0 {
+		ret.Tags = strings.Split(string(rVal), ",")
+	}
+	if rVal := mB.Get([]byte("resources")); len(rVal) > 0 {
+		ret.Resources = strings.Split(string(rVal), "\n")
+	}
+	return ret
+}
@@ -0,0 +1,705 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/openwichita/infant-info/hours"
+)
+
+// Organization runs one or more resources (its programs), from one or more
+// Locations
+type Organization struct {
+	ID           string `json:"id"` // From the name, it doesn't change when renamed
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Website      string `json:"website"`
+	ContactName  string `json:"contact_name"`
+	ContactEmail string `json:"contact_email"`
+	ContactPhone string `json:"contact_phone"`
+	Logo         string `json:"logo"` // URL of an image
+	// Filled in when the organization is read, it isn't saved with it
+	Locations []Location `json:"locations"`
+}
+
+// Location is a place where an organization offers its programs
+type Location struct {
+	ID        string         `json:"id"` // A sequence number
+	OrgID     string         `json:"org_id"`
+	Name      string         `json:"name"` // Like "Main Office", can be blank
+	Address   string         `json:"address"`
+	Schedule  hours.Schedule `json:"schedule"`
+	Phones    []string       `json:"phones"`
+	Latitude  float64        `json:"latitude,omitempty"`
+	Longitude float64        `json:"longitude,omitempty"`
+}
+
+// Organization Model Functions
+// Organizations and their locations are stored in the boltdb like so
+// orgs			(bucket)
+// \- <id> (bucket) (from the name, see orgID)
+//   |-name		(pair)
+//   |-description	(pair)
+//   |-website		(pair)
+//   |-contact_name	(pair)
+//   |-contact_email	(pair)
+//   |-contact_phone	(pair)
+//   \-logo		(pair)
+// locations		(bucket)
+// \- <id> (bucket) (a sequence number)
+//   |-org		(pair)
+//   |-name		(pair)
+//   |-address		(pair)
+//   |-schedule	(pair) (one rule to a line, see hours.Parse)
+//   |-phones		(pair) (one to a line)
+//   |-latitude		(pair)
+//   \-longitude	(pair)
+//
+// Resources keep the ID of their organization and of their locations. A
+// resource's org name is copied from its organization, and its address,
+// coordinates, phone and hours from its first location, whenever it or they
+// are saved.
+
+// ValidateOrganization
+// Make sure that an organization has a name
+func ValidateOrganization(org Organization) error {
+	if org.Name == "" {
+		return fmt.Errorf("Organization name is required")
+	}
+	return nil
+}
+
+// ValidateLocation
+// Make sure that a location belongs to an organization and can be found
+func ValidateLocation(loc Location) error {
+	if loc.OrgID == "" {
+		return fmt.Errorf("Location organization is required")
+	}
+	if loc.Address == "" {
+		return fmt.Errorf("Location address is required")
+	}
+	if _, err := loc.Schedule.Normalize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// orgID
+// Returns the ID for an organization named 'name', like "healthy-babies"
+func orgID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "org"
+	}
+	return b.String()
+}
+
+// SaveOrganization
+// Save an organization, a new ID is given to it if it doesn't have one yet.
+// Its programs are saved again when it's renamed, so they show the new name.
+func (s *Store) SaveOrganization(org Organization) (Organization, error) {
+	org.Name = strings.TrimSpace(org.Name)
+	org.Description = strings.TrimSpace(org.Description)
+	org.Website = strings.TrimSpace(org.Website)
+	org.ContactName = strings.TrimSpace(org.ContactName)
+	org.ContactEmail = strings.TrimSpace(org.ContactEmail)
+	org.ContactPhone = strings.TrimSpace(org.ContactPhone)
+	org.Logo = strings.TrimSpace(org.Logo)
+	if err := ValidateOrganization(org); err != nil {
+		return org, err
+	}
+	var prev Organization
+	if org.ID != "" {
+		var err error
+		if prev, err = s.Organization(org.ID); err != nil {
+			return org, err
+		}
+	}
+	var events []Event
+	if err := s.loadDatabase(); err != nil {
+		return org, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("orgs"))
+		if org.ID == "" {
+			base := orgID(org.Name)
+			org.ID = base
+			for i := 2; b.Bucket([]byte(org.ID)) != nil; i++ {
+				org.ID = base + "-" + strconv.Itoa(i)
+			}
+		}
+		oB, err := b.CreateBucketIfNotExists([]byte(org.ID))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"name":          org.Name,
+			"description":   org.Description,
+			"website":       org.Website,
+			"contact_name":  org.ContactName,
+			"contact_email": org.ContactEmail,
+			"contact_phone": org.ContactPhone,
+			"logo":          org.Logo,
+		} {
+			if err := oB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		if prev.ID == "" || prev.Name == org.Name {
+			return nil
+		}
+		events, err = relink(tx, func(res Resource) bool { return res.OrgID == org.ID }, func(res *Resource) {})
+		return err
+	})
+	s.closeDatabase()
+	if err != nil {
+		return org, err
+	}
+	s.notifyAll(events)
+	return org, nil
+}
+
+// Organizations
+// Returns every organization with its locations, ordered by name
+func (s *Store) Organizations() ([]Organization, error) {
+	ret := make([]Organization, 0, 0)
+	if err := s.loadDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("orgs"))
+		locs := orgLocations(tx)
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				org := bucketToOrganization(string(k), b.Bucket(k))
+				if l, ok := locs[org.ID]; ok {
+					org.Locations = l
+				}
+				ret = append(ret, org)
+			}
+			return nil
+		})
+	})
+	s.closeDatabase()
+	sort.SliceStable(ret, func(i, j int) bool {
+		return strings.ToLower(ret[i].Name) < strings.ToLower(ret[j].Name)
+	})
+	return ret, err
+}
+
+// Organization
+// Returns a single organization with its locations
+func (s *Store) Organization(id string) (Organization, error) {
+	var ret Organization
+	if err := s.loadDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		oB := tx.Bucket([]byte("orgs")).Bucket([]byte(id))
+		if oB == nil {
+			return fmt.Errorf("Organization not found: %s", id)
+		}
+		ret = bucketToOrganization(id, oB)
+		if l, ok := orgLocations(tx)[id]; ok {
+			ret.Locations = l
+		}
+		return nil
+	})
+	s.closeDatabase()
+	return ret, err
+}
+
+// DeleteOrganization
+// Remove an organization and its locations. Its programs are kept, with
+// the organization's name, but aren't linked to it anymore.
+func (s *Store) DeleteOrganization(id string) error {
+	org, err := s.Organization(id)
+	if err != nil {
+		return err
+	}
+	var events []Event
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		lb := tx.Bucket([]byte("locations"))
+		for _, loc := range org.Locations {
+			if err := lb.DeleteBucket([]byte(loc.ID)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket([]byte("orgs")).DeleteBucket([]byte(id)); err != nil {
+			return err
+		}
+		events, err = relink(tx, func(res Resource) bool { return res.OrgID == id }, func(res *Resource) {
+			res.OrgID, res.LocationIDs = "", nil
+		})
+		return err
+	})
+	s.closeDatabase()
+	if err != nil {
+		return err
+	}
+	s.notifyAll(events)
+	return nil
+}
+
+// MergeOrganization
+// Move the locations and programs of organization 'from' to 'into', then
+// remove 'from'. For cleaning up duplicates made by ImportOrganizations.
+func (s *Store) MergeOrganization(from, into string) error {
+	if from == into {
+		return fmt.Errorf("Can't merge an organization into itself")
+	}
+	src, err := s.Organization(from)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Organization(into); err != nil {
+		return err
+	}
+	var events []Event
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		lb := tx.Bucket([]byte("locations"))
+		for _, loc := range src.Locations {
+			if err := lb.Bucket([]byte(loc.ID)).Put([]byte("org"), []byte(into)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Bucket([]byte("orgs")).DeleteBucket([]byte(from)); err != nil {
+			return err
+		}
+		events, err = relink(tx, func(res Resource) bool { return res.OrgID == from }, func(res *Resource) {
+			res.OrgID = into
+		})
+		return err
+	})
+	s.closeDatabase()
+	if err != nil {
+		return err
+	}
+	s.notifyAll(events)
+	return nil
+}
+
+// ImportOrganizations
+// Make an organization for each org name the resources have that isn't one
+// yet, and link the resources to it. Names are matched ignoring case.
+// Returns how many resources were linked.
+func (s *Store) ImportOrganizations() (int, error) {
+	orgs, err := s.Organizations()
+	if err != nil {
+		return 0, err
+	}
+	byName := make(map[string]string)
+	for _, org := range orgs {
+		byName[strings.ToLower(org.Name)] = org.ID
+	}
+	resources, err := s.Resources()
+	if err != nil {
+		return 0, err
+	}
+	linked := 0
+	for _, res := range resources {
+		name := strings.TrimSpace(res.Org)
+		if res.OrgID != "" || name == "" {
+			continue
+		}
+		id, ok := byName[strings.ToLower(name)]
+		if !ok {
+			org, err := s.SaveOrganization(Organization{Name: name})
+			if err != nil {
+				return linked, err
+			}
+			id, byName[strings.ToLower(name)] = org.ID, org.ID
+		}
+		res.OrgID = id
+		if err := s.Save(res.Title, res); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+// SaveLocation
+// Save a location, a new ID is given to it if it doesn't have one yet. The
+// programs that have it as their first location are updated, so they show
+// its address, phone and hours.
+func (s *Store) SaveLocation(loc Location) (Location, error) {
+	loc.Name = strings.TrimSpace(loc.Name)
+	loc.Address = strings.TrimSpace(loc.Address)
+	loc.Phones = CleanList(loc.Phones)
+	if err := ValidateLocation(loc); err != nil {
+		return loc, err
+	}
+	if _, err := s.Organization(loc.OrgID); err != nil {
+		return loc, err
+	}
+	var prev Location
+	if loc.ID != "" {
+		var err error
+		if prev, err = s.Location(loc.ID); err != nil {
+			return loc, err
+		}
+		if prev.OrgID != loc.OrgID {
+			return loc, fmt.Errorf("Locations can't be moved to another organization, merge them instead")
+		}
+	}
+	loc.Schedule, _ = loc.Schedule.Normalize()
+	if s.geocoder != nil && (prev.Address != loc.Address || loc.Latitude == 0 && loc.Longitude == 0) {
+		if lat, lng, ok := s.geocoder.Geocode(loc.Address); ok {
+			loc.Latitude, loc.Longitude = lat, lng
+		}
+	}
+	var events []Event
+	if err := s.loadDatabase(); err != nil {
+		return loc, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("locations"))
+		if loc.ID == "" {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			loc.ID = strconv.FormatUint(seq, 10)
+		}
+		lB, err := b.CreateBucketIfNotExists([]byte(loc.ID))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"org":       loc.OrgID,
+			"name":      loc.Name,
+			"address":   loc.Address,
+			"schedule":  loc.Schedule.String(),
+			"phones":    strings.Join(loc.Phones, "\n"),
+			"latitude":  strconv.FormatFloat(loc.Latitude, 'f', -1, 64),
+			"longitude": strconv.FormatFloat(loc.Longitude, 'f', -1, 64),
+		} {
+			if err := lB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		if prev.ID == "" {
+			return nil
+		}
+		events, err = relink(tx, func(res Resource) bool {
+			return len(res.LocationIDs) > 0 && res.LocationIDs[0] == loc.ID
+		}, func(res *Resource) {
+			// Forget what was copied from the location as it was, so
+			// anything taken off of it goes from the programs too
+			unlinkLocation(res, prev)
+			res.LocationIDs = append([]string{loc.ID}, res.LocationIDs...)
+		})
+		return err
+	})
+	s.closeDatabase()
+	if err != nil {
+		return loc, err
+	}
+	s.notifyAll(events)
+	return loc, nil
+}
+
+// Location
+// Returns a single location
+func (s *Store) Location(id string) (Location, error) {
+	var ret Location
+	if err := s.loadDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		lB := tx.Bucket([]byte("locations")).Bucket([]byte(id))
+		if lB == nil {
+			return fmt.Errorf("Location not found: %s", id)
+		}
+		ret = bucketToLocation(id, lB)
+		return nil
+	})
+	s.closeDatabase()
+	return ret, err
+}
+
+// DeleteLocation
+// Remove a location, and take it off the programs offered there. Programs
+// that had it first take their details from their next location instead.
+func (s *Store) DeleteLocation(id string) error {
+	var events []Event
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("locations"))
+		lB := b.Bucket([]byte(id))
+		if lB == nil {
+			return fmt.Errorf("Location not found: %s", id)
+		}
+		loc := bucketToLocation(id, lB)
+		if err := b.DeleteBucket([]byte(id)); err != nil {
+			return err
+		}
+		var err error
+		events, err = relink(tx, func(res Resource) bool { return res.HasLocation(id) }, func(res *Resource) {
+			unlinkLocation(res, loc)
+		})
+		return err
+	})
+	s.closeDatabase()
+	if err != nil {
+		return err
+	}
+	s.notifyAll(events)
+	return nil
+}
+
+// unlinkLocation
+// Take 'loc' off of 'res', along with the details that were copied from it
+// when it was the first location
+func unlinkLocation(res *Resource, loc Location) {
+	if len(res.LocationIDs) > 0 && res.LocationIDs[0] == loc.ID {
+		res.Address = ""
+		res.Latitude, res.Longitude = 0, 0
+		if len(loc.Phones) > 0 && res.Phone == loc.Phones[0] {
+			res.Phone = ""
+		}
+		if !loc.Schedule.IsEmpty() && res.Schedule.String() == loc.Schedule.String() {
+			res.Schedule = hours.Schedule{}
+		}
+	}
+	ids := make([]string, 0, len(res.LocationIDs))
+	for _, v := range res.LocationIDs {
+		if v != loc.ID {
+			ids = append(ids, v)
+		}
+	}
+	res.LocationIDs = ids
+}
+
+// Runs
+// Returns whether 'res' is one of the organization's programs, because it's
+// linked to it or has its name and isn't linked to another
+func (org Organization) Runs(res Resource) bool {
+	if res.OrgID != "" {
+		return res.OrgID == org.ID
+	}
+	return strings.EqualFold(strings.TrimSpace(res.Org), org.Name)
+}
+
+// Programs
+// Returns the resources an organization runs
+func (s *Store) Programs(org Organization) ([]Resource, error) {
+	ret := make([]Resource, 0, 0)
+	resources, err := s.Resources()
+	for _, res := range resources {
+		if org.Runs(res) {
+			ret = append(ret, res)
+		}
+	}
+	return ret, err
+}
+
+// HasLocation
+// Returns whether the resource is offered at the location with ID 'id'
+func (res Resource) HasLocation(id string) bool {
+	return containsString(res.LocationIDs, id)
+}
+
+// link
+// Check the organization and locations of 'res', and copy in the details
+// it takes from them, see linkTo
+func (s *Store) link(res *Resource) error {
+	res.OrgID = strings.TrimSpace(res.OrgID)
+	res.LocationIDs = CleanList(res.LocationIDs)
+	if res.OrgID == "" && len(res.LocationIDs) == 0 {
+		return nil
+	}
+	var orgs map[string]Organization
+	var locs map[string]Location
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		orgs, locs = linkTables(tx)
+		return nil
+	})
+	s.closeDatabase()
+	if err != nil {
+		return err
+	}
+	return linkTo(res, orgs, locs)
+}
+
+// linkTo
+// Check the organization and locations of 'res' against 'orgs' and 'locs',
+// and copy the org name onto it, and the first location's address,
+// coordinates, and phone and hours when the location has them
+func linkTo(res *Resource, orgs map[string]Organization, locs map[string]Location) error {
+	for i, id := range res.LocationIDs {
+		loc, ok := locs[id]
+		if !ok {
+			return fmt.Errorf("Location not found: %s", id)
+		}
+		if res.OrgID == "" {
+			res.OrgID = loc.OrgID
+		}
+		if loc.OrgID != res.OrgID {
+			return fmt.Errorf("Location %s belongs to another organization", id)
+		}
+		if i == 0 {
+			res.Address = loc.Address
+			res.Latitude, res.Longitude = loc.Latitude, loc.Longitude
+			if len(loc.Phones) > 0 {
+				res.Phone = loc.Phones[0]
+			}
+			if !loc.Schedule.IsEmpty() {
+				res.Schedule = loc.Schedule
+			}
+		}
+	}
+	if res.OrgID == "" {
+		return nil
+	}
+	org, ok := orgs[res.OrgID]
+	if !ok {
+		return fmt.Errorf("Organization not found: %s", res.OrgID)
+	}
+	res.Org = org.Name
+	return nil
+}
+
+// sameLink
+// Do 'a' and 'b' have the same organization and locations, and the same
+// details copied from them
+func sameLink(a, b Resource) bool {
+	return a.OrgID == b.OrgID && a.Org == b.Org &&
+		strings.Join(a.LocationIDs, ",") == strings.Join(b.LocationIDs, ",") &&
+		a.Address == b.Address && a.Latitude == b.Latitude && a.Longitude == b.Longitude &&
+		a.Phone == b.Phone && a.Schedule.String() == b.Schedule.String()
+}
+
+// relink
+// Apply 'change' to every resource that 'match'es and copy in its
+// organization and location details again, as part of the transaction that
+// changed them. Only the resources that end up different are written, each
+// with one change log entry. Returns an EventUpdated for each of them, to be
+// sent once the transaction is done.
+func relink(tx *bolt.Tx, match func(Resource) bool, change func(*Resource)) ([]Event, error) {
+	events := make([]Event, 0, 0)
+	orgs, locs := linkTables(tx)
+	b := tx.Bucket([]byte("resources"))
+	resources := make([]Resource, 0, 0)
+	b.ForEach(func(k, v []byte) error {
+		if v == nil { // Nested Bucket
+			resources = append(resources, bucketToResource(string(k), b.Bucket(k)))
+		}
+		return nil
+	})
+	for _, res := range resources {
+		if !match(res) {
+			continue
+		}
+		prev := res
+		change(&res)
+		res.LocationIDs = CleanList(res.LocationIDs)
+		if err := linkTo(&res, orgs, locs); err != nil {
+			return nil, fmt.Errorf("%s: %s", res.Title, err)
+		}
+		if sameLink(prev, res) {
+			continue
+		}
+		if err := putResource(tx, res); err != nil {
+			return nil, err
+		}
+		events = append(events, Event{Type: EventUpdated, Resource: res})
+	}
+	return events, nil
+}
+
+// linkTables
+// Returns every organization and every location, by ID
+func linkTables(tx *bolt.Tx) (map[string]Organization, map[string]Location) {
+	orgs := make(map[string]Organization)
+	locs := make(map[string]Location)
+	b := tx.Bucket([]byte("orgs"))
+	b.ForEach(func(k, v []byte) error {
+		if v == nil { // Nested Bucket
+			orgs[string(k)] = bucketToOrganization(string(k), b.Bucket(k))
+		}
+		return nil
+	})
+	for _, list := range orgLocations(tx) {
+		for _, loc := range list {
+			locs[loc.ID] = loc
+		}
+	}
+	return orgs, locs
+}
+
+// orgLocations
+// Returns every location, by organization ID, in the order they were added
+func orgLocations(tx *bolt.Tx) map[string][]Location {
+	ret := make(map[string][]Location)
+	b := tx.Bucket([]byte("locations"))
+	b.ForEach(func(k, v []byte) error {
+		if v == nil { // Nested Bucket
+			loc := bucketToLocation(string(k), b.Bucket(k))
+			ret[loc.OrgID] = append(ret[loc.OrgID], loc)
+		}
+		return nil
+	})
+	for _, locs := range ret {
+		sort.SliceStable(locs, func(i, j int) bool {
+			ni, _ := strconv.Atoi(locs[i].ID)
+			nj, _ := strconv.Atoi(locs[j].ID)
+			return ni < nj
+		})
+	}
+	return ret
+}
+
+func bucketToOrganization(id string, oB *bolt.Bucket) Organization {
+	ret := Organization{ID: id, Locations: make([]Location, 0, 0)}
+	ret.Name = string(oB.Get([]byte("name")))
+	ret.Description = string(oB.Get([]byte("description")))
+	ret.Website = string(oB.Get([]byte("website")))
+	ret.ContactName = string(oB.Get([]byte("contact_name")))
+	ret.ContactEmail = string(oB.Get([]byte("contact_email")))
+	ret.ContactPhone = string(oB.Get([]byte("contact_phone")))
+	ret.Logo = string(oB.Get([]byte("logo")))
+	return ret
+}
+
+func bucketToLocation(id string, lB *bolt.Bucket) Location {
+	ret := Location{ID: id}
+	ret.OrgID = string(lB.Get([]byte("org")))
+	ret.Name = string(lB.Get([]byte("name")))
+	ret.Address = string(lB.Get([]byte("address")))
+	ret.Schedule, _ = hours.Parse(string(lB.Get([]byte("schedule"))))
+	if rVal := lB.Get([]byte("phones")); len(rVal) > 0 {
+		ret.Phones = strings.Split(string(rVal), "\n")
+	}
+	ret.Latitude, _ = strconv.ParseFloat(string(lB.Get([]byte("latitude"))), 64)
+	ret.Longitude, _ = strconv.ParseFloat(string(lB.Get([]byte("longitude"))), 64)
+	return ret
+}
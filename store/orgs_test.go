@@ -0,0 +1,203 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/openwichita/infant-info/hours"
+)
+
+func mustSchedule(t *testing.T, text string) hours.Schedule {
+	t.Helper()
+	sch, err := hours.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch
+}
+
+// orgFixture
+// An organization with two locations, offering "Both" at the two of them,
+// "Second" at the second one and "Linked" with no location
+type orgFixture struct {
+	Org         Organization
+	First, Next Location
+}
+
+func newOrgFixture(t *testing.T, s *Store) orgFixture {
+	t.Helper()
+	var f orgFixture
+	var err error
+	if f.Org, err = s.SaveOrganization(Organization{Name: "Health Department"}); err != nil {
+		t.Fatal(err)
+	}
+	if f.First, err = s.SaveLocation(Location{
+		OrgID: f.Org.ID, Address: "1900 E 9th St N", Phones: []string{"316-555-0100"},
+		Schedule: mustSchedule(t, "Mon-Fri 8am-5pm"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Next, err = s.SaveLocation(Location{
+		OrgID: f.Org.ID, Address: "2716 W Central Ave", Phones: []string{"316-555-0200"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, res := range []Resource{
+		{Title: "Both", LocationIDs: []string{f.First.ID, f.Next.ID}},
+		{Title: "Second", LocationIDs: []string{f.Next.ID}},
+		{Title: "Linked", OrgID: f.Org.ID},
+		{Title: "Unlinked", Org: "Health Department"},
+	} {
+		res.URL = "https://example.org"
+		if err := s.Save("", res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f
+}
+
+func TestLinkCopiesDetails(t *testing.T) {
+	s, _ := newTestStore(t)
+	f := newOrgFixture(t, s)
+	res := mustResource(t, s, "Both")
+	if res.OrgID != f.Org.ID || res.Org != "Health Department" {
+		t.Errorf("Linked to %s (%s)", res.OrgID, res.Org)
+	}
+	if res.Address != f.First.Address || res.Phone != "316-555-0100" || res.Schedule.String() != "Mon-Fri 8am-5pm" {
+		t.Errorf("Copied %q, %q and %q", res.Address, res.Phone, res.Schedule)
+	}
+	if err := s.Save("Both", Resource{Title: "Both", URL: "https://example.org", LocationIDs: []string{"99"}}); err == nil {
+		t.Error("Saved a resource at a location that isn't there")
+	}
+}
+
+func TestRenameOrganization(t *testing.T) {
+	s, events := newTestStore(t)
+	f := newOrgFixture(t, s)
+
+	from := len(*events)
+	f.Org.Name = "Sedgwick County Health Department"
+	if _, err := s.SaveOrganization(f.Org); err != nil {
+		t.Fatal(err)
+	}
+	if got := eventTitles(*events, from); len(got) != 3 {
+		t.Errorf("Renaming sent events for %v, wanted the 3 linked resources", got)
+	}
+	if res := mustResource(t, s, "Second"); res.Org != f.Org.Name {
+		t.Errorf("Org is %q after the rename", res.Org)
+	}
+	if res := mustResource(t, s, "Unlinked"); res.Org != "Health Department" {
+		t.Errorf("An unlinked resource was renamed to %q", res.Org)
+	}
+
+	// Nothing that's copied changed
+	from = len(*events)
+	f.Org.Description = "Clinics and home visits"
+	if _, err := s.SaveOrganization(f.Org); err != nil {
+		t.Fatal(err)
+	}
+	if got := eventTitles(*events, from); len(got) != 0 {
+		t.Errorf("Saving the org again sent events for %v", got)
+	}
+}
+
+func TestSaveLocation(t *testing.T) {
+	s, events := newTestStore(t)
+	f := newOrgFixture(t, s)
+
+	from := len(*events)
+	f.First.Phones = nil
+	f.First.Schedule = hours.Schedule{}
+	f.First.Address = "1900 E 9th St N, Wichita, KS"
+	if _, err := s.SaveLocation(f.First); err != nil {
+		t.Fatal(err)
+	}
+	if got := eventTitles(*events, from); len(got) != 1 || got[0] != "Both" {
+		t.Errorf("Saving the location sent events for %v, wanted Both", got)
+	}
+	res := mustResource(t, s, "Both")
+	if res.Address != f.First.Address || res.Phone != "" || !res.Schedule.IsEmpty() {
+		t.Errorf("Both has %q, %q and %q", res.Address, res.Phone, res.Schedule)
+	}
+}
+
+func TestDeleteLocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		delete    func(orgFixture) string
+		title     string
+		locations int
+		address   string
+		phone     string
+		schedule  string
+	}{
+		{"the first", func(f orgFixture) string { return f.First.ID }, "Both", 1, "2716 W Central Ave", "316-555-0200", ""},
+		{"the second", func(f orgFixture) string { return f.Next.ID }, "Both", 1, "1900 E 9th St N", "316-555-0100", "Mon-Fri 8am-5pm"},
+		{"the only", func(f orgFixture) string { return f.Next.ID }, "Second", 0, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, events := newTestStore(t)
+			f := newOrgFixture(t, s)
+			from := len(*events)
+			if err := s.DeleteLocation(tt.delete(f)); err != nil {
+				t.Fatal(err)
+			}
+			res := mustResource(t, s, tt.title)
+			if len(res.LocationIDs) != tt.locations || res.OrgID != f.Org.ID {
+				t.Errorf("%s is at %v of %s", tt.title, res.LocationIDs, res.OrgID)
+			}
+			if res.Address != tt.address || res.Phone != tt.phone || res.Schedule.String() != tt.schedule {
+				t.Errorf("%s has %q, %q and %q", tt.title, res.Address, res.Phone, res.Schedule)
+			}
+			if got := eventTitles(*events, from); len(got) == 0 || len(got) > 2 {
+				t.Errorf("Deleting sent events for %v", got)
+			}
+		})
+	}
+
+	s, _ := newTestStore(t)
+	if err := s.DeleteLocation("1"); err == nil {
+		t.Error("Deleted a location that isn't there")
+	}
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	s, events := newTestStore(t)
+	f := newOrgFixture(t, s)
+	from := len(*events)
+	if err := s.DeleteOrganization(f.Org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := eventTitles(*events, from); len(got) != 3 {
+		t.Errorf("Deleting sent events for %v, wanted the 3 linked resources", got)
+	}
+	res := mustResource(t, s, "Both")
+	if res.OrgID != "" || len(res.LocationIDs) != 0 || res.Org != "Health Department" {
+		t.Errorf("Both is still linked to %s at %v (%s)", res.OrgID, res.LocationIDs, res.Org)
+	}
+	if _, err := s.Location(f.First.ID); err == nil {
+		t.Error("The org's locations are still there")
+	}
+}
+
+func TestMergeOrganization(t *testing.T) {
+	s, _ := newTestStore(t)
+	f := newOrgFixture(t, s)
+	into, err := s.SaveOrganization(Organization{Name: "Sedgwick County"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.MergeOrganization(f.Org.ID, into.ID); err != nil {
+		t.Fatal(err)
+	}
+	res := mustResource(t, s, "Both")
+	if res.OrgID != into.ID || res.Org != into.Name || len(res.LocationIDs) != 2 {
+		t.Errorf("Both is linked to %s (%s) at %v", res.OrgID, res.Org, res.LocationIDs)
+	}
+	if loc, err := s.Location(f.First.ID); err != nil || loc.OrgID != into.ID {
+		t.Errorf("The location belongs to %s (%v)", loc.OrgID, err)
+	}
+	if err := s.MergeOrganization(into.ID, into.ID); err == nil {
+		t.Error("Merged an organization into itself")
+	}
+}
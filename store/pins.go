@@ -0,0 +1,203 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// Pin puts a resource at the top of the results for a search query or a
+// tag, or gives it a boost in the ranking
+type Pin struct {
+	ID       string
+	Resource string  // Title of the resource
+	Query    string  // Lower case words, like "safe sleep"
+	Tag      string  // Set instead of Query to pin to a tag
+	Pinned   bool    // Shown before everything else
+	Boost    float64 // Added to the ranking, a title match is worth about 3
+	Expires  time.Time
+	Created  time.Time
+}
+
+// IsActive
+// Has the pin not expired yet
+func (p Pin) IsActive(now time.Time) bool {
+	return p.Expires.IsZero() || now.Before(p.Expires)
+}
+
+// Pin Model Functions
+// Pins are stored in the admin boltdb like so
+// pins		(bucket)
+// \- <id> (bucket) (a sequence number)
+//   |-resource	(pair)
+//   |-query		(pair)
+//   |-tag		(pair)
+//   |-pinned		(pair) (true/false)
+//   |-boost		(pair)
+//   |-expires	(pair) (RFC3339, blank for never)
+//   \-created	(pair) (RFC3339)
+
+// ValidatePin
+// Make sure that a pin says what it is for and does something
+func ValidatePin(p Pin) error {
+	if p.Resource == "" {
+		return fmt.Errorf("Pin resource is required")
+	}
+	if (p.Query == "") == (p.Tag == "") {
+		return fmt.Errorf("Pin needs either a query or a tag")
+	}
+	if !p.Pinned && p.Boost == 0 {
+		return fmt.Errorf("Pin needs to be pinned or have a boost")
+	}
+	return nil
+}
+
+// SavePin
+// Save a pin, a new ID is given to it if it doesn't have one yet
+func (s *AdminStore) SavePin(p Pin) (Pin, error) {
+	p.Resource = strings.TrimSpace(p.Resource)
+	p.Query = strings.TrimSpace(p.Query)
+	p.Tag = strings.TrimSpace(p.Tag)
+	if err := ValidatePin(p); err != nil {
+		return p, err
+	}
+	if p.Created.IsZero() {
+		p.Created = time.Now()
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return p, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("pins"))
+		if p.ID == "" {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			p.ID = strconv.FormatUint(seq, 10)
+		}
+		return putPin(b, p)
+	})
+	s.closeAdminDatabase()
+	return p, err
+}
+
+func putPin(b *bolt.Bucket, p Pin) error {
+	pB, err := b.CreateBucketIfNotExists([]byte(p.ID))
+	if err != nil {
+		return err
+	}
+	expires := ""
+	if !p.Expires.IsZero() {
+		expires = p.Expires.Format(time.RFC3339)
+	}
+	for k, v := range map[string]string{
+		"resource": p.Resource,
+		"query":    p.Query,
+		"tag":      p.Tag,
+		"pinned":   strconv.FormatBool(p.Pinned),
+		"boost":    strconv.FormatFloat(p.Boost, 'f', -1, 64),
+		"expires":  expires,
+		"created":  p.Created.Format(time.RFC3339),
+	} {
+		if err := pB.Put([]byte(k), []byte(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pins
+// Returns every pin, ordered by query or tag and then by when it was made
+func (s *AdminStore) Pins() ([]Pin, error) {
+	ret := make([]Pin, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("pins"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToPin(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	sort.Slice(ret, func(i, j int) bool {
+		if ki, kj := ret[i].Query+"\x00"+ret[i].Tag, ret[j].Query+"\x00"+ret[j].Tag; ki != kj {
+			return ki < kj
+		}
+		return ret[i].Created.Before(ret[j].Created)
+	})
+	return ret, err
+}
+
+// Pin
+// Returns a single pin
+func (s *AdminStore) Pin(id string) (Pin, error) {
+	var ret Pin
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		pB := tx.Bucket([]byte("pins")).Bucket([]byte(id))
+		if pB == nil {
+			return fmt.Errorf("Pin not found: %s", id)
+		}
+		ret = bucketToPin(id, pB)
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// DeletePin
+// Remove a pin
+func (s *AdminStore) DeletePin(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("pins")).DeleteBucket([]byte(id))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// RenamePins
+// Point the pins for resource 'from' at 'to', for when a resource is renamed
+func (s *AdminStore) RenamePins(from, to string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("pins"))
+		return b.ForEach(func(k, v []byte) error {
+			if pB := b.Bucket(k); v == nil && pB != nil && string(pB.Get([]byte("resource"))) == from {
+				return pB.Put([]byte("resource"), []byte(to))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+func bucketToPin(id string, pB *bolt.Bucket) Pin {
+	ret := Pin{ID: id}
+	ret.Resource = string(pB.Get([]byte("resource")))
+	ret.Query = string(pB.Get([]byte("query")))
+	ret.Tag = string(pB.Get([]byte("tag")))
+	ret.Pinned, _ = strconv.ParseBool(string(pB.Get([]byte("pinned"))))
+	ret.Boost, _ = strconv.ParseFloat(string(pB.Get([]byte("boost"))), 64)
+	if rVal := pB.Get([]byte("expires")); len(rVal) > 0 {
+		ret.Expires, _ = time.Parse(time.RFC3339, string(rVal))
+	}
+	ret.Created, _ = time.Parse(time.RFC3339, string(pB.Get([]byte("created"))))
+	return ret
+}
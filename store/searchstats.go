@@ -0,0 +1,211 @@
+package store
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// StatsDayFormat is how the days in the search statistics are written
+const StatsDayFormat = "2006-01-02"
+
+// How long search statistics are kept
+const statsKeepDays = 400
+
+// QueryStat is how a search query did
+type QueryStat struct {
+	Query       string
+	Searches    int
+	ZeroResults int // Searches that found nothing
+	Clicks      int // Results that were followed from the search
+}
+
+// ClickStat is how often a resource was followed from the search or browse results
+type ClickStat struct {
+	Title  string
+	Clicks int
+}
+
+// DayStats are the search statistics for a single day
+type DayStats struct {
+	Day     string
+	Queries []QueryStat
+	Clicks  []ClickStat
+}
+
+// Search Statistics
+// Only counts are kept, added up for each day. Nothing about who searched
+// (addresses, sessions or times of day) is ever stored.
+// searchstats		(bucket)
+// \- <YYYY-MM-DD> (bucket)
+//   |- queries	(bucket)
+//   | \- <query> (bucket)
+//   |   |-searches	(pair)
+//   |   |-zero		(pair)
+//   |   \-clicks		(pair)
+//   \- clicks	(bucket)
+//     \- <title>	(pair) (count)
+
+// AddSearchStats
+// Add the counts in 'day' to what is stored for that day, and drop any
+// days that are too old to keep
+func (s *AdminStore) AddSearchStats(day DayStats) error {
+	if len(day.Queries) == 0 && len(day.Clicks) == 0 {
+		return nil
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("searchstats"))
+		dB, err := b.CreateBucketIfNotExists([]byte(day.Day))
+		if err != nil {
+			return err
+		}
+		qB, err := dB.CreateBucketIfNotExists([]byte("queries"))
+		if err != nil {
+			return err
+		}
+		for _, q := range day.Queries {
+			sB, err := qB.CreateBucketIfNotExists([]byte(q.Query))
+			if err != nil {
+				return err
+			}
+			for k, v := range map[string]int{
+				"searches": q.Searches,
+				"zero":     q.ZeroResults,
+				"clicks":   q.Clicks,
+			} {
+				if err := addCount(sB, k, v); err != nil {
+					return err
+				}
+			}
+		}
+		cB, err := dB.CreateBucketIfNotExists([]byte("clicks"))
+		if err != nil {
+			return err
+		}
+		for _, c := range day.Clicks {
+			if err := addCount(cB, c.Title, c.Clicks); err != nil {
+				return err
+			}
+		}
+
+		// Prune
+		oldest := []byte(time.Now().AddDate(0, 0, -statsKeepDays).Format(StatsDayFormat))
+		old := make([][]byte, 0, 0)
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && string(k) < string(oldest); k, _ = c.Next() {
+			old = append(old, k)
+		}
+		for i := range old {
+			if err := b.DeleteBucket(old[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// addCount
+// Add 'n' to the count stored at 'key'
+func addCount(b *bolt.Bucket, key string, n int) error {
+	if n == 0 {
+		return nil
+	}
+	cur, _ := strconv.Atoi(string(b.Get([]byte(key))))
+	return b.Put([]byte(key), []byte(strconv.Itoa(cur+n)))
+}
+
+// SearchStats
+// Returns the statistics for each day from 'from' to 'to' (inclusive, as
+// YYYY-MM-DD), oldest first
+func (s *AdminStore) SearchStats(from, to string) ([]DayStats, error) {
+	ret := make([]DayStats, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("searchstats"))
+		c := b.Cursor()
+		for k, v := c.Seek([]byte(from)); k != nil && string(k) <= to; k, v = c.Next() {
+			if v != nil {
+				continue
+			}
+			dB := b.Bucket(k)
+			day := DayStats{Day: string(k)}
+			if qB := dB.Bucket([]byte("queries")); qB != nil {
+				qB.ForEach(func(qk, qv []byte) error {
+					if sB := qB.Bucket(qk); qv == nil && sB != nil {
+						q := QueryStat{Query: string(qk)}
+						q.Searches, _ = strconv.Atoi(string(sB.Get([]byte("searches"))))
+						q.ZeroResults, _ = strconv.Atoi(string(sB.Get([]byte("zero"))))
+						q.Clicks, _ = strconv.Atoi(string(sB.Get([]byte("clicks"))))
+						day.Queries = append(day.Queries, q)
+					}
+					return nil
+				})
+			}
+			if cB := dB.Bucket([]byte("clicks")); cB != nil {
+				cB.ForEach(func(ck, cv []byte) error {
+					n, _ := strconv.Atoi(string(cv))
+					day.Clicks = append(day.Clicks, ClickStat{Title: string(ck), Clicks: n})
+					return nil
+				})
+			}
+			ret = append(ret, day)
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// SumSearchStats
+// Add up the statistics for several days. Queries are sorted by how often
+// they were searched for and resources by how often they were followed.
+func SumSearchStats(days []DayStats) ([]QueryStat, []ClickStat) {
+	qIdx := make(map[string]int)
+	queries := make([]QueryStat, 0, 0)
+	cIdx := make(map[string]int)
+	clicks := make([]ClickStat, 0, 0)
+	for _, day := range days {
+		for _, q := range day.Queries {
+			i, ok := qIdx[q.Query]
+			if !ok {
+				i = len(queries)
+				qIdx[q.Query] = i
+				queries = append(queries, QueryStat{Query: q.Query})
+			}
+			queries[i].Searches += q.Searches
+			queries[i].ZeroResults += q.ZeroResults
+			queries[i].Clicks += q.Clicks
+		}
+		for _, c := range day.Clicks {
+			i, ok := cIdx[c.Title]
+			if !ok {
+				i = len(clicks)
+				cIdx[c.Title] = i
+				clicks = append(clicks, ClickStat{Title: c.Title})
+			}
+			clicks[i].Clicks += c.Clicks
+		}
+	}
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Searches != queries[j].Searches {
+			return queries[i].Searches > queries[j].Searches
+		}
+		return queries[i].Query < queries[j].Query
+	})
+	sort.Slice(clicks, func(i, j int) bool {
+		if clicks[i].Clicks != clicks[j].Clicks {
+			return clicks[i].Clicks > clicks[j].Clicks
+		}
+		return clicks[i].Title < clicks[j].Title
+	})
+	return queries, clicks
+}
@@ -0,0 +1,646 @@
+// Package store keeps the resource directory and its admin data in boltdb.
+//
+// A Store holds the resources and the organizations that run them (ii.db),
+// an AdminStore holds the admin users, API tokens, API keys and webhooks
+// (iiAdmin.db). Both open their database file for each call and close it
+// again afterwards, so the files can be copied or backed up while the server
+// is running.
+package store
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/boltdb/bolt"
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/hours"
+)
+
+// Resource is a single entry in the directory
+type Resource struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Org         string   `json:"org"` // Copied from the organization when OrgID is set
+	Address     string   `json:"address"`
+	Email       string   `json:"email"`
+	Phone       string   `json:"phone"`
+	Hours       string   `json:"hours"` // As written by the resource
+	Fees        []string `json:"fees"`  // Free-form, see Cost
+	Languages   []string `json:"languages"`
+	Tags        []string `json:"tags"`
+	// Where the resource serves, like "county:Sedgwick", see geo.ServiceArea
+	// None means anywhere, or that nobody has said.
+	ServiceAreas []string `json:"service_areas"`
+	// When it's open, for "open now", see hours.Schedule
+	Schedule hours.Schedule `json:"schedule"`
+	// What it costs and how families can pay
+	Cost Cost `json:"cost"`
+	// Who it's for
+	Eligibility Eligibility `json:"eligibility"`
+	// The Organization that runs it, and the Locations it's offered at. The
+	// address and coordinates are the first location's, when there are any.
+	OrgID       string   `json:"org_id,omitempty"`
+	LocationIDs []string `json:"location_ids,omitempty"`
+	Latitude    float64  `json:"latitude,omitempty"`
+	Longitude   float64  `json:"longitude,omitempty"`
+}
+
+// Resource Events
+// Sent to the OnChange listeners whenever a resource is saved or deleted
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+	EventPing    = "ping"
+)
+
+// Event describes a change to a resource
+type Event struct {
+	Type          string
+	Resource      Resource
+	PreviousTitle string // Set when an update renamed the resource
+}
+
+// Store is the resource database
+type Store struct {
+	path string
+
+	mu        sync.Mutex // Held while the db is open
+	db        *bolt.DB
+	listeners []func(Event)
+	geocoder  Geocoder
+}
+
+// Geocoder works out the coordinates of an address, see geo.Gazetteer
+type Geocoder interface {
+	Geocode(address string) (lat, lng float64, ok bool)
+}
+
+// Open
+// Returns a Store for the database file at 'path', creating it if needed
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.loadDatabase(); err != nil {
+		return nil, err
+	}
+	s.closeDatabase()
+	return s, nil
+}
+
+// OnChange
+// Register a function to be called after every save or delete
+func (s *Store) OnChange(fn func(Event)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// SetGeocoder
+// Use 'g' to fill in the coordinates of resources as they are saved
+func (s *Store) SetGeocoder(g Geocoder) {
+	s.geocoder = g
+}
+
+func (s *Store) notify(ev Event) {
+	for _, fn := range s.listeners {
+		fn(ev)
+	}
+}
+
+// notifyAll
+// Let the listeners know about each of 'events', in order
+func (s *Store) notifyAll(events []Event) {
+	for _, ev := range events {
+		s.notify(ev)
+	}
+}
+
+// loadDatabase Opens the database file and makes sure that the
+// initial 'resources', 'orgs', 'locations' and 'changes' buckets exist
+func (s *Store) loadDatabase() error {
+	s.mu.Lock()
+	var err error
+	s.db, err = bolt.Open(s.path, 0600, nil)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	// Make sure that the 'resources' bucket exists
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		for _, bkt := range []string{"resources", "orgs", "locations"} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bkt)); err != nil {
+				return err
+			}
+		}
+		return initChanges(tx)
+	})
+
+	if err != nil {
+		s.closeDatabase()
+		return err
+	}
+	return nil
+}
+
+func (s *Store) closeDatabase() error {
+	defer s.mu.Unlock()
+	return s.db.Close()
+}
+
+// All resources are saved in the boltdb like so:
+// Likely there will be changes here when we actually get resources
+// resources			(bucket)
+// |- Title 1	(bucket)
+// | |-description	(pair)
+// | |-url			(pair)
+// | |-org			(pair)
+// | |-address		(pair)
+// | |-email		(pair)
+// | |-phone		(pair)
+// | |-hours		(pair)
+// | |-fees			(pair) (csv)
+// | |-languages	(pair) (csv)
+// | |-tags			(pair) (csv)
+// | |-areas		(pair) (csv)
+// | |-schedule	(pair) (one rule to a line, see hours.Parse)
+// | |-cost		(pair) (csv of Cost Options)
+// | |-flat_fee		(pair)
+// | |-cost_notes	(pair)
+// | |-parent_age	(pair) (like 0-19, see ParseAgeRange)
+// | |-child_age	(pair) (months)
+// | |-pregnancy	(pair) (csv of Pregnancy Stages)
+// | |-income_fpl	(pair)
+// | |-residency	(pair) (csv)
+// | |-insurance	(pair) (csv of Insurance Statuses)
+// | |-eligibility_notes	(pair)
+// | |-org_id		(pair) (see Organization)
+// | |-locations	(pair) (csv of Location IDs)
+// | |-latitude		(pair)
+// | \-longitude	(pair)
+// |
+// \- Title 2	(bucket)
+//   |-description	(pair)
+//   |-url			(pair)
+//   |-org			(pair)
+//   |-address		(pair)
+//   |-email		(pair)
+//   |-phone		(pair)
+//   |-hours		(pair)
+//   |-fees			(pair) (csv)
+//   |-languages	(pair) (csv)
+//   |-tags			(pair) (csv)
+//   |-areas		(pair) (csv)
+//   |-schedule	(pair) (one rule to a line, see hours.Parse)
+//   |-cost		(pair) (csv of Cost Options)
+//   |-flat_fee		(pair)
+//   |-cost_notes	(pair)
+//   |-parent_age	(pair) (like 0-19, see ParseAgeRange)
+//   |-child_age	(pair) (months)
+//   |-pregnancy	(pair) (csv of Pregnancy Stages)
+//   |-income_fpl	(pair)
+//   |-residency	(pair) (csv)
+//   |-insurance	(pair) (csv of Insurance Statuses)
+//   |-eligibility_notes	(pair)
+//   |-org_id		(pair) (see Organization)
+//   |-locations	(pair) (csv of Location IDs)
+//   |-latitude		(pair)
+//   \-longitude	(pair)
+
+// ValidateResource
+// Make sure that a resource has everything it needs to be saved
+func ValidateResource(res Resource) error {
+	if res.Title == "" {
+		return fmt.Errorf("Resource title is required")
+	}
+	if res.URL == "" {
+		return fmt.Errorf("Resource URL is required")
+	}
+	for _, a := range res.ServiceAreas {
+		if _, err := geo.ParseServiceArea(a); err != nil {
+			return err
+		}
+	}
+	if _, err := res.Schedule.Normalize(); err != nil {
+		return err
+	}
+	if err := ValidateCost(res.Cost); err != nil {
+		return err
+	}
+	if err := ValidateEligibility(res.Eligibility); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Save
+// Validate and save a resource, replacing the resource that was stored
+// under origTitle (if any). Both the admin form and the API save through here.
+func (s *Store) Save(origTitle string, res Resource) error {
+	res.Fees = CleanList(res.Fees)
+	res.Languages = CleanList(res.Languages)
+	res.Tags = CleanList(res.Tags)
+	res.ServiceAreas = CleanList(res.ServiceAreas)
+	res.Cost.FlatFee = strings.TrimSpace(res.Cost.FlatFee)
+	res.Cost.Notes = strings.TrimSpace(res.Cost.Notes)
+	res.Eligibility.Pregnancy = CleanList(res.Eligibility.Pregnancy)
+	res.Eligibility.Residency = CleanList(res.Eligibility.Residency)
+	res.Eligibility.Insurance = CleanList(res.Eligibility.Insurance)
+	if err := ValidateResource(res); err != nil {
+		return err
+	}
+	if err := s.link(&res); err != nil {
+		return err
+	}
+	for i := range res.ServiceAreas {
+		a, _ := geo.ParseServiceArea(res.ServiceAreas[i])
+		res.ServiceAreas[i] = a.String()
+	}
+	res.Schedule, _ = res.Schedule.Normalize()
+	res.Eligibility = res.Eligibility.normalize()
+	s.locate(origTitle, &res)
+	if err := s.writeResource(origTitle, res); err != nil {
+		return err
+	}
+	if origTitle == "" {
+		s.notify(Event{Type: EventCreated, Resource: res})
+	} else {
+		ev := Event{Type: EventUpdated, Resource: res}
+		if origTitle != res.Title {
+			ev.PreviousTitle = origTitle
+		}
+		s.notify(ev)
+	}
+	return nil
+}
+
+// Delete
+// Delete a resource and let anyone listening know
+func (s *Store) Delete(title string) error {
+	if err := s.deleteResource(title); err != nil {
+		return err
+	}
+	s.notify(Event{Type: EventDeleted, Resource: Resource{Title: title}})
+	return nil
+}
+
+// locate
+// Fill in the coordinates of 'res' from its address when it doesn't have
+// any, or when the address changed and the coordinates didn't
+func (s *Store) locate(origTitle string, res *Resource) {
+	if s.geocoder == nil {
+		return
+	}
+	var prev Resource
+	if origTitle != "" {
+		prev, _ = s.Resource(origTitle)
+	}
+	moved := prev.Address != res.Address && prev.Latitude == res.Latitude && prev.Longitude == res.Longitude
+	if !moved && (res.Latitude != 0 || res.Longitude != 0) {
+		return
+	}
+	lat, lng, ok := s.geocoder.Geocode(res.Address)
+	if !ok && !moved {
+		return
+	}
+	res.Latitude, res.Longitude = lat, lng
+}
+
+// GeocodeMissing
+// Fill in the coordinates of every resource that has an address but no
+// coordinates, returning how many were found. This runs at startup, so
+// only the coordinates are written: listeners aren't told and nothing goes
+// in the change log, or every sync client would fetch the whole directory
+// again after a restart. They get the coordinates with the next real change.
+func (s *Store) GeocodeMissing() (int, error) {
+	if s.geocoder == nil {
+		return 0, nil
+	}
+	if err := s.loadDatabase(); err != nil {
+		return 0, err
+	}
+	found := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("resources"))
+		return b.ForEach(func(k, v []byte) error {
+			if v != nil {
+				return nil
+			}
+			rB := b.Bucket(k)
+			res := bucketToResource(string(k), rB)
+			if res.Address == "" || res.Latitude != 0 || res.Longitude != 0 {
+				return nil
+			}
+			lat, lng, ok := s.geocoder.Geocode(res.Address)
+			if !ok {
+				return nil
+			}
+			if err := rB.Put([]byte("latitude"), []byte(strconv.FormatFloat(lat, 'f', -1, 64))); err != nil {
+				return err
+			}
+			if err := rB.Put([]byte("longitude"), []byte(strconv.FormatFloat(lng, 'f', -1, 64))); err != nil {
+				return err
+			}
+			found++
+			return nil
+		})
+	})
+	s.closeDatabase()
+	if err != nil {
+		return 0, err
+	}
+	return found, nil
+}
+
+// CleanList
+// Trim the values in a list and drop any that are blank
+func CleanList(list []string) []string {
+	ret := make([]string, 0, 0)
+	for _, v := range list {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// ResourceExistsError is returned when a resource is created, or renamed, with
+// a title that another resource already has
+type ResourceExistsError struct {
+	Title string
+}
+
+func (e ResourceExistsError) Error() string {
+	return fmt.Sprintf("Resource already exists: %s", e.Title)
+}
+
+// writeResource
+// Replace the resource stored under origTitle (if any) with 'res', in one
+// transaction so a failed write can't lose it. Only a rename logs a delete.
+// Creating a resource never replaces one that's already there.
+func (s *Store) writeResource(origTitle string, res Resource) error {
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("resources"))
+		if origTitle == "" {
+			if _, err := b.CreateBucket([]byte(res.Title)); err != nil {
+				if err == bolt.ErrBucketExists {
+					return ResourceExistsError{res.Title}
+				}
+				return err
+			}
+			return putResource(tx, res)
+		}
+		renamed := origTitle != res.Title
+		if renamed && b.Bucket([]byte(res.Title)) != nil {
+			return ResourceExistsError{res.Title}
+		}
+		if err := b.DeleteBucket([]byte(origTitle)); err != nil {
+			if err == bolt.ErrBucketNotFound {
+				return fmt.Errorf("Resource not found: %s", origTitle)
+			}
+			return err
+		}
+		if renamed {
+			if err := recordChange(tx, ChangeDelete, origTitle); err != nil {
+				return err
+			}
+		}
+		return putResource(tx, res)
+	})
+	s.closeDatabase()
+	return err
+}
+
+// putResource
+// Write 'res' to its bucket and log the change
+func putResource(tx *bolt.Tx, res Resource) error {
+	b := tx.Bucket([]byte("resources"))
+	var newB *bolt.Bucket
+	var err error
+	if newB, err = b.CreateBucketIfNotExists([]byte(res.Title)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("description"), []byte(res.Description)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("url"), []byte(res.URL)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("org"), []byte(res.Org)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("address"), []byte(res.Address)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("email"), []byte(res.Email)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("phone"), []byte(res.Phone)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("hours"), []byte(res.Hours)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("fees"), []byte(strings.Join(res.Fees, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("languages"), []byte(strings.Join(res.Languages, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("tags"), []byte(strings.Join(res.Tags, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("areas"), []byte(strings.Join(res.ServiceAreas, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("schedule"), []byte(res.Schedule.String())); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("cost"), []byte(strings.Join(res.Cost.Options(), ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("flat_fee"), []byte(res.Cost.FlatFee)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("cost_notes"), []byte(res.Cost.Notes)); err != nil {
+		return err
+	}
+	elig := res.Eligibility
+	if err := newB.Put([]byte("parent_age"), []byte(elig.ParentAge.String())); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("child_age"), []byte(elig.ChildAge.String())); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("pregnancy"), []byte(strings.Join(elig.Pregnancy, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("income_fpl"), []byte(strconv.Itoa(elig.IncomeFPL))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("residency"), []byte(strings.Join(elig.Residency, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("insurance"), []byte(strings.Join(elig.Insurance, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("eligibility_notes"), []byte(elig.Notes)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("org_id"), []byte(res.OrgID)); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("locations"), []byte(strings.Join(res.LocationIDs, ","))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("latitude"), []byte(strconv.FormatFloat(res.Latitude, 'f', -1, 64))); err != nil {
+		return err
+	}
+	if err := newB.Put([]byte("longitude"), []byte(strconv.FormatFloat(res.Longitude, 'f', -1, 64))); err != nil {
+		return err
+	}
+	return recordChange(tx, ChangeUpsert, res.Title)
+}
+
+// Resources
+// Returns every resource, ordered by title
+func (s *Store) Resources() ([]Resource, error) {
+	ret := make([]Resource, 0, 0)
+	if err := s.loadDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("resources"))
+		err := b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				// Nested Bucket
+				ret = append(ret, bucketToResource(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	s.closeDatabase()
+	return ret, err
+}
+
+// Resource
+// Returns a single resource, or an error if there isn't one by that title
+func (s *Store) Resource(title string) (Resource, error) {
+	var ret Resource
+	if err := s.loadDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("resources"))
+		rB := b.Bucket([]byte(title))
+		if rB == nil {
+			return fmt.Errorf("Resource not found: %s", title)
+		}
+		ret = bucketToResource(title, rB)
+		return nil
+	})
+	s.closeDatabase()
+	return ret, err
+}
+
+// bucketToResource
+// Build a resource from its bucket in the 'resources' bucket
+func bucketToResource(title string, rB *bolt.Bucket) Resource {
+	var ret Resource
+	ret.Title = title
+	if rVal := rB.Get([]byte("tags")); len(rVal) > 0 {
+		ret.Tags = strings.Split(string(rVal), ",")
+	}
+	if rVal := rB.Get([]byte("languages")); len(rVal) > 0 {
+		ret.Languages = strings.Split(string(rVal), ",")
+	}
+	if rVal := rB.Get([]byte("fees")); len(rVal) > 0 {
+		ret.Fees = strings.Split(string(rVal), ",")
+	}
+	if rVal := rB.Get([]byte("hours")); rVal != nil {
+		ret.Hours = string(rVal)
+	}
+	if rVal := rB.Get([]byte("phone")); rVal != nil {
+		ret.Phone = string(rVal)
+	}
+	if rVal := rB.Get([]byte("email")); rVal != nil {
+		ret.Email = string(rVal)
+	}
+	if rVal := rB.Get([]byte("address")); rVal != nil {
+		ret.Address = string(rVal)
+	}
+	if rVal := rB.Get([]byte("org")); rVal != nil {
+		ret.Org = string(rVal)
+	}
+	if rVal := rB.Get([]byte("url")); rVal != nil {
+		ret.URL = string(rVal)
+	}
+	if rVal := rB.Get([]byte("description")); rVal != nil {
+		ret.Description = string(rVal)
+	}
+	if rVal := rB.Get([]byte("areas")); len(rVal) > 0 {
+		ret.ServiceAreas = strings.Split(string(rVal), ",")
+	}
+	ret.Schedule, _ = hours.Parse(string(rB.Get([]byte("schedule"))))
+	ret.Cost, _ = ParseCost(strings.Split(string(rB.Get([]byte("cost"))), ","),
+		string(rB.Get([]byte("flat_fee"))), string(rB.Get([]byte("cost_notes"))))
+	ret.Eligibility.ParentAge, _ = ParseAgeRange(string(rB.Get([]byte("parent_age"))))
+	ret.Eligibility.ChildAge, _ = ParseAgeRange(string(rB.Get([]byte("child_age"))))
+	if rVal := rB.Get([]byte("pregnancy")); len(rVal) > 0 {
+		ret.Eligibility.Pregnancy = strings.Split(string(rVal), ",")
+	}
+	ret.Eligibility.IncomeFPL, _ = strconv.Atoi(string(rB.Get([]byte("income_fpl"))))
+	if rVal := rB.Get([]byte("residency")); len(rVal) > 0 {
+		ret.Eligibility.Residency = strings.Split(string(rVal), ",")
+	}
+	if rVal := rB.Get([]byte("insurance")); len(rVal) > 0 {
+		ret.Eligibility.Insurance = strings.Split(string(rVal), ",")
+	}
+	ret.Eligibility.Notes = string(rB.Get([]byte("eligibility_notes")))
+	ret.OrgID = string(rB.Get([]byte("org_id")))
+	if rVal := rB.Get([]byte("locations")); len(rVal) > 0 {
+		ret.LocationIDs = strings.Split(string(rVal), ",")
+	}
+	ret.Latitude, _ = strconv.ParseFloat(string(rB.Get([]byte("latitude"))), 64)
+	ret.Longitude, _ = strconv.ParseFloat(string(rB.Get([]byte("longitude"))), 64)
+	return ret
+}
+
+func (s *Store) deleteResource(title string) error {
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("resources"))
+		if err := b.DeleteBucket([]byte(title)); err != nil {
+			return err
+		}
+		return recordChange(tx, ChangeDelete, title)
+	})
+	s.closeDatabase()
+	return err
+}
+
+// Backup
+// Write a consistent copy of the whole database to 'b'
+func (s *Store) Backup(b io.Writer) error {
+	if err := s.loadDatabase(); err != nil {
+		return err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(b)
+		return err
+	})
+	s.closeDatabase()
+
+	return err
+}
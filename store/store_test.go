@@ -0,0 +1,84 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newTestStore
+// Returns a Store on a fresh database, and the events it has sent
+func newTestStore(t *testing.T) (*Store, *[]Event) {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "ii.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make([]Event, 0, 0)
+	s.OnChange(func(ev Event) { events = append(events, ev) })
+	return s, &events
+}
+
+func mustResource(t *testing.T, s *Store, title string) Resource {
+	t.Helper()
+	res, err := s.Resource(title)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// eventTitles
+// The titles of the events sent since 'from'
+func eventTitles(events []Event, from int) []string {
+	ret := make([]string, 0, 0)
+	for _, ev := range events[from:] {
+		ret = append(ret, ev.Resource.Title)
+	}
+	return ret
+}
+
+// testGeocoder knows where one address is
+type testGeocoder struct{}
+
+func (testGeocoder) Geocode(address string) (float64, float64, bool) {
+	if address == "1900 E 9th St N, Wichita, KS 67214" {
+		return 37.6948, -97.3129, true
+	}
+	return 0, 0, false
+}
+
+func TestGeocodeMissing(t *testing.T) {
+	s, events := newTestStore(t)
+	for _, res := range []Resource{
+		{Title: "Found", URL: "https://example.org", Address: "1900 E 9th St N, Wichita, KS 67214"},
+		{Title: "Unknown", URL: "https://example.org", Address: "Somewhere"},
+		{Title: "Nowhere", URL: "https://example.org"},
+	} {
+		if err := s.Save("", res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seq, err := s.Sequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := len(*events)
+
+	s.SetGeocoder(testGeocoder{})
+	n, err := s.GeocodeMissing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Located %d resources, wanted 1", n)
+	}
+	if res := mustResource(t, s, "Found"); res.Latitude != 37.6948 || res.Longitude != -97.3129 {
+		t.Errorf("Found is at %v,%v", res.Latitude, res.Longitude)
+	}
+	if after, _ := s.Sequence(); after != seq {
+		t.Errorf("Locating logged changes, the sequence went from %d to %d", seq, after)
+	}
+	if got := eventTitles(*events, from); len(got) != 0 {
+		t.Errorf("Locating sent events for %v", got)
+	}
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/br0xen/bolt"
+)
+
+// Synonym is a group of search terms that mean the same thing, like
+// "food stamps" and "SNAP", or an acronym and what it stands for
+type Synonym struct {
+	ID      string
+	Acronym string   // Blank for a plain group of synonyms
+	Terms   []string // What the acronym stands for, or the group
+}
+
+// Synonym Model Functions
+// Synonyms are stored in the admin boltdb like so
+// synonyms		(bucket)
+// \- <id> (bucket) (a sequence number)
+//   |-acronym		(pair)
+//   \-terms		(pair) (csv)
+
+// ValidateSynonym
+// Make sure that a synonym has enough terms to be of use
+func ValidateSynonym(syn Synonym) error {
+	if syn.Acronym != "" && len(syn.Terms) == 0 {
+		return fmt.Errorf("An acronym needs what it stands for")
+	}
+	if syn.Acronym == "" && len(syn.Terms) < 2 {
+		return fmt.Errorf("A synonym group needs at least two terms")
+	}
+	return nil
+}
+
+// SaveSynonym
+// Save a synonym, a new ID is given to it if it doesn't have one yet
+func (s *AdminStore) SaveSynonym(syn Synonym) (Synonym, error) {
+	syn.Acronym = strings.TrimSpace(syn.Acronym)
+	syn.Terms = CleanList(syn.Terms)
+	if err := ValidateSynonym(syn); err != nil {
+		return syn, err
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return syn, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("synonyms"))
+		if syn.ID == "" {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			syn.ID = strconv.FormatUint(seq, 10)
+		}
+		sB, err := b.CreateBucketIfNotExists([]byte(syn.ID))
+		if err != nil {
+			return err
+		}
+		if err := sB.Put([]byte("acronym"), []byte(syn.Acronym)); err != nil {
+			return err
+		}
+		return sB.Put([]byte("terms"), []byte(strings.Join(syn.Terms, ",")))
+	})
+	s.closeAdminDatabase()
+	return syn, err
+}
+
+// Synonyms
+// Returns every synonym, acronyms first and then by their first term
+func (s *AdminStore) Synonyms() ([]Synonym, error) {
+	ret := make([]Synonym, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("synonyms"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToSynonym(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	sort.Slice(ret, func(i, j int) bool {
+		if (ret[i].Acronym == "") != (ret[j].Acronym == "") {
+			return ret[i].Acronym != ""
+		}
+		return strings.ToLower(synonymSortKey(ret[i])) < strings.ToLower(synonymSortKey(ret[j]))
+	})
+	return ret, err
+}
+
+func synonymSortKey(syn Synonym) string {
+	if syn.Acronym != "" {
+		return syn.Acronym
+	}
+	if len(syn.Terms) > 0 {
+		return syn.Terms[0]
+	}
+	return ""
+}
+
+// Synonym
+// Returns a single synonym
+func (s *AdminStore) Synonym(id string) (Synonym, error) {
+	var ret Synonym
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		sB := tx.Bucket([]byte("synonyms")).Bucket([]byte(id))
+		if sB == nil {
+			return fmt.Errorf("Synonym not found: %s", id)
+		}
+		ret = bucketToSynonym(id, sB)
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// DeleteSynonym
+// Remove a synonym
+func (s *AdminStore) DeleteSynonym(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("synonyms")).DeleteBucket([]byte(id))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+func bucketToSynonym(id string, sB *bolt.Bucket) Synonym {
+	ret := Synonym{ID: id}
+	ret.Acronym = string(sB.Get([]byte("acronym")))
+	if rVal := sB.Get([]byte("terms")); len(rVal) > 0 {
+		ret.Terms = strings.Split(string(rVal), ",")
+	}
+	return ret
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// API Token Scopes
+const (
+	ScopeCreate = "create"
+	ScopeUpdate = "update"
+	ScopeDelete = "delete"
+)
+
+// TokenScopes are all of the scopes that a token can be given
+var TokenScopes = []string{ScopeCreate, ScopeUpdate, ScopeDelete}
+
+// Token is an API token that allows changes through the API
+type Token struct {
+	ID       string // sha256 of the token, the token itself is never stored
+	Name     string
+	Scopes   []string
+	Created  time.Time
+	Revoked  time.Time
+	Uses     int
+	LastUsed time.Time
+}
+
+// IsRevoked
+// Has the token been revoked
+func (t Token) IsRevoked() bool {
+	return !t.Revoked.IsZero()
+}
+
+// HasScope
+// Is the token allowed to make changes with 'scope'
+func (t Token) HasScope(scope string) bool {
+	for i := range t.Scopes {
+		if t.Scopes[i] == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// API Token Model Functions
+// All API tokens are stored in the admin boltdb like so
+// tokens		(bucket)
+// |- <sha256 of token 1> (bucket)
+// | |-name			(pair)
+// | |-scopes		(pair) (csv)
+// | |-created		(pair) (RFC3339)
+// | |-revoked		(pair) (RFC3339, only once revoked)
+// | |-uses		(pair)
+// | \-lastused		(pair) (RFC3339)
+// |
+// \- <sha256 of token 2> (bucket)
+//   ...
+
+// HashToken
+// Returns the key that a token (or API key) is stored under
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken
+// Generates a new token, saves the hash of it and returns the token.
+// This is the only time that the token itself is available.
+func (s *AdminStore) CreateToken(name string, scopes []string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(raw)
+	if err := s.loadAdminDatabase(); err != nil {
+		return "", err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("tokens"))
+		newB, err := b.CreateBucket([]byte(HashToken(token)))
+		if err != nil {
+			return err
+		}
+		if err := newB.Put([]byte("name"), []byte(name)); err != nil {
+			return err
+		}
+		if err := newB.Put([]byte("scopes"), []byte(strings.Join(scopes, ","))); err != nil {
+			return err
+		}
+		if err := newB.Put([]byte("created"), []byte(time.Now().Format(time.RFC3339))); err != nil {
+			return err
+		}
+		return newB.Put([]byte("uses"), []byte("0"))
+	})
+	s.closeAdminDatabase()
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Tokens
+// Returns all of the API tokens, including revoked ones
+func (s *AdminStore) Tokens() ([]Token, error) {
+	ret := make([]Token, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("tokens"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				ret = append(ret, bucketToToken(string(k), b.Bucket(k)))
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+func bucketToToken(id string, tB *bolt.Bucket) Token {
+	ret := Token{ID: id}
+	ret.Name = string(tB.Get([]byte("name")))
+	if rVal := tB.Get([]byte("scopes")); len(rVal) > 0 {
+		ret.Scopes = strings.Split(string(rVal), ",")
+	}
+	ret.Created, _ = time.Parse(time.RFC3339, string(tB.Get([]byte("created"))))
+	ret.Revoked, _ = time.Parse(time.RFC3339, string(tB.Get([]byte("revoked"))))
+	ret.LastUsed, _ = time.Parse(time.RFC3339, string(tB.Get([]byte("lastused"))))
+	ret.Uses, _ = strconv.Atoi(string(tB.Get([]byte("uses"))))
+	return ret
+}
+
+// RevokeToken
+// Marks a token as revoked. The record is kept so its usage can still be seen.
+func (s *AdminStore) RevokeToken(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		tB := tx.Bucket([]byte("tokens")).Bucket([]byte(id))
+		if tB == nil {
+			return fmt.Errorf("Invalid Token")
+		}
+		return tB.Put([]byte("revoked"), []byte(time.Now().Format(time.RFC3339)))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// UseToken
+// Checks that the token is valid and allowed 'scope', then records the use
+func (s *AdminStore) UseToken(token, scope string) (Token, error) {
+	var ret Token
+	if token == "" {
+		return ret, fmt.Errorf("Invalid Token")
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		id := HashToken(token)
+		tB := tx.Bucket([]byte("tokens")).Bucket([]byte(id))
+		if tB == nil {
+			return fmt.Errorf("Invalid Token")
+		}
+		ret = bucketToToken(id, tB)
+		if ret.IsRevoked() {
+			return fmt.Errorf("Token has been revoked")
+		}
+		if !ret.HasScope(scope) {
+			return fmt.Errorf("Token does not have the '%s' scope", scope)
+		}
+		ret.Uses++
+		ret.LastUsed = time.Now()
+		if err := tB.Put([]byte("uses"), []byte(strconv.Itoa(ret.Uses))); err != nil {
+			return err
+		}
+		return tB.Put([]byte("lastused"), []byte(ret.LastUsed.Format(time.RFC3339)))
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
@@ -0,0 +1,319 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/br0xen/bolt"
+)
+
+// WebhookEvents are the resource events that a webhook can ask for
+var WebhookEvents = []string{EventCreated, EventUpdated, EventDeleted}
+
+// Delivery Statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a URL that is sent resource events
+type Webhook struct {
+	ID      string
+	URL     string
+	Events  []string
+	Secret  string // Used to sign the payloads, so it has to be kept as is
+	Created time.Time
+}
+
+func (h Webhook) HasEvent(event string) bool {
+	if event == EventPing {
+		return true
+	}
+	for i := range h.Events {
+		if h.Events[i] == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is a single event being sent to a webhook
+type Delivery struct {
+	ID          uint64
+	Hook        string
+	URL         string
+	Event       string
+	Payload     []byte
+	Status      string
+	Attempts    int
+	Created     time.Time
+	NextAttempt time.Time
+	LastAttempt time.Time
+	LastStatus  int // HTTP status of the last attempt
+	LastError   string
+}
+
+// Webhook Model Functions
+// Webhooks and their delivery queue are stored in the admin boltdb like so
+// webhooks		(bucket)
+// |- <id> (bucket)
+// | |-url			(pair)
+// | |-events		(pair) (csv)
+// | |-secret		(pair)
+// | \-created		(pair) (RFC3339)
+// |
+// deliveries	(bucket)
+// \- <sequence> (bucket) (big endian uint64, so they sort in order)
+//   |-hook			(pair)
+//   |-url			(pair)
+//   |-event		(pair)
+//   |-payload		(pair) (json)
+//   |-status		(pair) (pending/delivered/failed)
+//   |-attempts		(pair)
+//   |-created		(pair) (RFC3339)
+//   |-nextattempt	(pair) (RFC3339)
+//   |-lastattempt	(pair) (RFC3339)
+//   |-laststatus	(pair)
+//   \-lasterror	(pair)
+
+// SaveWebhook
+// Create a new webhook, a secret for signing its payloads is generated
+func (s *AdminStore) SaveWebhook(url string, events []string) (Webhook, error) {
+	h := Webhook{URL: url, Events: events, Created: time.Now()}
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return h, err
+	}
+	h.Secret = hex.EncodeToString(raw)
+	h.ID = HashToken(h.Secret)[:12]
+	if err := s.loadAdminDatabase(); err != nil {
+		return h, err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+		newB, err := b.CreateBucket([]byte(h.ID))
+		if err != nil {
+			return err
+		}
+		for k, v := range map[string]string{
+			"url":     h.URL,
+			"events":  strings.Join(h.Events, ","),
+			"secret":  h.Secret,
+			"created": h.Created.Format(time.RFC3339),
+		} {
+			if err := newB.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return h, err
+}
+
+// Webhooks
+// Returns all of the registered webhooks
+func (s *AdminStore) Webhooks() ([]Webhook, error) {
+	ret := make([]Webhook, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // Nested Bucket
+				hB := b.Bucket(k)
+				h := Webhook{ID: string(k)}
+				h.URL = string(hB.Get([]byte("url")))
+				if rVal := hB.Get([]byte("events")); len(rVal) > 0 {
+					h.Events = strings.Split(string(rVal), ",")
+				}
+				h.Secret = string(hB.Get([]byte("secret")))
+				h.Created, _ = time.Parse(time.RFC3339, string(hB.Get([]byte("created"))))
+				ret = append(ret, h)
+			}
+			return nil
+		})
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// Webhook
+// Returns a single webhook
+func (s *AdminStore) Webhook(id string) (Webhook, error) {
+	hooks, err := s.Webhooks()
+	if err != nil {
+		return Webhook{}, err
+	}
+	for i := range hooks {
+		if hooks[i].ID == id {
+			return hooks[i], nil
+		}
+	}
+	return Webhook{}, fmt.Errorf("Invalid Webhook")
+}
+
+// DeleteWebhook
+// Remove a webhook, its deliveries are left to fail
+func (s *AdminStore) DeleteWebhook(id string) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("webhooks"))
+		return b.DeleteBucket([]byte(id))
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// AddDeliveries
+// Put deliveries in the queue, their IDs are filled in
+func (s *AdminStore) AddDeliveries(dels []Delivery) error {
+	if len(dels) == 0 {
+		return nil
+	}
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("deliveries"))
+		var err error
+		for i := range dels {
+			if dels[i].ID, err = b.NextSequence(); err != nil {
+				return err
+			}
+			dB, err := b.CreateBucket(sequenceKey(dels[i].ID))
+			if err != nil {
+				return err
+			}
+			if err := putDelivery(dB, dels[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+// SaveDelivery
+// Update a delivery that is already in the queue
+func (s *AdminStore) SaveDelivery(d Delivery) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("deliveries"))
+		dB := b.Bucket(sequenceKey(d.ID))
+		if dB == nil {
+			return fmt.Errorf("Invalid Delivery")
+		}
+		return putDelivery(dB, d)
+	})
+	s.closeAdminDatabase()
+	return err
+}
+
+func putDelivery(dB *bolt.Bucket, d Delivery) error {
+	for k, v := range map[string]string{
+		"hook":        d.Hook,
+		"url":         d.URL,
+		"event":       d.Event,
+		"payload":     string(d.Payload),
+		"status":      d.Status,
+		"attempts":    strconv.Itoa(d.Attempts),
+		"created":     d.Created.Format(time.RFC3339),
+		"nextattempt": d.NextAttempt.Format(time.RFC3339),
+		"lastattempt": d.LastAttempt.Format(time.RFC3339),
+		"laststatus":  strconv.Itoa(d.LastStatus),
+		"lasterror":   d.LastError,
+	} {
+		if err := dB.Put([]byte(k), []byte(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliveries
+// Returns deliveries, newest first. If 'status' is given only deliveries
+// with that status are returned. 'limit' of 0 returns them all.
+func (s *AdminStore) Deliveries(status string, limit int) ([]Delivery, error) {
+	ret := make([]Delivery, 0, 0)
+	if err := s.loadAdminDatabase(); err != nil {
+		return ret, err
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("deliveries"))
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if v != nil {
+				continue
+			}
+			dB := b.Bucket(k)
+			if status != "" && string(dB.Get([]byte("status"))) != status {
+				continue
+			}
+			d := Delivery{ID: binary.BigEndian.Uint64(k)}
+			d.Hook = string(dB.Get([]byte("hook")))
+			d.URL = string(dB.Get([]byte("url")))
+			d.Event = string(dB.Get([]byte("event")))
+			d.Payload = append([]byte{}, dB.Get([]byte("payload"))...)
+			d.Status = string(dB.Get([]byte("status")))
+			d.Attempts, _ = strconv.Atoi(string(dB.Get([]byte("attempts"))))
+			d.Created, _ = time.Parse(time.RFC3339, string(dB.Get([]byte("created"))))
+			d.NextAttempt, _ = time.Parse(time.RFC3339, string(dB.Get([]byte("nextattempt"))))
+			d.LastAttempt, _ = time.Parse(time.RFC3339, string(dB.Get([]byte("lastattempt"))))
+			d.LastStatus, _ = strconv.Atoi(string(dB.Get([]byte("laststatus"))))
+			d.LastError = string(dB.Get([]byte("lasterror")))
+			ret = append(ret, d)
+			if limit > 0 && len(ret) >= limit {
+				break
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return ret, err
+}
+
+// PruneDeliveries
+// Remove finished deliveries created before 'before'
+func (s *AdminStore) PruneDeliveries(before time.Time) error {
+	if err := s.loadAdminDatabase(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte("deliveries"))
+		old := make([][]byte, 0, 0)
+		err := b.ForEach(func(k, v []byte) error {
+			if v == nil {
+				dB := b.Bucket(k)
+				created, _ := time.Parse(time.RFC3339, string(dB.Get([]byte("created"))))
+				if string(dB.Get([]byte("status"))) != DeliveryPending && created.Before(before) {
+					old = append(old, append([]byte{}, k...))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range old {
+			if err := b.DeleteBucket(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.closeAdminDatabase()
+	return err
+}
@@ -0,0 +1,193 @@
+// Package transit reads a static GTFS feed, like the one Wichita Transit
+// publishes, to tell families which bus routes stop near a resource.
+package transit
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openwichita/infant-info/geo"
+)
+
+// Stop is a place where buses stop, and the routes that stop there
+type Stop struct {
+	ID     string
+	Name   string
+	Routes []string // Route names, like "21"
+	geo.Point
+}
+
+// RouteStop is the closest stop on a route to somewhere
+type RouteStop struct {
+	Route string
+	Stop  string
+	Miles float64
+}
+
+// Feed is the stops and routes from a GTFS zip
+type Feed struct {
+	Stops []Stop
+}
+
+// LoadFeed
+// Read the stops, routes, trips and stop times from a GTFS zip file
+func LoadFeed(path string) (*Feed, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	routeNames := make(map[string]string) // route_id -> name
+	err = readTable(&zr.Reader, "routes.txt", func(row map[string]string) error {
+		name := row["route_short_name"]
+		if name == "" {
+			name = row["route_long_name"]
+		}
+		routeNames[row["route_id"]] = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tripRoutes := make(map[string]string) // trip_id -> route name
+	err = readTable(&zr.Reader, "trips.txt", func(row map[string]string) error {
+		tripRoutes[row["trip_id"]] = routeNames[row["route_id"]]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	stopRoutes := make(map[string]map[string]bool) // stop_id -> route names
+	err = readTable(&zr.Reader, "stop_times.txt", func(row map[string]string) error {
+		route := tripRoutes[row["trip_id"]]
+		if route == "" {
+			return nil
+		}
+		if stopRoutes[row["stop_id"]] == nil {
+			stopRoutes[row["stop_id"]] = make(map[string]bool)
+		}
+		stopRoutes[row["stop_id"]][route] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f := new(Feed)
+	err = readTable(&zr.Reader, "stops.txt", func(row map[string]string) error {
+		if len(stopRoutes[row["stop_id"]]) == 0 {
+			// Stations and stops that nothing is scheduled at
+			return nil
+		}
+		lat, err := strconv.ParseFloat(row["stop_lat"], 64)
+		if err != nil {
+			return fmt.Errorf("stops.txt: stop %s: bad latitude %q", row["stop_id"], row["stop_lat"])
+		}
+		lng, err := strconv.ParseFloat(row["stop_lon"], 64)
+		if err != nil {
+			return fmt.Errorf("stops.txt: stop %s: bad longitude %q", row["stop_id"], row["stop_lon"])
+		}
+		st := Stop{ID: row["stop_id"], Name: row["stop_name"], Point: geo.Point{Lat: lat, Lng: lng}}
+		for r := range stopRoutes[st.ID] {
+			st.Routes = append(st.Routes, r)
+		}
+		sort.Strings(st.Routes)
+		f.Stops = append(f.Stops, st)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// readTable
+// Call 'fn' with each row of the CSV file 'name' in the zip, keyed by the
+// column names in its header
+func readTable(zr *zip.Reader, name string, fn func(map[string]string) error) error {
+	var zf *zip.File
+	for _, f := range zr.File {
+		// Some feeds put their files in a folder
+		if f.Name == name || strings.HasSuffix(f.Name, "/"+name) {
+			zf = f
+			break
+		}
+	}
+	if zf == nil {
+		return fmt.Errorf("GTFS feed has no %s", name)
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r := csv.NewReader(rc)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	row := make(map[string]string, len(header))
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		for i := range header {
+			row[header[i]] = ""
+			if i < len(rec) {
+				row[header[i]] = strings.TrimSpace(rec[i])
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// Nearby
+// Returns the closest stop on each route within 'miles' of 'pt', closest
+// first, at most 'limit' of them
+func (f *Feed) Nearby(pt geo.Point, miles float64, limit int) []RouteStop {
+	if f == nil {
+		return nil
+	}
+	best := make(map[string]RouteStop)
+	for _, st := range f.Stops {
+		d := geo.Distance(pt, st.Point)
+		if d > miles {
+			continue
+		}
+		for _, r := range st.Routes {
+			if cur, ok := best[r]; !ok || d < cur.Miles {
+				best[r] = RouteStop{Route: r, Stop: st.Name, Miles: d}
+			}
+		}
+	}
+	ret := make([]RouteStop, 0, len(best))
+	for _, rs := range best {
+		ret = append(ret, rs)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Miles != ret[j].Miles {
+			return ret[i].Miles < ret[j].Miles
+		}
+		return ret[i].Route < ret[j].Route
+	})
+	if len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret
+}
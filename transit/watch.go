@@ -0,0 +1,78 @@
+package transit
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openwichita/infant-info/geo"
+)
+
+// Watcher keeps the Feed from a GTFS zip, loading it again whenever a new
+// file is dropped in its place
+type Watcher struct {
+	path   string
+	output func(string)
+
+	mu      sync.RWMutex
+	feed    *Feed
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher
+// Returns a Watcher for the GTFS zip at 'path', which doesn't have to exist
+// yet. Problems loading it are written to 'output'.
+func NewWatcher(path string, output func(string)) *Watcher {
+	w := &Watcher{path: path, output: output}
+	w.Reload()
+	return w
+}
+
+// Reload
+// Load the feed again if the file has changed since it was last loaded
+// A file that's missing or doesn't load leaves the last good feed in place.
+func (w *Watcher) Reload() {
+	fi, err := os.Stat(w.path)
+	if err != nil {
+		return
+	}
+	w.mu.RLock()
+	same := fi.ModTime().Equal(w.modTime) && fi.Size() == w.size
+	w.mu.RUnlock()
+	if same {
+		return
+	}
+	feed, err := LoadFeed(w.path)
+	w.mu.Lock()
+	// Don't try the same broken file again
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+	if err == nil {
+		w.feed = feed
+	}
+	w.mu.Unlock()
+	if err != nil {
+		w.output("Error loading GTFS feed " + w.path + ": " + err.Error() + "\n")
+		return
+	}
+	w.output("Loaded GTFS feed " + w.path + "\n")
+}
+
+// Run
+// Check for a new file every 'every', run this in a goroutine
+func (w *Watcher) Run(every time.Duration) {
+	for range time.Tick(every) {
+		w.Reload()
+	}
+}
+
+// Nearby
+// Returns the closest stop on each route near 'pt', see Feed.Nearby
+func (w *Watcher) Nearby(pt geo.Point, miles float64, limit int) []RouteStop {
+	if w == nil {
+		return nil
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.feed.Nearby(pt, miles, limit)
+}
@@ -0,0 +1,143 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// Search Analytics
+// Searches and the results followed from them are counted in memory and
+// added to the day's totals in the admin database every so often, see
+// store/searchstats.go. Only the (cleaned up) query and the counts are
+// kept, never who searched.
+
+// searchStats adds up searches and clicks until they are saved
+type searchStats struct {
+	mu      sync.Mutex
+	queries map[string]*store.QueryStat
+	clicks  map[string]int
+}
+
+func newSearchStats() *searchStats {
+	return &searchStats{
+		queries: make(map[string]*store.QueryStat),
+		clicks:  make(map[string]int),
+	}
+}
+
+func (st *searchStats) query(q string) *store.QueryStat {
+	qs, ok := st.queries[q]
+	if !ok {
+		qs = &store.QueryStat{Query: q}
+		st.queries[q] = qs
+	}
+	return qs
+}
+
+// search
+// Count a search for 'query' that found 'results' resources
+func (st *searchStats) search(query string, results int) {
+	q := search.StatsQuery(query)
+	if q == "" {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	qs := st.query(q)
+	qs.Searches++
+	if results == 0 {
+		qs.ZeroResults++
+	}
+}
+
+// click
+// Count a click through to 'title', from a search for 'query' if it isn't blank
+func (st *searchStats) click(query, title string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.clicks[title]++
+	if q := search.StatsQuery(query); q != "" {
+		st.query(q).Clicks++
+	}
+}
+
+// take
+// Returns everything counted so far and starts again
+func (st *searchStats) take() store.DayStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	day := store.DayStats{Day: time.Now().Format(store.StatsDayFormat)}
+	for _, qs := range st.queries {
+		day.Queries = append(day.Queries, *qs)
+	}
+	for title, n := range st.clicks {
+		day.Clicks = append(day.Clicks, store.ClickStat{Title: title, Clicks: n})
+	}
+	st.queries = make(map[string]*store.QueryStat)
+	st.clicks = make(map[string]int)
+	return day
+}
+
+// SaveSearchStats
+// Add the searches and clicks counted so far to the admin database
+func (s *Server) SaveSearchStats() error {
+	day := s.stats.take()
+	if err := s.Admin.AddSearchStats(day); err != nil {
+		// Put them back to try again next time
+		s.stats.mu.Lock()
+		for _, q := range day.Queries {
+			qs := s.stats.query(q.Query)
+			qs.Searches += q.Searches
+			qs.ZeroResults += q.ZeroResults
+			qs.Clicks += q.Clicks
+		}
+		for _, c := range day.Clicks {
+			s.stats.clicks[c.Title] += c.Clicks
+		}
+		s.stats.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// FlushSearchStats
+// Save the search statistics every 'every', run this in a goroutine
+func (s *Server) FlushSearchStats(every time.Duration) {
+	for range time.Tick(every) {
+		if err := s.SaveSearchStats(); err != nil {
+			s.PrintOutput(fmt.Sprintf("Error saving search statistics: %s\n", err))
+		}
+	}
+}
+
+// resultURL
+// The link for a resource in the results, which counts the click on the
+// way to the resource's own URL
+func (s *Server) resultURL(title, query string) string {
+	ret := s.URL("/go/" + url.PathEscape(title))
+	if query != "" {
+		ret += "?" + url.Values{search.ParamQuery: []string{query}}.Encode()
+	}
+	return ret
+}
+
+// handleGo
+// Count a click on a result and send the browser on to the resource
+func (s *Server) handleGo(w http.ResponseWriter, req *http.Request) {
+	title := mux.Vars(req)["title"]
+	res, err := s.Store.Resource(title)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("%s\n", err))
+		http.NotFound(w, req)
+		return
+	}
+	s.stats.click(req.URL.Query().Get(search.ParamQuery), res.Title)
+	http.Redirect(w, req, res.URL, http.StatusFound)
+}
@@ -0,0 +1,340 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// How many autocomplete suggestions /api/suggest returns
+const (
+	defaultSuggestions = 10
+	maxSuggestions     = 50
+)
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// apiRoute describes one of the JSON endpoints under /api
+// The routes are registered from this table, and the OpenAPI document is
+// generated from it, so the two can't drift apart.
+type apiRoute struct {
+	Method   string
+	Path     string // Relative to /api
+	Handler  http.HandlerFunc
+	Summary  string
+	Query    map[string]string // Query parameters and their descriptions
+	Scope    string            // Token scope required, if any
+	Request  interface{}       // Request body, if any
+	Response interface{}       // Response body on success, if any
+	Status   int               // Status on success
+	Errors   []int             // Other statuses that may be returned
+}
+
+// apiRoutes
+// Returns the table of JSON endpoints
+func (s *Server) apiRoutes() []apiRoute {
+	return []apiRoute{
+		{
+			Method: "GET", Path: "/resources", Handler: s.handleAPIListResources,
+			Summary: "List resources, sorted by title",
+			Query: map[string]string{
+				"limit": "Page size, the Link header points at the next page",
+				"after": "Only list resources with titles after this one",
+			},
+			Response: []store.Resource{}, Status: http.StatusOK,
+			Errors: []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "POST", Path: "/resources", Handler: s.handleAPICreateResource,
+			Summary: "Create a resource", Scope: store.ScopeCreate,
+			Request: store.Resource{}, Response: store.Resource{}, Status: http.StatusCreated,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict},
+		},
+		{
+			Method: "GET", Path: "/resources/{title}", Handler: s.handleAPIGetResource,
+			Summary:  "Get a resource",
+			Response: store.Resource{}, Status: http.StatusOK,
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method: "PUT", Path: "/resources/{title}", Handler: s.handleAPIUpdateResource,
+			Summary: "Replace a resource", Scope: store.ScopeUpdate,
+			Request: store.Resource{}, Response: store.Resource{}, Status: http.StatusOK,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusConflict},
+		},
+		{
+			Method: "DELETE", Path: "/resources/{title}", Handler: s.handleAPIDeleteResource,
+			Summary: "Delete a resource", Scope: store.ScopeDelete,
+			Status: http.StatusNoContent,
+			Errors: []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/orgs", Handler: s.handleAPIListOrgs,
+			Summary:  "List organizations with their locations, sorted by name",
+			Response: []store.Organization{}, Status: http.StatusOK,
+			Errors: []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/orgs/{id}", Handler: s.handleAPIGetOrg,
+			Summary:  "Get an organization with its locations",
+			Response: store.Organization{}, Status: http.StatusOK,
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method: "GET", Path: "/sync/bootstrap", Handler: s.handleAPISyncBootstrap,
+			Summary:  "Get every resource and a sync token to follow changes from",
+			Response: syncBootstrap{}, Status: http.StatusOK,
+			Errors: []int{http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/sync", Handler: s.handleAPISync,
+			Summary: "Get the changes made since a sync token",
+			Query: map[string]string{
+				"since": "The sync_token from the bootstrap or the last sync",
+				"limit": "The most changes to return, up to 1000",
+			},
+			Response: syncChanges{}, Status: http.StatusOK,
+			Errors: []int{http.StatusBadRequest, http.StatusInternalServerError},
+		},
+		{
+			Method: "GET", Path: "/suggest", Handler: s.handleAPISuggest,
+			Summary: "Autocomplete resource titles, organizations and tags",
+			Query: map[string]string{
+				"q":     "What has been typed so far",
+				"limit": "The most suggestions to return, up to 50 (default 10)",
+			},
+			Response: []search.Completion{}, Status: http.StatusOK,
+			Errors: []int{http.StatusInternalServerError},
+		},
+	}
+}
+
+// handleAPIListResources
+// Returns resources as JSON, sorted by title. If 'limit' is given the list is
+// paged and a Link header points at the next page.
+func (s *Server) handleAPIListResources(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	resources, err := s.Store.Resources()
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Title < resources[j].Title })
+
+	v := req.URL.Query()
+	if after := v.Get("after"); after != "" {
+		start := sort.Search(len(resources), func(i int) bool { return resources[i].Title > after })
+		resources = resources[start:]
+	}
+	if limit, err := strconv.Atoi(v.Get("limit")); err == nil && limit > 0 && limit < len(resources) {
+		resources = resources[:limit]
+		next := url.Values{}
+		next.Set("after", resources[limit-1].Title)
+		next.Set("limit", strconv.Itoa(limit))
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, s.URL("/api/resources"), next.Encode()))
+	}
+	s.writeJSON(w, http.StatusOK, resources)
+}
+
+// handleAPIGetResource
+// Returns a single resource as JSON
+func (s *Server) handleAPIGetResource(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	title, err := apiPathVar(req, "title")
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	res, err := s.Store.Resource(title)
+	if err != nil {
+		s.writeJSON(w, http.StatusNotFound, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, res)
+}
+
+// handleAPICreateResource
+// Create a new resource from the JSON request body
+// Requires a token with the 'create' scope
+func (s *Server) handleAPICreateResource(w http.ResponseWriter, req *http.Request) {
+	if !s.apiAuthorize(w, req, store.ScopeCreate) {
+		return
+	}
+	var res store.Resource
+	if err := json.NewDecoder(req.Body).Decode(&res); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	if err := s.Store.Save("", res); err != nil {
+		s.writeJSON(w, apiSaveStatus(err), apiError{err.Error()})
+		return
+	}
+	s.apiWriteResource(w, http.StatusCreated, res.Title)
+}
+
+// handleAPIUpdateResource
+// Replace the resource at {title} with the JSON request body
+// Requires a token with the 'update' scope
+func (s *Server) handleAPIUpdateResource(w http.ResponseWriter, req *http.Request) {
+	if !s.apiAuthorize(w, req, store.ScopeUpdate) {
+		return
+	}
+	origTitle, err := apiPathVar(req, "title")
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	if _, err := s.Store.Resource(origTitle); err != nil {
+		s.writeJSON(w, http.StatusNotFound, apiError{err.Error()})
+		return
+	}
+	var res store.Resource
+	if err := json.NewDecoder(req.Body).Decode(&res); err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	if res.Title == "" {
+		// Allow updates that don't repeat the title
+		res.Title = origTitle
+	}
+	if err := s.Store.Save(origTitle, res); err != nil {
+		s.writeJSON(w, apiSaveStatus(err), apiError{err.Error()})
+		return
+	}
+	s.apiWriteResource(w, http.StatusOK, res.Title)
+}
+
+// handleAPIDeleteResource
+// Delete the resource at {title}
+// Requires a token with the 'delete' scope
+func (s *Server) handleAPIDeleteResource(w http.ResponseWriter, req *http.Request) {
+	if !s.apiAuthorize(w, req, store.ScopeDelete) {
+		return
+	}
+	title, err := apiPathVar(req, "title")
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	if _, err := s.Store.Resource(title); err != nil {
+		s.writeJSON(w, http.StatusNotFound, apiError{err.Error()})
+		return
+	}
+	if err := s.Store.Delete(title); err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIListOrgs
+// Returns every organization as JSON, with its locations
+func (s *Server) handleAPIListOrgs(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	orgs, err := s.Store.Organizations()
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, orgs)
+}
+
+// handleAPIGetOrg
+// Returns a single organization as JSON, with its locations
+func (s *Server) handleAPIGetOrg(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	id, err := apiPathVar(req, "id")
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+		return
+	}
+	org, err := s.Store.Organization(id)
+	if err != nil {
+		s.writeJSON(w, http.StatusNotFound, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, org)
+}
+
+// handleAPISuggest
+// Returns autocomplete suggestions for the search box
+func (s *Server) handleAPISuggest(w http.ResponseWriter, req *http.Request) {
+	v := req.URL.Query()
+	limit := defaultSuggestions
+	if l, err := strconv.Atoi(v.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+	resources, err := s.Store.Resources()
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, search.Complete(v.Get("q"), resources, limit))
+}
+
+// apiAuthorize
+// Checks the request's bearer token for 'scope'
+// If it isn't allowed, an error is written and false is returned
+func (s *Server) apiAuthorize(w http.ResponseWriter, req *http.Request, scope string) bool {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	t, err := s.Admin.UseToken(strings.TrimSpace(token), scope)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("  Unauthorized: %s\n", err))
+		w.Header().Set("WWW-Authenticate", `Bearer realm="infant-info"`)
+		s.writeJSON(w, http.StatusUnauthorized, apiError{err.Error()})
+		return false
+	}
+	s.PrintOutput(fmt.Sprintf("  Token: %s (%d uses)\n", t.Name, t.Uses))
+	return true
+}
+
+// apiPathVar
+// Returns the path variable 'name', unescaped
+func apiPathVar(req *http.Request, name string) (string, error) {
+	return url.PathUnescape(mux.Vars(req)[name])
+}
+
+// apiSaveStatus
+// Returns the status for a resource that couldn't be saved
+func apiSaveStatus(err error) int {
+	if _, ok := err.(store.ResourceExistsError); ok {
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+// apiWriteResource
+// Respond with the resource as it is now stored
+func (s *Server) apiWriteResource(w http.ResponseWriter, status int, title string) {
+	res, err := s.Store.Resource(title)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, status, res)
+}
+
+// writeJSON
+// Spit out 'v' as the JSON response
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// apiCall
+// Make a request to the API with 'token', returning the status
+func apiCall(t *testing.T, ts *httptest.Server, token, method, path string, body interface{}) int {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, ts.URL+"/api"+path, &buf)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAPICreateConflict(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	token, err := s.Admin.CreateToken("test", []string{store.ScopeCreate})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only one of the creates can win, however they're interleaved
+	res := store.Resource{Title: "Healthy Babies", URL: "https://example.org"}
+	statuses := make([]int, 8)
+	var wg sync.WaitGroup
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = apiCall(t, ts, token, "POST", "/resources", res)
+		}(i)
+	}
+	wg.Wait()
+	created := 0
+	for _, st := range statuses {
+		switch st {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("Got a %d", st)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d creates succeeded, wanted 1", created)
+	}
+
+	changes, _, _, err := s.Store.Changes(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("Logged %d changes, wanted 1", len(changes))
+	}
+}
+
+// TestAPITitleEscaping
+// Titles are unescaped exactly once, so '+', '%' and '/' all survive
+func TestAPITitleEscaping(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	token, err := s.Admin.CreateToken("test", []string{store.ScopeUpdate, store.ScopeDelete})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, title := range []string{"A+B 100%", "A/B Clinic", "Caf%C3%A9"} {
+		if err := s.Store.Save("", store.Resource{Title: title, URL: "https://example.org"}); err != nil {
+			t.Fatal(err)
+		}
+		path := "/resources/" + url.PathEscape(title)
+		if st := apiCall(t, ts, "", "GET", path, nil); st != http.StatusOK {
+			t.Errorf("Getting %q: %d", title, st)
+		}
+		upd := store.Resource{Title: title, URL: "https://example.org", Description: "Updated"}
+		if st := apiCall(t, ts, token, "PUT", path, upd); st != http.StatusOK {
+			t.Errorf("Updating %q: %d", title, st)
+		}
+		if res, err := s.Store.Resource(title); err != nil || res.Description != "Updated" {
+			t.Errorf("Updating %q changed %+v (%v)", title, res, err)
+		}
+		if st := apiCall(t, ts, token, "DELETE", path, nil); st != http.StatusNoContent {
+			t.Errorf("Deleting %q: %d", title, st)
+		}
+		if _, err := s.Store.Resource(title); err == nil {
+			t.Errorf("%q is still there", title)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// How many sizes the tag cloud uses, see .tag-cloud-N in ii.css
+const tagCloudLevels = 5
+
+type browseChip struct {
+	Tag    string
+	Remove string // Link to the same page without this tag
+}
+
+type browseCloudTag struct {
+	Name  string
+	Count int
+	Level int    // 1 to tagCloudLevels, bigger for more resources
+	Link  string // Link to the current page with this tag added
+}
+
+type browseData struct {
+	Tags    string         // The tag path, like "food+meals,housing"
+	Chips   [][]browseChip // One slice for each group of OR'd tags
+	Cloud   []browseCloudTag
+	Results resultsData
+
+	// The form for where the family is keeps the rest of the filter
+	Location nearData
+	Hidden   url.Values
+}
+
+// handleBrowse
+// The main handler for all 'browse' functionality
+// /browse/food,housing shows resources tagged food AND housing,
+// /browse/food+meals shows resources tagged food OR meals.
+// The other facets are in the query, see search.ParseFilter.
+func (s *Server) handleBrowse(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+	vars := mux.Vars(req)
+	qry := search.ParseTagQuery(vars["tags"])
+	f := search.ParseFilter(req.URL.Query())
+	location := s.locate(&f)
+	vals := f.Values()
+	s.ShowCrisis(site, strings.Join(append(append(qry.Tags(), f.Tags...), f.Query), " "))
+
+	resources, err := s.Store.Resources()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+		// TODO: Show Flash Message
+		//showFlashMessage("Error Loading Resources!", "error")
+	}
+	matches := make([]store.Resource, 0, 0)
+	for i := range resources {
+		if qry.Matches(resources[i]) && f.Matches(resources[i]) {
+			matches = append(matches, resources[i])
+		}
+	}
+
+	data := browseData{
+		Tags:     qry.String(),
+		Location: location,
+		Hidden:   f.Values(),
+		// The tag cloud stands in for the Tags facet
+		Results: s.buildResults(req, "/browse/"+qry.String(), f, matches, s.Promotions("", append(qry.Tags(), f.Tags...)), "Tags"),
+	}
+	data.Hidden.Del(search.ParamNear)
+	data.Hidden.Del(search.ParamWithin)
+	data.Hidden.Del(search.ParamServes)
+	for _, grp := range qry {
+		chips := make([]browseChip, 0, len(grp))
+		for _, t := range grp {
+			chips = append(chips, browseChip{Tag: t, Remove: s.browseURL(qry.Without(t), vals)})
+		}
+		data.Chips = append(data.Chips, chips)
+	}
+
+	// The cloud only offers tags that narrow down the current results
+	counts := search.CountTags(matches)
+	most := 0
+	for i := range counts {
+		if counts[i].Count > most {
+			most = counts[i].Count
+		}
+	}
+	for i := range counts {
+		if qry.Has(counts[i].Name) {
+			continue
+		}
+		data.Cloud = append(data.Cloud, browseCloudTag{
+			Name:  counts[i].Name,
+			Count: counts[i].Count,
+			Level: 1 + (counts[i].Count*tagCloudLevels-1)/most,
+			Link:  s.browseURL(qry.With(counts[i].Name), vals),
+		})
+	}
+
+	site.SubTitle = "Browse Resources"
+	site.SetMenuItemActive("Browse")
+	site.TemplateData = data
+	s.ShowPage("browse.html", site, w)
+}
+
+// browseURL
+// Returns the link to the resources matching 'qry' and the query 'vals'
+func (s *Server) browseURL(qry search.TagQuery, vals url.Values) string {
+	return s.resultsURL("/browse/"+qry.String(), vals, 1)
+}
@@ -0,0 +1,65 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// Crisis Messages
+// Some searches, like "suicide" or "baby not breathing", need more than a
+// list of results. When a search or browse matches one of the crisis terms
+// the admins keep, its message and hotline are shown in the Flash aside at
+// the top of the page, above everything else.
+
+// ShowCrisis
+// Put the crisis message matching 'text' into the Flash, if there is one
+func (s *Server) ShowCrisis(site *SiteData, text string) {
+	crises, err := s.Admin.Crises()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Crisis Messages: %s\n", err))
+		return
+	}
+	if c, ok := search.MatchCrisis(text, crises); ok {
+		site.Flash = FlashMessage{
+			Message: c.Message,
+			Status:  "error",
+			Phone:   c.Phone,
+			Link:    c.Link,
+		}
+	}
+}
+
+// Banner
+// Returns the sitewide emergency banner, blank if the admins haven't set one
+func (s *Server) Banner() FlashMessage {
+	vals, err := s.Admin.Settings(store.SettingBannerMessage, store.SettingBannerStatus, store.SettingBannerLink)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Banner: %s\n", err))
+		return FlashMessage{}
+	}
+	ret := FlashMessage{
+		Message: vals[store.SettingBannerMessage],
+		Status:  vals[store.SettingBannerStatus],
+		Link:    vals[store.SettingBannerLink],
+	}
+	if ret.Status == "" {
+		ret.Status = "warning"
+	}
+	return ret
+}
+
+// PhoneLink
+// Returns the tel: link for a phone number, like "1-800-273-8255" or "988".
+// Only digits and '+' are kept, so it's safe for the template to use as is.
+func (f FlashMessage) PhoneLink() template.URL {
+	return template.URL("tel:" + strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '+' {
+			return r
+		}
+		return -1
+	}, f.Phone))
+}
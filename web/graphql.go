@@ -0,0 +1,952 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// The GraphQL schema over the resource directory
+//
+//	resource(title)               A single resource
+//	resources(filters, paging)    A ResourceConnection of matching resources
+//	tags                          Every tag with its resource count
+//	organizations                 Every organization with its resources
+//	organization(id or name)      A single organization
+//
+// Resource lists are paged relay-style, with 'first' and 'after' arguments
+// and opaque cursors.
+
+// Default and maximum page size for resource connections
+const (
+	gqlDefaultPageSize = 20
+	gqlMaxPageSize     = 100
+)
+
+// Limits on a single query, checked before it runs. The depth counts
+// nested selections, and the complexity counts every field it could
+// resolve, multiplying what's inside a resource connection by its page size
+// and what's inside the lists that aren't paged by how long they are now.
+const (
+	gqlMaxDepth      = 12
+	gqlMaxComplexity = 5000
+)
+
+type gqlTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// gqlOrganization is an organization, or just the org name of resources
+// that aren't linked to one, which leaves the rest blank
+type gqlOrganization struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Website      string           `json:"website"`
+	ContactName  string           `json:"contactName"`
+	ContactEmail string           `json:"contactEmail"`
+	ContactPhone string           `json:"contactPhone"`
+	Logo         string           `json:"logo"`
+	Locations    []store.Location `json:"locations"`
+}
+
+type gqlEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   store.Resource `json:"node"`
+}
+
+type gqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type gqlConnection struct {
+	TotalCount int         `json:"totalCount"`
+	Edges      []gqlEdge   `json:"edges"`
+	PageInfo   gqlPageInfo `json:"pageInfo"`
+}
+
+func (s *Server) buildGraphQLSchema() (graphql.Schema, error) {
+	strList := graphql.NewList(graphql.String)
+
+	orgType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Organization",
+		Fields: graphql.Fields{},
+	})
+	locationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Location",
+		Description: "A place where an organization offers its programs",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"name":    &graphql.Field{Type: graphql.String},
+			"address": &graphql.Field{Type: graphql.String},
+			"phones":  &graphql.Field{Type: strList},
+			"schedule": &graphql.Field{
+				Type:        graphql.String,
+				Description: "When it's open, one rule to a line, like \"Mon-Fri 8am-5pm\"",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Location).Schedule.String(), nil
+				},
+			},
+			"openNow": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Null when nobody has said when it's open",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					st := p.Source.(store.Location).Schedule.Status(time.Now())
+					if !st.Known {
+						return nil, nil
+					}
+					return st.Open, nil
+				},
+			},
+			"hoursStatus": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Like \"Open now until 5pm\" or \"Opens tomorrow at 8am\"",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Location).Schedule.Status(time.Now()).Label, nil
+				},
+			},
+			"latitude": &graphql.Field{Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if loc := p.Source.(store.Location); loc.Latitude != 0 || loc.Longitude != 0 {
+					return loc.Latitude, nil
+				}
+				return nil, nil
+			}},
+			"longitude": &graphql.Field{Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if loc := p.Source.(store.Location); loc.Latitude != 0 || loc.Longitude != 0 {
+					return loc.Longitude, nil
+				}
+				return nil, nil
+			}},
+		},
+	})
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Tag",
+		Fields: graphql.Fields{},
+	})
+	costType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Cost",
+		Description: "What a resource costs and how families can pay for it",
+		Fields: graphql.Fields{
+			"free":             gqlCostOption(store.CostFree),
+			"slidingScale":     gqlCostOption(store.CostSlidingScale),
+			"medicaid":         gqlCostOption(store.CostMedicaid),
+			"chip":             gqlCostOption(store.CostCHIP),
+			"privateInsurance": gqlCostOption(store.CostPrivateInsurance),
+			"uninsured":        gqlCostOption(store.CostUninsured),
+			"flatFee": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Cost).FlatFee, nil
+				},
+			},
+			"notes": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Cost).Notes, nil
+				},
+			},
+			"labels": &graphql.Field{
+				Type:        strList,
+				Description: "Like \"Free\" or \"Accepts KanCare/Medicaid\", as used by the cost argument",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Cost).Labels(), nil
+				},
+			},
+		},
+	})
+	ageRangeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "AgeRange",
+		Description: "From min to max inclusive, no max is no limit",
+		Fields: graphql.Fields{
+			"min": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"max": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if r := p.Source.(store.AgeRange); r.Max > 0 {
+					return r.Max, nil
+				}
+				return nil, nil
+			}},
+		},
+	})
+	eligibilityType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Eligibility",
+		Description: "Who a resource is for, blank fields don't limit anything",
+		Fields: graphql.Fields{
+			"parentAge": &graphql.Field{
+				Type:        ageRangeType,
+				Description: "Years",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return gqlAgeRange(p.Source.(store.Eligibility).ParentAge), nil
+				},
+			},
+			"childAge": &graphql.Field{
+				Type:        ageRangeType,
+				Description: "Months",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return gqlAgeRange(p.Source.(store.Eligibility).ChildAge), nil
+				},
+			},
+			"pregnancy": &graphql.Field{
+				Type:        strList,
+				Description: "first_trimester, second_trimester, third_trimester or postpartum",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Eligibility).Pregnancy, nil
+				},
+			},
+			"incomeFPL": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Income at or below this percent of the federal poverty level",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if fpl := p.Source.(store.Eligibility).IncomeFPL; fpl > 0 {
+						return fpl, nil
+					}
+					return nil, nil
+				},
+			},
+			"residency": &graphql.Field{
+				Type:        strList,
+				Description: "Where the family has to live, like county:Sedgwick",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Eligibility).Residency, nil
+				},
+			},
+			"insurance": &graphql.Field{
+				Type:        strList,
+				Description: "medicaid, chip, private or uninsured, the family needs one",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Eligibility).Insurance, nil
+				},
+			},
+			"notes": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Eligibility).Notes, nil
+				},
+			},
+			"requirements": &graphql.Field{
+				Type:        strList,
+				Description: "For people to read, like \"Pregnant or children under 1 year\"",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Eligibility).Requirements(), nil
+				},
+			},
+		},
+	})
+	resourceType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Resource",
+		Description: "A resource available to families",
+		Fields: graphql.Fields{
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.String},
+			"url":         &graphql.Field{Type: graphql.String},
+			"org":         &graphql.Field{Type: graphql.String},
+			"address":     &graphql.Field{Type: graphql.String},
+			"email":       &graphql.Field{Type: graphql.String},
+			"phone":       &graphql.Field{Type: graphql.String},
+			"hours":       &graphql.Field{Type: graphql.String},
+			"fees":        &graphql.Field{Type: strList},
+			"languages":   &graphql.Field{Type: strList},
+			"tags":        &graphql.Field{Type: strList},
+			"serviceAreas": &graphql.Field{
+				Type:        strList,
+				Description: "Where the resource serves, like county:Sedgwick",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Resource).ServiceAreas, nil
+				},
+			},
+			"cost": &graphql.Field{
+				Type: costType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Resource).Cost, nil
+				},
+			},
+			"eligibility": &graphql.Field{
+				Type: eligibilityType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Resource).Eligibility, nil
+				},
+			},
+			"schedule": &graphql.Field{
+				Type:        graphql.String,
+				Description: "When it's open, one rule to a line, like \"Mon-Fri 8am-5pm\"",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Resource).Schedule.String(), nil
+				},
+			},
+			"openNow": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Null when nobody has said when it's open",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					st := p.Source.(store.Resource).Schedule.Status(time.Now())
+					if !st.Known {
+						return nil, nil
+					}
+					return st.Open, nil
+				},
+			},
+			"hoursStatus": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Like \"Open now until 5pm\" or \"Opens tomorrow at 8am\"",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(store.Resource).Schedule.Status(time.Now()).Label, nil
+				},
+			},
+			"latitude":  &graphql.Field{Type: graphql.Float, Resolve: gqlCoordinate},
+			"longitude": &graphql.Field{Type: graphql.Float, Resolve: gqlCoordinate},
+			"organization": &graphql.Field{
+				Type: orgType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					res := p.Source.(store.Resource)
+					if res.OrgID != "" {
+						if org, ok := gqlLoad(p).Organization(res.OrgID); ok {
+							return gqlOrgFrom(org), nil
+						}
+					}
+					if res.Org == "" {
+						return nil, nil
+					}
+					return gqlOrganization{Name: res.Org}, nil
+				},
+			},
+			"locations": &graphql.Field{
+				Type:        graphql.NewList(locationType),
+				Description: "Where it's offered, the address is the first one's",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					ret := make([]store.Location, 0, 0)
+					for _, id := range p.Source.(store.Resource).LocationIDs {
+						if loc, ok := gqlLoad(p).Location(id); ok {
+							ret = append(ret, loc)
+						}
+					}
+					return ret, nil
+				},
+			},
+		},
+	})
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ResourceEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: resourceType},
+		},
+	})
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "ResourceConnection",
+		Fields: graphql.Fields{
+			"totalCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"edges":      &graphql.Field{Type: graphql.NewList(edgeType)},
+			"pageInfo":   &graphql.Field{Type: pageInfoType},
+		},
+	})
+
+	// Arguments for anything that returns a ResourceConnection
+	pageArgs := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Page size"},
+		"after": &graphql.ArgumentConfig{Type: graphql.String, Description: "Cursor to start after"},
+	}
+	filterArgs := graphql.FieldConfigArgument{
+		"query":     &graphql.ArgumentConfig{Type: graphql.String, Description: "Text to look for"},
+		"tags":      &graphql.ArgumentConfig{Type: strList, Description: "Resources must have all of these tags"},
+		"org":       &graphql.ArgumentConfig{Type: graphql.String},
+		"languages": &graphql.ArgumentConfig{Type: strList, Description: "Resources must offer one of these languages"},
+		"fees":      &graphql.ArgumentConfig{Type: strList, Description: "Resources must have one of these fees"},
+		"near":      &graphql.ArgumentConfig{Type: graphql.String, Description: "A ZIP code or \"lat,lng\", used with within"},
+		"within":    &graphql.ArgumentConfig{Type: graphql.Float, Description: "Resources must be this many miles from near"},
+		"serves":    &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Leave out resources that don't serve near"},
+		"cost":      &graphql.ArgumentConfig{Type: strList, Description: "Resources must have one of these, like \"free\" or \"Accepts CHIP\""},
+		"openNow":   &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Only resources that are open now"},
+	}
+	for k, v := range pageArgs {
+		filterArgs[k] = v
+	}
+
+	tagType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	tagType.AddFieldConfig("count", &graphql.Field{Type: graphql.NewNonNull(graphql.Int)})
+	tagType.AddFieldConfig("resources", &graphql.Field{
+		Type: connectionType,
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return s.gqlResolveConnection(p, search.Filter{Tags: []string{p.Source.(gqlTag).Name}})
+		},
+	})
+	orgType.AddFieldConfig("id", &graphql.Field{Type: graphql.String, Description: "Null for an org name that isn't an organization yet", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		if id := p.Source.(gqlOrganization).ID; id != "" {
+			return id, nil
+		}
+		return nil, nil
+	}})
+	orgType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	for _, fld := range []string{"description", "website", "contactName", "contactEmail", "contactPhone", "logo"} {
+		orgType.AddFieldConfig(fld, &graphql.Field{Type: graphql.String})
+	}
+	orgType.AddFieldConfig("locations", &graphql.Field{Type: graphql.NewList(locationType)})
+	orgType.AddFieldConfig("resources", &graphql.Field{
+		Type: connectionType,
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			org := p.Source.(gqlOrganization)
+			resources, err := gqlLoad(p).Resources()
+			if err != nil {
+				return nil, err
+			}
+			// Linked resources go by the org's ID, so same-named and
+			// renamed organizations keep their own programs
+			runs := store.Organization{ID: org.ID, Name: org.Name}
+			programs := make([]store.Resource, 0, 0)
+			for i := range resources {
+				if runs.Runs(resources[i]) {
+					programs = append(programs, resources[i])
+				}
+			}
+			return gqlPage(p, programs)
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"resource": &graphql.Field{
+				Type: resourceType,
+				Args: graphql.FieldConfigArgument{
+					"title": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if res, ok := gqlLoad(p).Resource(p.Args["title"].(string)); ok {
+						return res, nil
+					}
+					return nil, nil
+				},
+			},
+			"resources": &graphql.Field{
+				Type: connectionType,
+				Args: filterArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var f search.Filter
+					f.Query, _ = p.Args["query"].(string)
+					f.Org, _ = p.Args["org"].(string)
+					f.Tags = gqlStringList(p.Args["tags"])
+					f.Languages = gqlStringList(p.Args["languages"])
+					f.Fees = gqlStringList(p.Args["fees"])
+					for _, c := range gqlStringList(p.Args["cost"]) {
+						f.Cost = append(f.Cost, store.CostLabel(c))
+					}
+					f.Near, _ = p.Args["near"].(string)
+					f.Within, _ = p.Args["within"].(float64)
+					f.Serves, _ = p.Args["serves"].(bool)
+					if open, _ := p.Args["openNow"].(bool); open {
+						f.Hours = []string{search.HoursOpenNow}
+					}
+					if loc := s.locate(&f); loc.Error != "" {
+						return nil, fmt.Errorf("%s", loc.Error)
+					}
+					return s.gqlResolveConnection(p, f)
+				},
+			},
+			"tags": &graphql.Field{
+				Type: graphql.NewList(tagType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return gqlLoad(p).Tags()
+				},
+			},
+			"organizations": &graphql.Field{
+				Type: graphql.NewList(orgType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return gqlLoad(p).AllOrganizations()
+				},
+			},
+			"organization": &graphql.Field{
+				Type: orgType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.String},
+					"name": &graphql.ArgumentConfig{Type: graphql.String, Description: "For an org name that isn't an organization yet"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if id, ok := p.Args["id"].(string); ok && id != "" {
+						if org, ok := gqlLoad(p).Organization(id); ok {
+							return gqlOrgFrom(org), nil
+						}
+						return nil, nil
+					}
+					name, _ := p.Args["name"].(string)
+					if name == "" {
+						return nil, fmt.Errorf("organization needs an id or a name")
+					}
+					orgs, err := gqlLoad(p).Organizations()
+					if err != nil {
+						return nil, err
+					}
+					for _, org := range orgs {
+						if strings.EqualFold(org.Name, name) {
+							return gqlOrgFrom(org), nil
+						}
+					}
+					return gqlOrganization{Name: name}, nil
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// gqlResolveConnection
+// Filter all resources with 'f' and return the page asked for in p.Args
+func (s *Server) gqlResolveConnection(p graphql.ResolveParams, f search.Filter) (interface{}, error) {
+	resources, err := gqlLoad(p).Resources()
+	if err != nil {
+		return nil, err
+	}
+	return gqlPage(p, f.Apply(resources))
+}
+
+// gqlPage
+// Returns the page of 'matches' asked for in p.Args, sorted by title
+func gqlPage(p graphql.ResolveParams, matches []store.Resource) (interface{}, error) {
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Title < matches[j].Title })
+
+	first, ok := p.Args["first"].(int)
+	if !ok || first <= 0 {
+		first = gqlDefaultPageSize
+	}
+	if first > gqlMaxPageSize {
+		first = gqlMaxPageSize
+	}
+	start := 0
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		afterTitle, err := gqlDecodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		start = sort.Search(len(matches), func(i int) bool { return matches[i].Title > afterTitle })
+	}
+
+	conn := gqlConnection{TotalCount: len(matches), Edges: make([]gqlEdge, 0, first)}
+	for i := start; i < len(matches) && len(conn.Edges) < first; i++ {
+		conn.Edges = append(conn.Edges, gqlEdge{Cursor: gqlEncodeCursor(matches[i].Title), Node: matches[i]})
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	conn.PageInfo.HasNextPage = start+len(conn.Edges) < len(matches)
+	return conn, nil
+}
+
+func gqlEncodeCursor(title string) string {
+	return base64.URLEncoding.EncodeToString([]byte("resource:" + title))
+}
+
+func gqlDecodeCursor(cursor string) (string, error) {
+	b, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), "resource:") {
+		return "", fmt.Errorf("Invalid cursor: %s", cursor)
+	}
+	return strings.TrimPrefix(string(b), "resource:"), nil
+}
+
+func gqlStringList(arg interface{}) []string {
+	ret := make([]string, 0, 0)
+	if list, ok := arg.([]interface{}); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+	}
+	return ret
+}
+
+// handleGraphQL
+// Run a GraphQL query, sent either as a POSTed JSON body or in the query string
+func (s *Server) handleGraphQL(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("GraphQL Request: %s %s\n", req.Method, req.URL))
+	var body struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if req.Method == "POST" {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+			return
+		}
+	} else {
+		v := req.URL.Query()
+		body.Query = v.Get("query")
+		body.OperationName = v.Get("operationName")
+		if vars := v.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &body.Variables); err != nil {
+				s.writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+				return
+			}
+		}
+	}
+	loader := &gqlLoader{store: s.Store}
+	sizes, err := loader.ListSizes()
+	if err == nil {
+		err = gqlCheckLimits(body.Query, sizes)
+	}
+	if err != nil {
+		s.writeJSON(w, http.StatusOK, graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(err.Error())}})
+		return
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         s.gqlSchema,
+		RequestString:  body.Query,
+		OperationName:  body.OperationName,
+		VariableValues: body.Variables,
+		Context:        context.WithValue(req.Context(), gqlLoaderKey{}, loader),
+	})
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleGraphiQL
+// Show the GraphiQL explorer, only available in --dev mode
+func (s *Server) handleGraphiQL(w http.ResponseWriter, req *http.Request) {
+	if !s.DevMode {
+		http.NotFound(w, req)
+		return
+	}
+	if err := s.OutputTemplate("graphiql.html", &SiteData{Title: s.Title, BasePath: s.BasePath}, w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// gqlCoordinate
+// Resolves latitude and longitude, null for resources that haven't been located
+func gqlCoordinate(p graphql.ResolveParams) (interface{}, error) {
+	res := p.Source.(store.Resource)
+	if res.Latitude == 0 && res.Longitude == 0 {
+		return nil, nil
+	}
+	if p.Info.FieldName == "latitude" {
+		return res.Latitude, nil
+	}
+	return res.Longitude, nil
+}
+
+// gqlOrgFrom
+// Returns the GraphQL view of a stored organization
+func gqlOrgFrom(org store.Organization) gqlOrganization {
+	return gqlOrganization{
+		ID:           org.ID,
+		Name:         org.Name,
+		Description:  org.Description,
+		Website:      org.Website,
+		ContactName:  org.ContactName,
+		ContactEmail: org.ContactEmail,
+		ContactPhone: org.ContactPhone,
+		Logo:         org.Logo,
+		Locations:    org.Locations,
+	}
+}
+
+// gqlCostOption
+// A Boolean field for one of the Cost Options
+func gqlCostOption(key string) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.Boolean),
+		Description: store.CostLabel(key),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(store.Cost).Has(key), nil
+		},
+	}
+}
+
+// gqlAgeRange
+// Returns null for ranges that don't limit anything
+func gqlAgeRange(r store.AgeRange) interface{} {
+	if r.IsEmpty() {
+		return nil
+	}
+	return r
+}
+
+// gqlLoaderKey is the context key for a request's gqlLoader
+type gqlLoaderKey struct{}
+
+// gqlLoader reads the resources and organizations once for a GraphQL
+// request, so nested resolvers don't go back to the database for every node
+type gqlLoader struct {
+	store *store.Store
+
+	mu        sync.Mutex
+	resources []store.Resource
+	resErr    error
+	resLoaded bool
+	orgs      []store.Organization
+	orgErr    error
+	orgLoaded bool
+}
+
+// gqlLoad
+// Returns the loader for the request 'p' is part of
+func gqlLoad(p graphql.ResolveParams) *gqlLoader {
+	if l, ok := p.Context.Value(gqlLoaderKey{}).(*gqlLoader); ok {
+		return l
+	}
+	return &gqlLoader{}
+}
+
+// Resources
+// Returns every resource, reading them the first time it's called
+func (l *gqlLoader) Resources() ([]store.Resource, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.resLoaded {
+		if l.store == nil {
+			return nil, fmt.Errorf("No store for this request")
+		}
+		l.resources, l.resErr = l.store.Resources()
+		l.resLoaded = true
+	}
+	return l.resources, l.resErr
+}
+
+// Organizations
+// Returns every organization, reading them the first time it's called
+func (l *gqlLoader) Organizations() ([]store.Organization, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.orgLoaded {
+		if l.store == nil {
+			return nil, fmt.Errorf("No store for this request")
+		}
+		l.orgs, l.orgErr = l.store.Organizations()
+		l.orgLoaded = true
+	}
+	return l.orgs, l.orgErr
+}
+
+// Resource
+// Returns the resource titled 'title', and whether there is one
+func (l *gqlLoader) Resource(title string) (store.Resource, bool) {
+	resources, _ := l.Resources()
+	for i := range resources {
+		if resources[i].Title == title {
+			return resources[i], true
+		}
+	}
+	return store.Resource{}, false
+}
+
+// Organization
+// Returns the organization with 'id', and whether there is one
+func (l *gqlLoader) Organization(id string) (store.Organization, bool) {
+	orgs, _ := l.Organizations()
+	for i := range orgs {
+		if orgs[i].ID == id {
+			return orgs[i], true
+		}
+	}
+	return store.Organization{}, false
+}
+
+// Location
+// Returns the location with 'id', and whether there is one
+func (l *gqlLoader) Location(id string) (store.Location, bool) {
+	orgs, _ := l.Organizations()
+	for i := range orgs {
+		for _, loc := range orgs[i].Locations {
+			if loc.ID == id {
+				return loc, true
+			}
+		}
+	}
+	return store.Location{}, false
+}
+
+// Tags
+// Returns every tag with its resource count
+func (l *gqlLoader) Tags() ([]gqlTag, error) {
+	resources, err := l.Resources()
+	if err != nil {
+		return nil, err
+	}
+	counts := search.CountTags(resources)
+	ret := make([]gqlTag, 0, len(counts))
+	for i := range counts {
+		ret = append(ret, gqlTag{Name: counts[i].Name, Count: counts[i].Count})
+	}
+	return ret, nil
+}
+
+// AllOrganizations
+// Returns every organization, and the org names of resources that aren't
+// linked to one, sorted by name
+func (l *gqlLoader) AllOrganizations() ([]gqlOrganization, error) {
+	orgs, err := l.Organizations()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := l.Resources()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	ret := make([]gqlOrganization, 0, len(orgs))
+	for _, org := range orgs {
+		seen[org.Name] = true
+		ret = append(ret, gqlOrgFrom(org))
+	}
+	for i := range resources {
+		if org := resources[i].Org; org != "" && !seen[org] {
+			seen[org] = true
+			ret = append(ret, gqlOrganization{Name: org})
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret, nil
+}
+
+// ListSizes
+// Returns how long the lists that aren't paged are, by field name, for
+// gqlCheckLimits. Locations is the most that any one org or resource has.
+func (l *gqlLoader) ListSizes() (map[string]int, error) {
+	tags, err := l.Tags()
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := l.AllOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	resources, _ := l.Resources()
+	locs := 0
+	for i := range orgs {
+		if len(orgs[i].Locations) > locs {
+			locs = len(orgs[i].Locations)
+		}
+	}
+	for i := range resources {
+		if len(resources[i].LocationIDs) > locs {
+			locs = len(resources[i].LocationIDs)
+		}
+	}
+	return map[string]int{"tags": len(tags), "organizations": len(orgs), "locations": locs}, nil
+}
+
+// gqlCheckLimits
+// Returns an error if 'query' nests deeper than gqlMaxDepth or could
+// resolve more than gqlMaxComplexity fields, with 'sizes' from ListSizes.
+// Queries that don't parse are left for graphql.Do to report.
+func gqlCheckLimits(query string, sizes map[string]int) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+	frags := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok && frag.Name != nil {
+			frags[frag.Name.Value] = frag
+		}
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		depth, cost := gqlMeasure(op.SelectionSet, frags, sizes, 1, 1, map[string]bool{})
+		if depth > gqlMaxDepth {
+			return fmt.Errorf("Query is nested %d deep, the most allowed is %d", depth, gqlMaxDepth)
+		}
+		if cost > gqlMaxComplexity {
+			return fmt.Errorf("Query could resolve %d fields, the most allowed is %d", cost, gqlMaxComplexity)
+		}
+	}
+	return nil
+}
+
+// gqlMeasure
+// Returns how deep 'set' nests and how many fields it could resolve, when
+// it's at 'depth' and repeated 'mult' times. 'seen' holds the fragments
+// already being expanded, so a fragment that spreads itself stops.
+func gqlMeasure(set *ast.SelectionSet, frags map[string]*ast.FragmentDefinition, sizes map[string]int, depth, mult int, seen map[string]bool) (int, int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+	maxDepth, cost := depth, 0
+	for _, sel := range set.Selections {
+		var d, c int
+		switch n := sel.(type) {
+		case *ast.Field:
+			cost += mult
+			if n.SelectionSet == nil {
+				continue
+			}
+			d, c = gqlMeasure(n.SelectionSet, frags, sizes, depth+1, mult*gqlMultiplier(n, sizes), seen)
+		case *ast.InlineFragment:
+			d, c = gqlMeasure(n.SelectionSet, frags, sizes, depth, mult, seen)
+		case *ast.FragmentSpread:
+			if n.Name == nil || seen[n.Name.Value] || frags[n.Name.Value] == nil {
+				continue
+			}
+			seen[n.Name.Value] = true
+			d, c = gqlMeasure(frags[n.Name.Value].SelectionSet, frags, sizes, depth, mult, seen)
+			delete(seen, n.Name.Value)
+		}
+		if d > maxDepth {
+			maxDepth = d
+		}
+		cost += c
+	}
+	return maxDepth, cost
+}
+
+// gqlMultiplier
+// Returns how many nodes a field could return: a resource connection's
+// 'first' argument, the size of a list that isn't paged, or 1 for any other
+// field. A variable is counted as the largest page.
+func gqlMultiplier(f *ast.Field, sizes map[string]int) int {
+	if f.Name == nil {
+		return 1
+	}
+	if n, ok := sizes[f.Name.Value]; ok {
+		if n < 1 {
+			return 1
+		}
+		return n
+	}
+	if f.Name.Value != "resources" {
+		return 1
+	}
+	for _, arg := range f.Arguments {
+		if arg.Name == nil || arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			n, err := strconv.Atoi(v.Value)
+			if err != nil || n <= 0 {
+				return gqlDefaultPageSize
+			}
+			if n > gqlMaxPageSize {
+				return gqlMaxPageSize
+			}
+			return n
+		case *ast.Variable:
+			return gqlMaxPageSize
+		}
+	}
+	return gqlDefaultPageSize
+}
@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/openwichita/infant-info/store"
+)
+
+func TestGraphQLLimits(t *testing.T) {
+	sizes := map[string]int{"tags": 60, "organizations": 30, "locations": 2}
+	tests := []struct {
+		query string
+		ok    bool
+	}{
+		{`{resources(first:100){edges{node{title}}}}`, true},
+		{`{tags{name count}}`, true},
+		// 60 tags of 100 resources
+		{`{tags{resources(first:100){edges{node{title}}}}}`, false},
+		{`{tags{resources(first:10){edges{node{title}}}}}`, true},
+		{`{organizations{name locations{id address phones}}}`, true},
+		{`{organizations{resources(first:$n){edges{node{title locations{id}}}}}}`, false},
+		{`{organizations{resources(first:1){edges{node{title locations{id}}}}}}`, true},
+		{`{a{b{c{d{e{f{g{h{i{j{k{l{m}}}}}}}}}}}}}`, false},
+		{`{...f} fragment f on Query {tags{resources(first:100){edges{node{title}}}}}`, false},
+	}
+	for _, tt := range tests {
+		if err := gqlCheckLimits(tt.query, sizes); (err == nil) != tt.ok {
+			t.Errorf("gqlCheckLimits(%s) = %v", tt.query, err)
+		}
+	}
+}
+
+// TestGraphQLOrgResources
+// An organization's resources are the ones linked to it, not the ones that
+// happen to have its name
+func TestGraphQLOrgResources(t *testing.T) {
+	s := newTestServer(t)
+	var ids []string
+	for i := 0; i < 2; i++ {
+		org, err := s.Store.SaveOrganization(store.Organization{Name: "Health Department"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, org.ID)
+	}
+	if ids[0] == ids[1] {
+		t.Fatalf("Both organizations are %s", ids[0])
+	}
+	for i, title := range []string{"Immunizations", "WIC"} {
+		if err := s.Store.Save("", store.Resource{Title: title, URL: "https://example.org", OrgID: ids[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, want := range []string{"Immunizations", "WIC"} {
+		query := fmt.Sprintf(`{organization(id:%q){resources{edges{node{title}}}}}`, ids[i])
+		got := gqlTitles(t, s, query)
+		if strings.Join(got, ",") != want {
+			t.Errorf("%s has %v, wanted %s", ids[i], got, want)
+		}
+	}
+}
+
+// gqlTitles
+// Run 'query' and return every title in the result
+func gqlTitles(t *testing.T, s *Server, query string) []string {
+	t.Helper()
+	result := graphql.Do(graphql.Params{
+		Schema:        s.gqlSchema,
+		RequestString: query,
+		Context:       context.WithValue(context.Background(), gqlLoaderKey{}, &gqlLoader{store: s.Store}),
+	})
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+	var data struct {
+		Organization struct {
+			Resources gqlConnection
+		}
+	}
+	b, _ := json.Marshal(result.Data)
+	if err := json.Unmarshal(b, &data); err != nil {
+		t.Fatal(err)
+	}
+	var ret []string
+	for _, e := range data.Organization.Resources.Edges {
+		ret = append(ret, e.Node.Title)
+	}
+	return ret
+}
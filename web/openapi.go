@@ -0,0 +1,243 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// The OpenAPI 3 description of the JSON API is built from apiRoutes, with
+// schemas generated from the Go types the handlers read and write.
+
+// openAPISchemaNames are the types that get their own entry in
+// components/schemas and are referenced by name everywhere else
+var openAPISchemaNames = map[reflect.Type]string{
+	reflect.TypeOf(store.Resource{}):     "Resource",
+	reflect.TypeOf(store.Organization{}): "Organization",
+	reflect.TypeOf(store.Location{}):     "Location",
+	reflect.TypeOf(apiError{}):           "Error",
+	reflect.TypeOf(store.Change{}):       "Change",
+}
+
+type openAPIDoc map[string]interface{}
+
+// handleOpenAPI
+// Serves the OpenAPI document for everything under /api
+func (s *Server) handleOpenAPI(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	s.writeJSON(w, http.StatusOK, s.buildOpenAPI())
+}
+
+func (s *Server) buildOpenAPI() openAPIDoc {
+	schemas := make(map[string]interface{})
+	for t, name := range openAPISchemaNames {
+		schemas[name] = openAPIStructSchema(t)
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, rt := range s.apiRoutes() {
+		if paths[rt.Path] == nil {
+			paths[rt.Path] = make(map[string]interface{})
+		}
+		paths[rt.Path][strings.ToLower(rt.Method)] = openAPIOperation(rt)
+	}
+
+	return openAPIDoc{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       s.Title + " API",
+			"description": "Wichita resources for families, nurses and midwives.",
+			"version":     "1.0.0",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": s.URL("/api")},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"apiToken": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "An API token issued at " + s.URL("/admin/tokens"),
+				},
+				"apiKey": map[string]interface{}{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "X-API-Key",
+					"description": "A public API key issued at " + s.URL("/admin/apikeys"),
+				},
+			},
+		},
+	}
+}
+
+func openAPIOperation(rt apiRoute) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary":     rt.Summary,
+		"operationId": openAPIOperationID(rt),
+	}
+
+	params := make([]interface{}, 0, 0)
+	for _, p := range openAPIPathParams(rt.Path) {
+		params = append(params, map[string]interface{}{
+			"name":     p,
+			"in":       "path",
+			"required": true,
+			"schema":   map[string]interface{}{"type": "string"},
+		})
+	}
+	for _, name := range sortedKeys(rt.Query) {
+		params = append(params, map[string]interface{}{
+			"name":        name,
+			"in":          "query",
+			"description": rt.Query[name],
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	if rt.Request != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  openAPIContent(rt.Request),
+		}
+	}
+
+	responses := make(map[string]interface{})
+	success := map[string]interface{}{"description": http.StatusText(rt.Status)}
+	if rt.Response != nil {
+		success["content"] = openAPIContent(rt.Response)
+	}
+	responses[strconv.Itoa(rt.Status)] = success
+	// Every route is rate limited, which turns away bad API keys too
+	for _, st := range append(rt.Errors, http.StatusUnauthorized, http.StatusTooManyRequests) {
+		responses[strconv.Itoa(st)] = map[string]interface{}{
+			"description": http.StatusText(st),
+			"content":     openAPIContent(apiError{}),
+		}
+	}
+	if rt.Scope == "" {
+		responses[strconv.Itoa(http.StatusUnauthorized)].(map[string]interface{})["description"] = "The API key is invalid or has been revoked"
+	}
+	op["responses"] = responses
+
+	if rt.Scope != "" {
+		op["description"] = fmt.Sprintf("Requires an API token with the '%s' scope.", rt.Scope)
+		op["security"] = []interface{}{
+			map[string]interface{}{"apiToken": []string{rt.Scope}},
+		}
+	} else {
+		// An API key is optional, it only raises the rate limit
+		op["security"] = []interface{}{
+			map[string]interface{}{},
+			map[string]interface{}{"apiKey": []string{}},
+		}
+	}
+	return op
+}
+
+func openAPIContent(v interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": openAPISchema(reflect.TypeOf(v)),
+		},
+	}
+}
+
+// openAPISchema
+// Returns the schema for a Go type
+func openAPISchema(t reflect.Type) map[string]interface{} {
+	if name, ok := openAPISchemaNames[t]; ok {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return openAPISchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		// A nil slice is written as null
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem()), "nullable": true}
+	case reflect.Array:
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": openAPISchema(t.Elem()), "nullable": true}
+	case reflect.Struct:
+		return openAPIStructSchema(t)
+	}
+	return map[string]interface{}{}
+}
+
+// openAPIStructSchema
+// Returns the object schema for a struct, using its json field names
+func openAPIStructSchema(t reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		name := f.Name
+		if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		props[name] = openAPISchema(f.Type)
+	}
+	return map[string]interface{}{"type": "object", "properties": props}
+}
+
+// openAPIPathParams
+// Pull the {parameters} out of a route path
+func openAPIPathParams(path string) []string {
+	ret := make([]string, 0, 0)
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			ret = append(ret, strings.Trim(part, "{}"))
+		}
+	}
+	return ret
+}
+
+// openAPIOperationID
+// Turns a route into an operationId like 'getResourcesTitle'
+func openAPIOperationID(rt apiRoute) string {
+	id := strings.ToLower(rt.Method)
+	parts := strings.FieldsFunc(rt.Path, func(c rune) bool {
+		return c == '/' || c == '{' || c == '}' || c == '-'
+	})
+	for _, p := range parts {
+		id += strings.ToUpper(p[:1]) + p[1:]
+	}
+	return id
+}
+
+func sortedKeys(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,255 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/openwichita/infant-info/hours"
+	"github.com/openwichita/infant-info/store"
+)
+
+// TestOpenAPIContract
+// Calls every operation in the served OpenAPI document and checks that the
+// route exists, the status is one the document lists and the body matches
+// the schema given for it
+func TestOpenAPIContract(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	token, err := s.Admin.CreateToken("contract", []string{store.ScopeCreate, store.ScopeUpdate, store.ScopeDelete})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := s.Store.SaveOrganization(store.Organization{Name: "Health Department"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := s.Store.SaveLocation(store.Location{
+		OrgID:    org.ID,
+		Address:  "1900 E 9th St N, Wichita, KS 67214",
+		Phones:   []string{"316-555-0100"},
+		Schedule: hours.Schedule{Weekly: []hours.Period{{Day: "mon", Open: "08:00", Close: "17:00"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed := store.Resource{
+		Title:       "Healthy Babies",
+		Description: "Home visits for new families",
+		URL:         "https://example.org/healthy-babies",
+		Tags:        []string{"home visiting"},
+		OrgID:       org.ID,
+		LocationIDs: []string{loc.ID},
+		Cost:        store.Cost{Free: true},
+		Eligibility: store.Eligibility{ChildAge: store.AgeRange{Min: 0, Max: 12}},
+	}
+	if err := s.Store.Save("", seed); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	v := openAPIValidator{doc: doc}
+
+	paths := doc["paths"].(map[string]interface{})
+	if n := openAPIOperationCount(paths); n != len(s.apiRoutes()) {
+		t.Errorf("The document has %d operations, the route table has %d", n, len(s.apiRoutes()))
+	}
+
+	// Deletes go last, so the other calls still have something to work on
+	var calls [][2]string
+	for _, path := range sortedPaths(paths) {
+		for method := range paths[path].(map[string]interface{}) {
+			calls = append(calls, [2]string{strings.ToUpper(method), path})
+		}
+	}
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i][0] != "DELETE" && calls[j][0] == "DELETE"
+	})
+
+	vars := map[string]string{"title": url.PathEscape(seed.Title), "id": org.ID}
+	expand := func(path string) string {
+		for name, val := range vars {
+			path = strings.Replace(path, "{"+name+"}", val, -1)
+		}
+		return ts.URL + "/api" + path
+	}
+
+	// Every route turns away a bad API key before it gets to the handler,
+	// so these go first and don't change anything
+	for _, call := range calls {
+		method, path := call[0], call[1]
+		op := paths[path].(map[string]interface{})[strings.ToLower(method)].(map[string]interface{})
+		t.Run("bad key "+method+" "+path, func(t *testing.T) {
+			req, _ := http.NewRequest(method, expand(path), nil)
+			req.Header.Set("X-API-Key", "not-a-key")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Got a %d", resp.StatusCode)
+			}
+			v.checkResponse(t, op, resp)
+		})
+	}
+
+	for _, call := range calls {
+		method, path := call[0], call[1]
+		op := paths[path].(map[string]interface{})[strings.ToLower(method)].(map[string]interface{})
+		t.Run(method+" "+path, func(t *testing.T) {
+			var body []byte
+			if _, ok := op["requestBody"]; ok {
+				res := seed
+				if method == "POST" {
+					res.Title = "Parents as Teachers"
+				}
+				body, _ = json.Marshal(res)
+			}
+			req, _ := http.NewRequest(method, expand(path), bytes.NewReader(body))
+			if _, ok := op["security"].([]interface{})[0].(map[string]interface{})["apiToken"]; ok {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				t.Errorf("Got a %d", resp.StatusCode)
+			}
+			v.checkResponse(t, op, resp)
+		})
+	}
+}
+
+// openAPIValidator checks JSON values against the schemas in doc
+// It only knows the parts of JSON Schema that buildOpenAPI writes.
+type openAPIValidator struct {
+	doc map[string]interface{}
+}
+
+// checkResponse
+// Check that the operation 'op' lists the status of 'resp', and that the
+// body matches the schema it gives for it
+func (v openAPIValidator) checkResponse(t *testing.T, op map[string]interface{}, resp *http.Response) {
+	t.Helper()
+	responses := op["responses"].(map[string]interface{})
+	spec, ok := responses[strconv.Itoa(resp.StatusCode)].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Got a %d, which the document doesn't list", resp.StatusCode)
+	}
+	content, ok := spec["content"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type is %q", ct)
+	}
+	var got interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	schema := content["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	for _, err := range v.validate(got, schema, "$") {
+		t.Error(err)
+	}
+}
+
+func (v openAPIValidator) validate(val interface{}, schema map[string]interface{}, at string) []error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		target, ok := v.doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: %s isn't in the document", at, ref)}
+		}
+		return v.validate(val, target, at)
+	}
+	if val == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: is null", at)}
+	}
+	var errs []error
+	switch schema["type"] {
+	case "object":
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: %T isn't an object", at, val)}
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		extra, _ := schema["additionalProperties"].(map[string]interface{})
+		for k, fv := range obj {
+			if p, ok := props[k].(map[string]interface{}); ok {
+				errs = append(errs, v.validate(fv, p, at+"."+k)...)
+			} else if extra != nil {
+				errs = append(errs, v.validate(fv, extra, at+"."+k)...)
+			} else {
+				errs = append(errs, fmt.Errorf("%s.%s: isn't in the schema", at, k))
+			}
+		}
+	case "array":
+		list, ok := val.([]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: %T isn't an array", at, val)}
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i := range list {
+			errs = append(errs, v.validate(list[i], items, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		if _, ok := val.(string); !ok {
+			errs = append(errs, fmt.Errorf("%s: %T isn't a string", at, val))
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			errs = append(errs, fmt.Errorf("%s: %T isn't a boolean", at, val))
+		}
+	case "number":
+		if _, ok := val.(float64); !ok {
+			errs = append(errs, fmt.Errorf("%s: %T isn't a number", at, val))
+		}
+	case "integer":
+		if n, ok := val.(float64); !ok || n != float64(int64(n)) {
+			errs = append(errs, fmt.Errorf("%s: %v isn't an integer", at, val))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: the schema has no type", at))
+	}
+	return errs
+}
+
+func openAPIOperationCount(paths map[string]interface{}) int {
+	n := 0
+	for _, p := range paths {
+		n += len(p.(map[string]interface{}))
+	}
+	return n
+}
+
+func sortedPaths(paths map[string]interface{}) []string {
+	ret := make([]string, 0, len(paths))
+	for p := range paths {
+		ret = append(ret, p)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openwichita/infant-info/hours"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// orgListItem is an organization on the list of them, with how many
+// programs it runs
+type orgListItem struct {
+	store.Organization
+	Link     string
+	Programs int
+}
+
+// orgLocation is one of an organization's locations and whether it's open
+type orgLocation struct {
+	store.Location
+	Status hours.Status
+}
+
+// orgData is what orgs.html shows, either the list of organizations or
+// one of them with its locations and programs
+type orgData struct {
+	Orgs      []orgListItem
+	Org       store.Organization
+	Locations []orgLocation
+	Results   resultsData
+}
+
+// handleOrgs
+// List every organization
+func (s *Server) handleOrgs(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+	site.SubTitle = "Organizations"
+	site.SetMenuItemActive("Organizations")
+
+	orgs, err := s.Store.Organizations()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Organizations: %s\n", err))
+	}
+	resources, err := s.Store.Resources()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+	}
+	var data orgData
+	for _, org := range orgs {
+		item := orgListItem{Organization: org, Link: s.orgURL(org.ID)}
+		for _, res := range resources {
+			if org.Runs(res) {
+				item.Programs++
+			}
+		}
+		data.Orgs = append(data.Orgs, item)
+	}
+	site.TemplateData = data
+	s.ShowPage("orgs.html", site, w)
+}
+
+// handleOrg
+// Show an organization, where it is and every program it runs
+// The programs can be narrowed down with the usual facets.
+func (s *Server) handleOrg(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+	site.SetMenuItemActive("Organizations")
+	id := mux.Vars(req)["id"]
+	org, err := s.Store.Organization(id)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Organization: %s\n", err))
+		w.WriteHeader(http.StatusNotFound)
+		site.SubTitle = "Organization Not Found"
+		site.TemplateData = orgData{}
+		s.ShowPage("orgs.html", site, w)
+		return
+	}
+	site.SubTitle = org.Name
+
+	data := orgData{Org: org}
+	now := time.Now()
+	for _, loc := range org.Locations {
+		data.Locations = append(data.Locations, orgLocation{Location: loc, Status: loc.Schedule.Status(now)})
+	}
+	programs, err := s.Store.Programs(org)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+	}
+	f := search.ParseFilter(req.URL.Query())
+	matches := make([]store.Resource, 0, len(programs))
+	for i := range programs {
+		if f.Matches(programs[i]) {
+			matches = append(matches, programs[i])
+		}
+	}
+	data.Results = s.buildResults(req, "/orgs/"+url.PathEscape(org.ID), f, matches, s.Promotions("", nil))
+	site.TemplateData = data
+	s.ShowPage("orgs.html", site, w)
+}
+
+// orgURL
+// Returns the link to an organization's page
+func (s *Server) orgURL(id string) string {
+	return s.URL("/orgs/" + url.PathEscape(id))
+}
@@ -0,0 +1,316 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// Rate limiting for the public API
+// Requests with an API key (X-API-Key header or api_key parameter) get that
+// key's rate and daily quota, everyone else is limited per IP address.
+// Anonymous IP addresses are only held in memory and are never stored.
+const (
+	AnonRatePerMinute       = 30
+	DefaultKeyRatePerMinute = 120
+	DefaultKeyQuotaPerDay   = 10000
+)
+
+// tokenBucket holds up to 'capacity' tokens, refilling at 'rate' tokens per second
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// take
+// Use a token if one is available
+func (b *tokenBucket) take(now time.Time) bool {
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// untilNext
+// How long until another token is available
+func (b *tokenBucket) untilNext() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// untilFull
+// How long until the bucket is full again
+func (b *tokenBucket) untilFull() time.Duration {
+	return time.Duration((b.capacity - b.tokens) / b.rate * float64(time.Second))
+}
+
+// RateLimiter tracks the usage of every API key and anonymous address
+type RateLimiter struct {
+	sync.Mutex
+	admin   *store.AdminStore
+	keys    map[string]*store.APIKey // Cache of the keys in the db, nil when it needs loading
+	dirty   map[string]bool          // Keys with usage that hasn't been saved
+	buckets map[string]*tokenBucket
+	saving  sync.Mutex // Held while usage is written, so saves land in order
+
+	// Anonymous usage since the server started
+	AnonRequests int
+	AnonLimited  int
+}
+
+type rateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Until the bucket is full
+	RetryAfter time.Duration // Until the next request will be allowed
+	Reason     string
+}
+
+func newRateLimiter(admin *store.AdminStore) *RateLimiter {
+	return &RateLimiter{
+		admin:   admin,
+		dirty:   make(map[string]bool),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// loadKeys
+// Fill the key cache from the db, must be called with the lock held
+func (l *RateLimiter) loadKeys() error {
+	if l.keys != nil {
+		return nil
+	}
+	keys, err := l.admin.APIKeys()
+	if err != nil {
+		return err
+	}
+	l.keys = make(map[string]*store.APIKey)
+	for i := range keys {
+		l.keys[keys[i].ID] = &keys[i]
+	}
+	return nil
+}
+
+// Reset
+// Save any pending usage and drop the key cache, for when keys change
+// The lock is held until the usage is saved, otherwise a request could
+// reload the keys with their old counts in between.
+func (l *RateLimiter) Reset() error {
+	l.saving.Lock()
+	defer l.saving.Unlock()
+	l.Lock()
+	defer l.Unlock()
+	save := l.takeDirty()
+	if err := l.admin.SaveAPIKeyUsage(save); err != nil {
+		for _, k := range save {
+			l.dirty[k.ID] = true
+		}
+		return err
+	}
+	l.keys = nil
+	return nil
+}
+
+// takeIP
+// Check an anonymous request from 'ip', must be called with the lock held
+func (l *RateLimiter) takeIP(ip string, now time.Time) rateLimitResult {
+	var res rateLimitResult
+	b, ok := l.buckets["ip:"+ip]
+	if !ok {
+		b = newTokenBucket(AnonRatePerMinute, now)
+		l.buckets["ip:"+ip] = b
+	}
+	res.Allowed = b.take(now)
+	res.Limit, res.Remaining = AnonRatePerMinute, int(b.tokens)
+	res.Reset, res.RetryAfter = b.untilFull(), b.untilNext()
+	l.AnonRequests++
+	if !res.Allowed {
+		l.AnonLimited++
+		res.Reason = "Rate limit exceeded, use an API key for a higher limit"
+	}
+	return res
+}
+
+// allow
+// Check a request against the limits for 'key', or 'ip' if there is no key
+func (l *RateLimiter) allow(key, ip string, now time.Time) (rateLimitResult, error) {
+	l.Lock()
+	defer l.Unlock()
+	var res rateLimitResult
+
+	if key == "" {
+		return l.takeIP(ip, now), nil
+	}
+
+	if err := l.loadKeys(); err != nil {
+		return res, err
+	}
+	k, ok := l.keys[store.HashToken(key)]
+	if !ok || k.IsRevoked() {
+		// A bad key costs the address like any anonymous request, so
+		// guessing keys is limited too
+		if res = l.takeIP(ip, now); !res.Allowed {
+			return res, nil
+		}
+		if !ok {
+			return res, fmt.Errorf("Invalid API Key")
+		}
+		return res, fmt.Errorf("API Key has been revoked")
+	}
+	rate := k.Rate
+	if rate <= 0 {
+		rate = DefaultKeyRatePerMinute
+	}
+	b, ok := l.buckets["key:"+k.ID]
+	if !ok || b.capacity != float64(rate) {
+		b = newTokenBucket(rate, now)
+		l.buckets["key:"+k.ID] = b
+	}
+
+	today := now.Format("2006-01-02")
+	if k.Day != today {
+		k.Day, k.DayCount = today, 0
+	}
+	k.Requests++
+	k.LastUsed = now
+	l.dirty[k.ID] = true
+
+	res.Limit = rate
+	if k.Quota > 0 && k.DayCount >= k.Quota {
+		// Out of quota until tomorrow
+		y, m, d := now.Date()
+		res.RetryAfter = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+		res.Reset = res.RetryAfter
+		res.Reason = "Daily quota exceeded"
+		k.Limited++
+		return res, nil
+	}
+	if res.Allowed = b.take(now); res.Allowed {
+		k.DayCount++
+	} else {
+		k.Limited++
+		res.Reason = "Rate limit exceeded"
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset, res.RetryAfter = b.untilFull(), b.untilNext()
+	return res, nil
+}
+
+// Flush
+// Save the usage counters for any keys that have been used and drop
+// any buckets that have filled back up
+func (l *RateLimiter) Flush() error {
+	l.saving.Lock()
+	defer l.saving.Unlock()
+	l.Lock()
+	now := time.Now()
+	for k, b := range l.buckets {
+		if b.refill(now); b.tokens >= b.capacity {
+			delete(l.buckets, k)
+		}
+	}
+	save := l.takeDirty()
+	l.Unlock()
+	return l.admin.SaveAPIKeyUsage(save)
+}
+
+// takeDirty
+// Returns the keys with unsaved usage and marks them saved, must be called
+// with the lock held
+func (l *RateLimiter) takeDirty() []store.APIKey {
+	save := make([]store.APIKey, 0, len(l.dirty))
+	for id := range l.dirty {
+		if k, ok := l.keys[id]; ok {
+			save = append(save, *k)
+		}
+	}
+	l.dirty = make(map[string]bool)
+	return save
+}
+
+// Usage
+// Returns the keys with their current (unsaved) usage and the anonymous counters
+func (l *RateLimiter) Usage() ([]store.APIKey, int, int, error) {
+	l.Lock()
+	defer l.Unlock()
+	ret := make([]store.APIKey, 0, 0)
+	if err := l.loadKeys(); err != nil {
+		return ret, l.AnonRequests, l.AnonLimited, err
+	}
+	for _, k := range l.keys {
+		ret = append(ret, *k)
+	}
+	return ret, l.AnonRequests, l.AnonLimited, nil
+}
+
+// FlushAPIKeyUsage
+// Periodically save API key usage, this should be started with 'go'
+func (s *Server) FlushAPIKeyUsage(every time.Duration) {
+	for range time.Tick(every) {
+		if err := s.Limiter.Flush(); err != nil {
+			s.PrintOutput(fmt.Sprintf("Error saving API key usage: %s\n", err))
+		}
+	}
+}
+
+// rateLimited
+// Wraps a handler so that it is subject to the API rate limits
+func (s *Server) rateLimited(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get("X-API-Key")
+		if key == "" {
+			key = req.URL.Query().Get("api_key")
+		}
+		res, err := s.Limiter.allow(key, clientIP(req), time.Now())
+		if err != nil {
+			s.writeJSON(w, http.StatusUnauthorized, apiError{err.Error()})
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(res.Reset).Unix(), 10))
+		if !res.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			s.writeJSON(w, http.StatusTooManyRequests, apiError{res.Reason})
+			return
+		}
+		h(w, req)
+	}
+}
+
+// clientIP
+// The address that the request came from, without the port
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// TestResetKeepsUsage
+// Keys are reset whenever an admin changes one, which mustn't lose the
+// usage of requests made at the same time
+func TestResetKeepsUsage(t *testing.T) {
+	adm, err := store.OpenAdmin(filepath.Join(t.TempDir(), "iiAdmin.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := adm.CreateAPIKey("test", "", 100000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newRateLimiter(adm)
+	// The requests need to really run alongside the resets
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	const workers, each = 8, 1000
+	done := make(chan struct{})
+	resetting := make(chan struct{})
+	go func() {
+		defer close(resetting)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := l.Reset(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < each; j++ {
+				if res, err := l.allow(key, "127.0.0.1", time.Now()); err != nil || !res.Allowed {
+					t.Errorf("Request wasn't allowed: %v %s", err, res.Reason)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	<-resetting
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := adm.APIKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Requests != workers*each {
+		t.Errorf("Saved %+v, wanted %d requests", keys, workers*each)
+	}
+}
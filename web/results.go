@@ -0,0 +1,230 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/hours"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+	"github.com/openwichita/infant-info/transit"
+)
+
+// How many resources are shown on each page of results
+const resultsPageSize = 20
+
+// Bus routes are shown for stops within walking distance, in miles
+const (
+	transitWalkMiles = 0.5
+	transitRoutes    = 3
+)
+
+type facetLink struct {
+	Value    string
+	Count    int
+	Selected bool
+	Link     string // Link to the results with this value toggled
+}
+
+type facetGroup struct {
+	Name   string
+	Values []facetLink
+}
+
+// resultItem is a resource in the results and the link to follow it
+type resultItem struct {
+	store.Resource
+	Link     string
+	OrgLink  string // The organization's page, when it has one
+	Pinned   bool
+	Miles    float64 // From where the family is, when HasMiles
+	HasMiles bool
+
+	Areas     []string // Where it serves, for people to read
+	OutOfArea bool     // It doesn't serve where the family is
+
+	Transit []transit.RouteStop // Nearest stop on each route close by
+	Status  hours.Status        // Open now, or when it opens next
+}
+
+// resultsData is what partial-results.html shows, a page of resources
+// with the facets for narrowing them down
+type resultsData struct {
+	BasePath  string
+	Resources []resultItem
+	Total     int
+	Page      int
+	Pages     int
+	PrevLink  string
+	NextLink  string
+	Facets    []facetGroup
+	ClearLink string // Link to the results without any facets, if any are set
+}
+
+// buildResults
+// Rank 'matches' with 'promo' and pick the page asked for in 'req'. Links go
+// to 'path' with the filter 'f' in the query. Facets named in 'skip' are left out.
+func (s *Server) buildResults(req *http.Request, path string, f search.Filter, matches []store.Resource, promo search.Promotions, skip ...string) resultsData {
+	promo.Sort(matches, f)
+	ret := resultsData{
+		BasePath: s.BasePath,
+		Total:    len(matches),
+		Page:     1,
+		Pages:    (len(matches) + resultsPageSize - 1) / resultsPageSize,
+	}
+	if ret.Pages == 0 {
+		ret.Pages = 1
+	}
+	if pg, err := strconv.Atoi(req.URL.Query().Get("page")); err == nil && pg > 1 {
+		ret.Page = pg
+	}
+	if ret.Page > ret.Pages {
+		ret.Page = ret.Pages
+	}
+	start := (ret.Page - 1) * resultsPageSize
+	end := start + resultsPageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	for _, res := range matches[start:end] {
+		item := resultItem{
+			Resource: res,
+			Link:     s.resultURL(res.Title, f.Query),
+			Pinned:   promo.IsPinned(res.Title),
+		}
+		if res.OrgID != "" {
+			item.OrgLink = s.orgURL(res.OrgID)
+		}
+		item.Miles, item.HasMiles = f.Distance(res)
+		item.OutOfArea = f.OutOfArea(res)
+		item.Status = res.Schedule.Status(time.Now())
+		if res.Latitude != 0 || res.Longitude != 0 {
+			item.Transit = s.Transit.Nearby(geo.Point{Lat: res.Latitude, Lng: res.Longitude}, transitWalkMiles, transitRoutes)
+		}
+		for _, a := range res.ServiceAreas {
+			if area, err := geo.ParseServiceArea(a); err == nil {
+				item.Areas = append(item.Areas, area.Label())
+			}
+		}
+		ret.Resources = append(ret.Resources, item)
+	}
+	vals := f.Values()
+	if ret.Page > 1 {
+		ret.PrevLink = s.resultsURL(path, vals, ret.Page-1)
+	}
+	if ret.Page < ret.Pages {
+		ret.NextLink = s.resultsURL(path, vals, ret.Page+1)
+	}
+
+	clear := make(url.Values)
+	for k := range vals {
+		clear[k] = vals[k]
+	}
+	for _, fc := range f.Facets(matches) {
+		if search.ContainsFold(skip, fc.Name) || len(fc.Values) == 0 {
+			continue
+		}
+		grp := facetGroup{Name: fc.Name}
+		for _, fv := range fc.Values {
+			grp.Values = append(grp.Values, facetLink{
+				Value:    fv.Value,
+				Count:    fv.Count,
+				Selected: fv.Selected,
+				Link:     s.resultsURL(path, toggleValue(vals, fc.Param, fv.Value), 1),
+			})
+		}
+		ret.Facets = append(ret.Facets, grp)
+		clear.Del(fc.Param)
+	}
+	if len(clear) != len(vals) {
+		ret.ClearLink = s.resultsURL(path, clear, 1)
+	}
+	return ret
+}
+
+// resultsURL
+// Returns the link to page 'page' of 'path' with the query 'vals'
+func (s *Server) resultsURL(path string, vals url.Values, page int) string {
+	v := make(url.Values)
+	for k := range vals {
+		v[k] = vals[k]
+	}
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	if len(v) == 0 {
+		return s.URL(path)
+	}
+	return s.URL(path) + "?" + v.Encode()
+}
+
+// toggleValue
+// Returns a copy of 'vals' with 'val' removed from 'param' if it is there
+// (ignoring case), or added if it isn't
+func toggleValue(vals url.Values, param, val string) url.Values {
+	ret := make(url.Values)
+	for k := range vals {
+		if k != param {
+			ret[k] = vals[k]
+		}
+	}
+	found := false
+	for _, v := range vals[param] {
+		if strings.EqualFold(v, val) {
+			found = true
+		} else {
+			ret.Add(param, v)
+		}
+	}
+	if !found {
+		ret.Add(param, val)
+	}
+	return ret
+}
+
+// Promotions
+// Returns the pins and boosts for a search for 'query' or any of 'tags'
+func (s *Server) Promotions(query string, tags []string) search.Promotions {
+	pins, err := s.Admin.Pins()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Pins: %s\n", err))
+	}
+	return search.NewPromotions(pins, query, tags, time.Now())
+}
+
+// The distances offered for narrowing down results, in miles
+var withinOptions = []string{"2", "5", "10", "25"}
+
+// nearData is what partial-near.html shows, the inputs for where the
+// family is and how far they can go
+type nearData struct {
+	Near    string
+	Within  string
+	Serves  bool
+	Options []string
+	Error   string // Why Near couldn't be found
+}
+
+// locate
+// Set the filter's Origin from where it says the family is
+func (s *Server) locate(f *search.Filter) nearData {
+	ret := nearData{Near: f.Near, Serves: f.Serves, Options: withinOptions}
+	if f.Within > 0 {
+		ret.Within = strconv.FormatFloat(f.Within, 'f', -1, 64)
+	}
+	if f.Near == "" {
+		return ret
+	}
+	place, err := s.Geo.Locate(f.Near)
+	if err != nil {
+		ret.Error = err.Error()
+		return ret
+	}
+	f.Origin = &place
+	return ret
+}
@@ -0,0 +1,235 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// The screener asks a family a few questions, a step at a time, and lists
+// the resources they may be able to use. Answers are posted from step to
+// step in hidden fields and never saved or logged, so nothing personal is
+// kept on the server.
+
+// screenerStep is one page of questions, with the form fields it asks for
+type screenerStep struct {
+	Title  string
+	Fields []string
+}
+
+var screenerSteps = []screenerStep{
+	{"Pregnancy and baby", []string{"due", "birth", "parent_age"}},
+	{"Where you live", []string{"zip"}},
+	{"Insurance", []string{"insurance"}},
+	{"Household", []string{"household", "income"}},
+	{"Language", []string{"language"}},
+}
+
+// dateInputFormat is how date inputs post their value, used by the screener
+// and the timeline
+const dateInputFormat = "2006-01-02"
+
+// screenerResult is a resource the family may be able to use, and why
+type screenerResult struct {
+	store.Resource
+	Link     string
+	Matches  []string
+	Unknown  []string
+	Cautions []string
+	Miles    float64
+	HasMiles bool
+}
+
+// screenerProgress is a step in the list along the top of the screener
+type screenerProgress struct {
+	Title  string
+	Active bool
+}
+
+// screenerData is what screener.html shows
+type screenerData struct {
+	Step     int // 1 to len(screenerSteps), or one more when Done
+	Progress []screenerProgress
+	Title    string
+	Done     bool
+	Last     bool // The last step of questions
+	Next     int
+	Back     int               // 0 on the first step
+	Finish   int               // The step number of the results
+	Values   map[string]string // The answers asked for on this step
+	Hidden   url.Values        // The answers from the other steps
+	Error    string
+
+	Languages         []string
+	InsuranceStatuses []store.EligibilityOption
+
+	Summary []string
+	Results []screenerResult
+}
+
+// handleScreener
+// Ask the screener questions, or show what fits the answers
+// Only posted answers are read, so they never end up in a URL.
+func (s *Server) handleScreener(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+	// Answers can be personal, don't let the browser keep them either
+	w.Header().Set("Cache-Control", "no-store")
+	site.SubTitle = "Find Programs"
+	site.SetMenuItemActive("Screener")
+
+	req.ParseForm()
+	form := req.PostForm
+	answers, errs := s.parseAnswers(form)
+
+	step, _ := strconv.Atoi(form.Get("step"))
+	from, _ := strconv.Atoi(form.Get("from"))
+	if step < 1 {
+		step = 1
+	}
+	if step > len(screenerSteps)+1 {
+		step = len(screenerSteps) + 1
+	}
+	var data screenerData
+	// Don't go on until the answers so far make sense
+	for i := 1; i < step && i <= len(screenerSteps); i++ {
+		if i < from {
+			continue
+		}
+		for _, fld := range screenerSteps[i-1].Fields {
+			if err, ok := errs[fld]; ok && data.Error == "" {
+				step, data.Error = i, err
+			}
+		}
+	}
+	data.Step = step
+	data.Next, data.Back, data.Finish = step+1, step-1, len(screenerSteps)+1
+	data.Last = step == len(screenerSteps)
+	for i, st := range screenerSteps {
+		data.Progress = append(data.Progress, screenerProgress{Title: st.Title, Active: i+1 == step})
+	}
+	data.Progress = append(data.Progress, screenerProgress{Title: "Programs", Active: step == data.Finish})
+
+	resources, err := s.Store.Resources()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+	}
+	if step > len(screenerSteps) {
+		data.Done = true
+		data.Title = "Programs that may fit"
+		data.Summary = answers.Summary()
+		for _, sc := range answers.ScreenAll(resources) {
+			data.Results = append(data.Results, screenerResult{
+				Resource: sc.Resource,
+				Link:     s.resultURL(sc.Resource.Title, ""),
+				Matches:  sc.Matches,
+				Unknown:  sc.Unknown,
+				Cautions: sc.Cautions,
+				Miles:    sc.Miles,
+				HasMiles: sc.HasMiles,
+			})
+		}
+	} else {
+		data.Title = screenerSteps[step-1].Title
+	}
+
+	// Every answer is carried along, the ones for this step in their inputs
+	data.Values = make(map[string]string)
+	data.Hidden = make(url.Values)
+	for _, st := range screenerSteps {
+		for _, fld := range st.Fields {
+			if v := strings.TrimSpace(form.Get(fld)); v != "" {
+				data.Hidden.Set(fld, v)
+			}
+		}
+	}
+	if !data.Done {
+		for _, fld := range screenerSteps[step-1].Fields {
+			data.Values[fld] = data.Hidden.Get(fld)
+			data.Hidden.Del(fld)
+		}
+	}
+	data.Languages = resourceLanguages(resources)
+	data.InsuranceStatuses = store.InsuranceStatuses
+
+	site.TemplateData = data
+	s.ShowPage("screener.html", site, w)
+}
+
+// parseAnswers
+// Read the screener answers from the form, with an error for each field that
+// doesn't make sense. Fields with errors are left blank.
+func (s *Server) parseAnswers(form url.Values) (search.Answers, map[string]string) {
+	var a search.Answers
+	errs := make(map[string]string)
+	date := func(fld, name string) time.Time {
+		v := strings.TrimSpace(form.Get(fld))
+		if v == "" {
+			return time.Time{}
+		}
+		t, err := time.ParseInLocation(dateInputFormat, v, time.Local)
+		if err != nil {
+			errs[fld] = fmt.Sprintf("The %s should be a date, like 2026-04-30", name)
+		}
+		return t
+	}
+	number := func(fld, name string) (float64, bool) {
+		v := strings.Replace(strings.TrimPrefix(strings.TrimSpace(form.Get(fld)), "$"), ",", "", -1)
+		if v == "" {
+			return 0, false
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			errs[fld] = fmt.Sprintf("The %s should be a number", name)
+			return 0, false
+		}
+		return n, true
+	}
+
+	a.DueDate = date("due", "due date")
+	a.BirthDate = date("birth", "baby's birthday")
+	if !a.BirthDate.IsZero() && a.BirthDate.After(time.Now()) {
+		errs["birth"] = "The baby's birthday can't be in the future, use the due date"
+		a.BirthDate = time.Time{}
+	}
+	if age, ok := number("parent_age", "parent's age"); ok {
+		a.ParentAge = int(age)
+	}
+	if zip := strings.TrimSpace(form.Get("zip")); zip != "" {
+		if place, err := s.Geo.Locate(zip); err != nil {
+			errs["zip"] = err.Error()
+		} else {
+			a.Place = &place
+		}
+	}
+	a.Insurance = form.Get("insurance")
+	if size, ok := number("household", "household size"); ok {
+		a.Household = int(size)
+	}
+	a.Income, a.HasIncome = number("income", "income")
+	a.Language = strings.TrimSpace(form.Get("language"))
+	return a, errs
+}
+
+// resourceLanguages
+// Returns every language a resource offers, with English first
+func resourceLanguages(resources []store.Resource) []string {
+	seen := map[string]bool{"english": true}
+	ret := make([]string, 0, 0)
+	for _, res := range resources {
+		for _, l := range res.Languages {
+			if k := strings.ToLower(l); !seen[k] {
+				seen[k] = true
+				ret = append(ret, l)
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool { return strings.ToLower(ret[i]) < strings.ToLower(ret[j]) })
+	return append([]string{"English"}, ret...)
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// Delta sync for offline copies of the directory
+// A client starts with /api/sync/bootstrap, which returns every resource and
+// a sync token. After that it asks /api/sync?since=<token> for what changed,
+// applying upserts and deleting tombstoned titles, until has_more is false.
+const (
+	syncDefaultLimit = 200
+	syncMaxLimit     = 1000
+)
+
+type syncBootstrap struct {
+	SyncToken string           `json:"sync_token"`
+	Resources []store.Resource `json:"resources"`
+}
+
+type syncChanges struct {
+	SyncToken string         `json:"sync_token"`
+	HasMore   bool           `json:"has_more"`
+	Changes   []store.Change `json:"changes"`
+}
+
+// handleAPISyncBootstrap
+// Returns a full snapshot of the directory and the token to sync from
+func (s *Server) handleAPISyncBootstrap(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	resources, seq, err := s.Store.Snapshot()
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, syncBootstrap{
+		SyncToken: strconv.FormatUint(seq, 10),
+		Resources: resources,
+	})
+}
+
+// handleAPISync
+// Returns every change after the 'since' sync token
+func (s *Server) handleAPISync(w http.ResponseWriter, req *http.Request) {
+	s.PrintOutput(fmt.Sprintf("API Request: %s %s\n", req.Method, req.URL))
+	v := req.URL.Query()
+	var since uint64
+	if tok := v.Get("since"); tok != "" {
+		var err error
+		if since, err = strconv.ParseUint(tok, 10, 64); err != nil {
+			s.writeJSON(w, http.StatusBadRequest, apiError{fmt.Sprintf("Invalid sync token: %s", tok)})
+			return
+		}
+	}
+	limit, err := strconv.Atoi(v.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = syncDefaultLimit
+	}
+	if limit > syncMaxLimit {
+		limit = syncMaxLimit
+	}
+	changes, next, more, err := s.Store.Changes(since, limit)
+	if err != nil {
+		s.writeJSON(w, http.StatusInternalServerError, apiError{err.Error()})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, syncChanges{
+		SyncToken: strconv.FormatUint(next, 10),
+		HasMore:   more,
+		Changes:   changes,
+	})
+}
@@ -0,0 +1,158 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+)
+
+// How many resources each milestone on the timeline links to
+const timelineResources = 3
+
+// Timeline Parameters
+const (
+	paramDue  = "due"  // The due date, YYYY-MM-DD
+	paramBorn = "born" // The baby's birthday, YYYY-MM-DD
+)
+
+type timelineLink struct {
+	Title string
+	Link  string
+}
+
+// timelineEntry is a milestone on a family's timeline
+type timelineEntry struct {
+	store.Milestone
+	Date      time.Time
+	Past      bool
+	Current   bool // The first milestone that isn't past
+	Resources []timelineLink
+	More      string // Browse the milestone's tags, if it has any
+}
+
+// timelineData is what timeline.html shows
+type timelineData struct {
+	Due     string
+	Born    string
+	Error   string
+	Entries []timelineEntry
+}
+
+// handleTimeline
+// Show the milestones for a pregnancy and the baby's first year, on the
+// dates they fall for the due date or birthday asked for
+func (s *Server) handleTimeline(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+	site.SubTitle = "Your Timeline"
+	site.SetMenuItemActive("Timeline")
+
+	data := timelineData{
+		Due:  strings.TrimSpace(req.FormValue(paramDue)),
+		Born: strings.TrimSpace(req.FormValue(paramBorn)),
+	}
+	due, born, err := timelineDates(data.Due, data.Born)
+	if err != nil {
+		data.Error = err.Error()
+	} else if !due.IsZero() || !born.IsZero() {
+		milestones, err := s.Admin.Milestones()
+		if err != nil {
+			s.PrintOutput(fmt.Sprintf("Error Loading Milestones: %s\n", err))
+		}
+		resources, err := s.Store.Resources()
+		if err != nil {
+			s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+		}
+		data.Entries = s.buildTimeline(milestones, resources, due, born, time.Now())
+	}
+	site.TemplateData = data
+	s.ShowPage("timeline.html", site, w)
+}
+
+// timelineDates
+// Parse the due date and birthday, either can be blank
+func timelineDates(due, born string) (time.Time, time.Time, error) {
+	var d, b time.Time
+	var err error
+	if due != "" {
+		if d, err = time.ParseInLocation(dateInputFormat, due, time.Local); err != nil {
+			return d, b, fmt.Errorf("The due date should be a date, like 2026-04-30")
+		}
+	}
+	if born != "" {
+		if b, err = time.ParseInLocation(dateInputFormat, born, time.Local); err != nil {
+			return d, b, fmt.Errorf("The birthday should be a date, like 2026-04-30")
+		}
+		if b.After(time.Now()) {
+			return d, b, fmt.Errorf("The birthday can't be in the future, use the due date")
+		}
+	}
+	return d, b, nil
+}
+
+// buildTimeline
+// Put the milestones on the calendar. Pregnancy milestones count back from
+// the due date and are left out once the baby is born. Baby milestones count
+// from the birthday, or the due date until then.
+func (s *Server) buildTimeline(milestones []store.Milestone, resources []store.Resource, due, born, now time.Time) []timelineEntry {
+	ret := make([]timelineEntry, 0, len(milestones))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	current := false
+	for _, m := range milestones {
+		start := due
+		if m.Stage == store.MilestonePregnancy && !born.IsZero() {
+			continue
+		}
+		if m.Stage == store.MilestoneBaby && !born.IsZero() {
+			start = born
+		}
+		e := timelineEntry{Milestone: m, Date: m.Date(start)}
+		// A milestone is current for the week it starts
+		e.Past = !e.Date.AddDate(0, 0, 7).After(today)
+		if !e.Past && !current {
+			e.Current, current = true, true
+		}
+		e.Resources = milestoneResources(m, resources)
+		for i := range e.Resources {
+			e.Resources[i].Link = s.resultURL(e.Resources[i].Title, "")
+		}
+		if len(m.Tags) > 0 {
+			e.More = s.browseURL(search.TagQuery{m.Tags}, nil)
+		}
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+// milestoneResources
+// Returns the resources linked to a milestone, the ones named first and
+// then ones with its tags, up to timelineResources
+func milestoneResources(m store.Milestone, resources []store.Resource) []timelineLink {
+	ret := make([]timelineLink, 0, timelineResources)
+	add := func(title string) {
+		for _, l := range ret {
+			if strings.EqualFold(l.Title, title) {
+				return
+			}
+		}
+		if len(ret) < timelineResources {
+			ret = append(ret, timelineLink{Title: title})
+		}
+	}
+	for _, t := range m.Resources {
+		for _, res := range resources {
+			if strings.EqualFold(res.Title, t) {
+				add(res.Title)
+			}
+		}
+	}
+	for _, res := range resources {
+		if len(m.Tags) > 0 && search.ContainsAnyFold(res.Tags, m.Tags) {
+			add(res.Title)
+		}
+	}
+	return ret
+}
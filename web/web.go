@@ -0,0 +1,443 @@
+// Package web serves the public pages, the JSON API and GraphQL for the
+// directory. A Server is an http.Handler, so it can be mounted in another
+// mux, with everything below Config.BasePath.
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/context"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/graphql-go/graphql"
+	"github.com/openwichita/infant-info/geo"
+	"github.com/openwichita/infant-info/search"
+	"github.com/openwichita/infant-info/store"
+	"github.com/openwichita/infant-info/transit"
+)
+
+// Config is everything needed to set up a Server
+type Config struct {
+	Title   string
+	DevMode bool // Print requests and enable the dev tools
+
+	SessionName   string
+	SessionSecret string // Set this to something else when in production
+
+	TemplateDir string
+	AssetDir    string
+
+	// BasePath is where the directory is mounted, like "/directory"
+	// Leave it blank when the directory is the whole site.
+	BasePath string
+}
+
+// DefaultConfig
+// Returns the settings used when running on our own
+func DefaultConfig() Config {
+	return Config{
+		Title:         "Infant Info",
+		SessionName:   "infant-info",
+		SessionSecret: "webserver secret wahoo",
+		TemplateDir:   "templates",
+		AssetDir:      "assets",
+	}
+}
+
+// SiteData contains data needed for many templates
+// Header/Footer/Menu, etc.
+type SiteData struct {
+	DevMode bool
+
+	Title    string
+	SubTitle string
+	BasePath string // Prefix for every link
+
+	Stylesheets []string
+	Scripts     []string
+
+	Flash      FlashMessage // Quick message at top of page
+	Banner     FlashMessage // Sitewide emergency banner, set by the admins
+	Menu       []MenuItem   // Top-aligned menu items
+	BottomMenu []MenuItem   // Bottom-aligned menu items
+
+	// Any other template data
+	TemplateData interface{}
+}
+
+// FlashMessage is shown in the 'aside' in the header template
+type FlashMessage struct {
+	Message string
+	Status  string
+	Phone   string // A number to call, like a crisis hotline
+	Link    string // A page with more information
+}
+
+// MenuItem is a link in the side menu
+type MenuItem struct {
+	Text   string
+	Link   string
+	Active bool
+}
+
+// Server handles every request for the directory
+type Server struct {
+	Config
+	Store   *store.Store
+	Admin   *store.AdminStore
+	Limiter *RateLimiter
+	Geo     *geo.Gazetteer   // Locates ZIP codes, nil knows nowhere
+	Transit *transit.Watcher // Bus stops near resources, nil for none
+
+	sessions  *sessions.CookieStore
+	router    *mux.Router // Everything
+	routes    *mux.Router // Everything under BasePath
+	gqlSchema graphql.Schema
+	stats     *searchStats
+}
+
+// New
+// Returns a Server for the given stores, with all of the public routes set up
+func New(cfg Config, st *store.Store, adm *store.AdminStore) (*Server, error) {
+	s := &Server{
+		Config:   cfg,
+		Store:    st,
+		Admin:    adm,
+		sessions: sessions.NewCookieStore([]byte(cfg.SessionSecret)),
+	}
+	s.BasePath = strings.TrimSuffix(s.BasePath, "/")
+	s.Limiter = newRateLimiter(adm)
+	s.stats = newSearchStats()
+	var err error
+	if s.gqlSchema, err = s.buildGraphQLSchema(); err != nil {
+		return nil, err
+	}
+
+	s.router = mux.NewRouter()
+	s.router.StrictSlash(true)
+	s.routes = s.router
+	if s.BasePath != "" {
+		s.routes = s.router.PathPrefix(s.BasePath).Subrouter()
+	}
+	r := s.routes
+
+	assetHandler := http.FileServer(http.Dir(s.AssetDir))
+	r.PathPrefix("/assets/").Handler(http.StripPrefix(s.URL("/assets/"), assetHandler))
+	r.HandleFunc("/search/", s.handleSearch)
+	r.HandleFunc("/browse/", s.handleBrowse)
+	r.HandleFunc("/browse/{tags}", s.handleBrowse)
+	r.HandleFunc("/screener/", s.handleScreener).Methods("GET", "POST")
+	r.HandleFunc("/timeline/", s.handleTimeline)
+	r.HandleFunc("/orgs/", s.handleOrgs)
+	r.HandleFunc("/orgs/{id}", s.handleOrg)
+	r.HandleFunc("/about/", s.handleAbout)
+	r.HandleFunc("/go/{title:.+}", s.handleGo)
+
+	// API Subrouter
+	// Titles can have slashes in them, so the API matches the escaped path
+	// and handlers unescape {title} themselves, see apiPathVar
+	a := r.PathPrefix("/api").Subrouter()
+	a.UseEncodedPath()
+	for _, rt := range s.apiRoutes() {
+		a.HandleFunc(rt.Path, s.rateLimited(rt.Handler)).Methods(rt.Method)
+	}
+	a.HandleFunc("/openapi.json", s.handleOpenAPI).Methods("GET")
+
+	r.HandleFunc("/graphql", s.rateLimited(s.handleGraphQL)).Methods("GET", "POST")
+	r.HandleFunc("/graphiql", s.handleGraphiQL)
+
+	r.HandleFunc("/download", s.handleBackupData)
+
+	r.HandleFunc("/", s.handleSearch)
+	return s, nil
+}
+
+// ServeHTTP
+// Makes the Server an http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	context.ClearHandler(s.router).ServeHTTP(w, req)
+}
+
+// Routes
+// Returns the router for everything under BasePath, so more can be added
+func (s *Server) Routes() *mux.Router {
+	return s.routes
+}
+
+// URL
+// Returns 'path' under BasePath
+func (s *Server) URL(path string) string {
+	return s.BasePath + path
+}
+
+// Redirect
+// Send the browser to 'path' under BasePath
+func (s *Server) Redirect(w http.ResponseWriter, req *http.Request, path string) {
+	http.Redirect(w, req, s.URL(path), 302)
+}
+
+// SetFlash
+// Keep a message in the session for the 'aside' in the header template of
+// the next page shown, for after a redirect
+// Valid 'status' values include:
+// - primary		(blue)
+// - secondary (light blue)
+// - success		(green)
+// - error			(maroon)
+// - warning		(orange)
+func (s *Server) SetFlash(w http.ResponseWriter, req *http.Request, msg, status string) {
+	if status == "" {
+		status = "primary"
+	}
+	session, err := s.Session(req)
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Setting Flash: %s\n", err))
+		return
+	}
+	session.Values["flash"] = msg
+	session.Values["flash_status"] = status
+	session.Save(req, w)
+}
+
+// TakeFlash
+// Returns the message kept by SetFlash, if any, and removes it from the
+// session so it's only shown once
+func (s *Server) TakeFlash(w http.ResponseWriter, req *http.Request) FlashMessage {
+	var ret FlashMessage
+	session, err := s.Session(req)
+	if err != nil {
+		return ret
+	}
+	msg, _ := session.Values["flash"].(string)
+	if msg == "" {
+		return ret
+	}
+	ret.Message = msg
+	ret.Status, _ = session.Values["flash_status"].(string)
+	delete(session.Values, "flash")
+	delete(session.Values, "flash_status")
+	session.Save(req, w)
+	return ret
+}
+
+// NewPage
+// Set up the response and the SiteData for a public page
+func (s *Server) NewPage(w http.ResponseWriter, req *http.Request) *SiteData {
+	s.PrintOutput(fmt.Sprintf("Request: %s\n", req.URL))
+	// Set no caching
+	w.Header().Set("Cache-Control", "no-cache")
+
+	site := &SiteData{
+		DevMode:  s.DevMode,
+		Title:    s.Title,
+		BasePath: s.BasePath,
+		Banner:   s.Banner(),
+	}
+
+	site.Stylesheets = make([]string, 0, 0)
+	site.Stylesheets = append(site.Stylesheets, s.URL("/assets/css/pure-min.css"))
+	site.Stylesheets = append(site.Stylesheets, s.URL("/assets/css/ii.css"))
+	site.Stylesheets = append(site.Stylesheets, "https://maxcdn.bootstrapcdn.com/font-awesome/4.4.0/css/font-awesome.min.css")
+	site.Scripts = make([]string, 0, 0)
+	site.Scripts = append(site.Scripts, s.URL("/assets/js/ii.js"))
+
+	site.Menu = make([]MenuItem, 0, 0)
+	site.BottomMenu = make([]MenuItem, 0, 0)
+	site.Menu = append(site.Menu, MenuItem{Text: "Search", Link: s.URL("/search/")})
+	site.Menu = append(site.Menu, MenuItem{Text: "Browse", Link: s.URL("/browse/")})
+	site.Menu = append(site.Menu, MenuItem{Text: "Screener", Link: s.URL("/screener/")})
+	site.Menu = append(site.Menu, MenuItem{Text: "Timeline", Link: s.URL("/timeline/")})
+	site.Menu = append(site.Menu, MenuItem{Text: "Organizations", Link: s.URL("/orgs/")})
+	site.Menu = append(site.Menu, MenuItem{Text: "About", Link: s.URL("/about/")})
+
+	site.BottomMenu = append(site.BottomMenu, MenuItem{Text: "Admin", Link: s.URL("/admin/")})
+	return site
+}
+
+// Searches with fewer results than this offer "Did you mean ...?"
+const didYouMeanBelow = 3
+
+// searchData is what search.html shows
+type searchData struct {
+	Query          string
+	Hidden         url.Values // The rest of the filter, kept when searching again
+	DidYouMean     string
+	DidYouMeanLink string
+	Location       nearData
+	Results        *resultsData
+}
+
+// handleSearch
+// The main handler for all 'search' functionality
+func (s *Server) handleSearch(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+
+	site.SubTitle = "Search Resources"
+	site.SetMenuItemActive("Search")
+
+	f := search.ParseFilter(req.URL.Query())
+	data := searchData{Query: f.Query, Hidden: f.Values(), Location: s.locate(&f)}
+	data.Hidden.Del(search.ParamQuery)
+	data.Hidden.Del(search.ParamNear)
+	data.Hidden.Del(search.ParamWithin)
+	data.Hidden.Del(search.ParamServes)
+	// Was a search action requested?
+	if !f.IsEmpty() {
+		s.PrintOutput(fmt.Sprintf("  Query: %s\n", f.Values().Encode()))
+		resources, err := s.Store.Resources()
+		if err != nil {
+			s.PrintOutput(fmt.Sprintf("Error Loading Resources: %s\n", err))
+		}
+		s.ShowCrisis(site, f.Query)
+		th := s.Thesaurus()
+		f.Expansion = th.Expand(f.Query)
+		matches := f.Apply(resources)
+		if f.Query != "" && len(matches) < didYouMeanBelow {
+			// Only suggest a spelling that finds more
+			if sugg := search.Suggest(f.Query, resources, th.Words()); sugg != "" {
+				alt := f
+				alt.Query = sugg
+				alt.Expansion = th.Expand(sugg)
+				if len(alt.Apply(resources)) > len(matches) {
+					data.DidYouMean = sugg
+					data.DidYouMeanLink = s.resultsURL("/search/", alt.Values(), 1)
+				}
+			}
+		}
+		if req.FormValue("page") == "" && len(data.Hidden) == 0 {
+			// Only count new searches, not paging or narrowing them down
+			s.stats.search(f.Query, len(matches))
+		}
+		res := s.buildResults(req, "/search/", f, matches, s.Promotions(f.Query, f.Tags))
+		data.Results = &res
+	}
+	site.Scripts = append(site.Scripts, s.URL("/assets/js/search.js"))
+	site.TemplateData = data
+	s.ShowPage("search.html", site, w)
+}
+
+// Thesaurus
+// Returns the search synonyms the admins have set up
+func (s *Server) Thesaurus() *search.Thesaurus {
+	syns, err := s.Admin.Synonyms()
+	if err != nil {
+		s.PrintOutput(fmt.Sprintf("Error Loading Synonyms: %s\n", err))
+	}
+	return search.NewThesaurus(syns)
+}
+
+// handleAbout
+// Show the about screen
+func (s *Server) handleAbout(w http.ResponseWriter, req *http.Request) {
+	site := s.NewPage(w, req)
+
+	site.SubTitle = "About"
+	site.SetMenuItemActive("About")
+
+	s.ShowPage("about.html", site, w)
+}
+
+// handleBackupData
+// Pushes a download of the resource database
+func (s *Server) handleBackupData(w http.ResponseWriter, req *http.Request) {
+	b := new(bytes.Buffer)
+	err := s.Store.Backup(b)
+	s.PrintOutput("DB Backup Requested\n")
+	s.PrintOutput(fmt.Sprintf("DB Size: %d\n", b.Len()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="infant-info.db"`)
+	w.Header().Set("Content-Length", strconv.Itoa(b.Len()))
+	if _, err = b.WriteTo(w); err != nil {
+		s.PrintOutput(fmt.Sprintf("%s\n", err))
+	}
+}
+
+// ShowPage
+// Load a template and all of the surrounding templates
+func (s *Server) ShowPage(tmplName string, tmplData interface{}, w io.Writer) error {
+	for _, tmpl := range []string{
+		"htmlheader.html",
+		"menu.html",
+		"header.html",
+		tmplName,
+		"footer.html",
+		"htmlfooter.html",
+	} {
+		if err := s.OutputTemplate(tmpl, tmplData, w); err != nil {
+			s.PrintOutput(fmt.Sprintf("%s\n", err))
+			return err
+		}
+	}
+	return nil
+}
+
+// OutputTemplate
+// Spit out a template
+func (s *Server) OutputTemplate(tmplName string, tmplData interface{}, w io.Writer) error {
+	path := filepath.Join(s.TemplateDir, tmplName)
+	_, err := os.Stat(path)
+	if err == nil {
+		// Every page can use the partial-*.html templates
+		files := []string{path}
+		partials, _ := filepath.Glob(filepath.Join(s.TemplateDir, "partial-*.html"))
+		t := template.New(tmplName)
+		t, _ = t.ParseFiles(append(files, partials...)...)
+		return t.Execute(w, tmplData)
+	}
+	return fmt.Errorf("WebServer: Cannot load template (%s): File not found", path)
+}
+
+// SetMenuItemActive
+// Sets a menu item to active, all others to inactive
+func (site *SiteData) SetMenuItemActive(which string) {
+	for i := range site.Menu {
+		if site.Menu[i].Text == which {
+			site.Menu[i].Active = true
+		} else {
+			site.Menu[i].Active = false
+		}
+	}
+}
+
+// Session
+// Returns the session for the request
+func (s *Server) Session(req *http.Request) (*sessions.Session, error) {
+	return s.sessions.Get(req, s.SessionName)
+}
+
+// SessionString
+// Returns a string value from the session
+func (s *Server) SessionString(key string, req *http.Request) (string, error) {
+	session, err := s.Session(req)
+	if err != nil {
+		return "", err
+	}
+	val := session.Values[key]
+	var retVal string
+	var ok bool
+	if retVal, ok = val.(string); !ok {
+		return "", fmt.Errorf("Unable to create string from %s", key)
+	}
+	return retVal, nil
+}
+
+// PrintOutput
+// Print something to the screen, if conditions are right
+func (s *Server) PrintOutput(out string) {
+	if s.DevMode {
+		fmt.Print(out)
+	}
+}
@@ -0,0 +1,30 @@
+package web
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// newTestServer
+// Returns a Server on fresh databases in a temporary directory
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	dir := t.TempDir()
+	st, err := store.Open(filepath.Join(dir, "ii.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adm, err := store.OpenAdmin(filepath.Join(dir, "iiAdmin.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.TemplateDir = "../templates"
+	s, err := New(cfg, st, adm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
@@ -0,0 +1,317 @@
+// Package webhook sends signed resource events to the webhooks in the
+// admin database.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// Outbound webhooks
+// When a resource is created, updated or deleted a delivery is queued for
+// every webhook that wants that event. The queue lives in the admin db so
+// nothing is lost on a restart, and Run works through it, retrying failures
+// with an increasing backoff.
+const (
+	maxAttempts = 8
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	keepFor     = 30 * 24 * time.Hour
+
+	// maxSenders is how many webhooks are sent to at once. Each webhook
+	// gets its deliveries in order, so a slow receiver only holds up itself
+	maxSenders = 4
+)
+
+// Headers sent with every delivery
+// The signature is the hex HMAC-SHA256 of the body using the webhook's secret
+const (
+	EventHeader     = "X-Infant-Info-Event"
+	DeliveryHeader  = "X-Infant-Info-Delivery"
+	SignatureHeader = "X-Infant-Info-Signature"
+)
+
+// Payload is the JSON body of every delivery
+type Payload struct {
+	Event         string         `json:"event"`
+	Time          time.Time      `json:"time"`
+	Resource      store.Resource `json:"resource"`
+	PreviousTitle string         `json:"previous_title,omitempty"`
+}
+
+// Dispatcher queues and sends deliveries
+type Dispatcher struct {
+	admin     *store.AdminStore
+	userAgent string
+	output    func(string)
+	client    *http.Client
+
+	// wake is poked whenever something is queued, so deliveries don't
+	// have to wait for the next tick
+	wake chan struct{}
+}
+
+// New
+// Returns a Dispatcher for the webhooks in 'admin'. Progress is written to
+// 'output', which may be nil.
+func New(admin *store.AdminStore, userAgent string, output func(string)) *Dispatcher {
+	if output == nil {
+		output = func(string) {}
+	}
+	return &Dispatcher{
+		admin:     admin,
+		userAgent: userAgent,
+		output:    output,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Queue
+// Queue a delivery of the event to every webhook that wants it
+// This is meant to be registered with store.Store.OnChange
+func (d *Dispatcher) Queue(ev store.Event) {
+	hooks, err := d.admin.Webhooks()
+	if err != nil {
+		d.output(fmt.Sprintf("Error loading webhooks: %s\n", err))
+		return
+	}
+	payload, err := json.Marshal(Payload{
+		Event:         ev.Type,
+		Time:          time.Now(),
+		Resource:      ev.Resource,
+		PreviousTitle: ev.PreviousTitle,
+	})
+	if err != nil {
+		d.output(fmt.Sprintf("Error building webhook payload: %s\n", err))
+		return
+	}
+	dels := make([]store.Delivery, 0, 0)
+	for i := range hooks {
+		if hooks[i].HasEvent(ev.Type) {
+			dels = append(dels, newDelivery(hooks[i], ev.Type, payload))
+		}
+	}
+	if err := d.admin.AddDeliveries(dels); err != nil {
+		d.output(fmt.Sprintf("Error queueing webhooks: %s\n", err))
+		return
+	}
+	d.poke()
+}
+
+// Ping
+// Queue a 'ping' to a single webhook to check that it's receiving
+func (d *Dispatcher) Ping(h store.Webhook) error {
+	payload, err := json.Marshal(Payload{Event: store.EventPing, Time: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := d.admin.AddDeliveries([]store.Delivery{newDelivery(h, store.EventPing, payload)}); err != nil {
+		return err
+	}
+	d.poke()
+	return nil
+}
+
+func newDelivery(h store.Webhook, event string, payload []byte) store.Delivery {
+	now := time.Now()
+	return store.Delivery{
+		Hook:        h.ID,
+		URL:         h.URL,
+		Event:       event,
+		Payload:     payload,
+		Status:      store.DeliveryPending,
+		Created:     now,
+		NextAttempt: now,
+	}
+}
+
+func (d *Dispatcher) poke() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run
+// Work through the delivery queue, this should be started with 'go'
+func (d *Dispatcher) Run(every time.Duration) {
+	tick := time.NewTicker(every)
+	for {
+		d.sendPending(time.Now())
+		select {
+		case <-tick.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// sendPending
+// Attempt every pending delivery that is due
+func (d *Dispatcher) sendPending(now time.Time) {
+	pending, err := d.admin.Deliveries(store.DeliveryPending, 0)
+	if err != nil {
+		d.output(fmt.Sprintf("Error loading webhook deliveries: %s\n", err))
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+	hooks, err := d.admin.Webhooks()
+	if err != nil {
+		d.output(fmt.Sprintf("Error loading webhooks: %s\n", err))
+		return
+	}
+	secrets := make(map[string]string)
+	for i := range hooks {
+		secrets[hooks[i].ID] = hooks[i].Secret
+	}
+	// The queue comes back newest first
+	due := make(map[string][]store.Delivery)
+	order := make([]string, 0, 0)
+	for i := len(pending) - 1; i >= 0; i-- {
+		if pending[i].NextAttempt.After(now) {
+			continue
+		}
+		hook := pending[i].Hook
+		if _, ok := due[hook]; !ok {
+			order = append(order, hook)
+		}
+		due[hook] = append(due[hook], pending[i])
+	}
+
+	hooksCh := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < maxSenders && i < len(order); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hook := range hooksCh {
+				secret, ok := secrets[hook]
+				d.sendHook(due[hook], secret, ok)
+			}
+		}()
+	}
+	for _, hook := range order {
+		hooksCh <- hook
+	}
+	close(hooksCh)
+	wg.Wait()
+
+	if err := d.admin.PruneDeliveries(now.Add(-keepFor)); err != nil {
+		d.output(fmt.Sprintf("Error pruning webhook deliveries: %s\n", err))
+	}
+}
+
+// sendHook
+// Attempt one webhook's due deliveries in order, failing them all if the
+// webhook has been removed
+func (d *Dispatcher) sendHook(dels []store.Delivery, secret string, exists bool) {
+	for _, del := range dels {
+		if !exists {
+			del.Status = store.DeliveryFailed
+			del.LastError = "Webhook has been removed"
+		} else {
+			d.attempt(&del, secret)
+		}
+		if err := d.admin.SaveDelivery(del); err != nil {
+			d.output(fmt.Sprintf("Error saving webhook delivery: %s\n", err))
+		}
+	}
+}
+
+// attempt
+// POST the delivery and update its status, scheduling a retry if it fails
+func (d *Dispatcher) attempt(del *store.Delivery, secret string) {
+	del.Attempts++
+	del.LastAttempt = time.Now()
+	del.LastStatus = 0
+	del.LastError = ""
+	sending := fmt.Sprintf("Webhook Delivery %d (%s) -> %s\n", del.ID, del.Event, del.URL)
+
+	req, err := http.NewRequest("POST", del.URL, bytes.NewReader(del.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", d.userAgent)
+		req.Header.Set(EventHeader, del.Event)
+		req.Header.Set(DeliveryHeader, strconv.FormatUint(del.ID, 10))
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, del.Payload))
+		var resp *http.Response
+		if resp, err = d.client.Do(req); err == nil {
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+			del.LastStatus = resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = fmt.Errorf("Receiver responded %s", resp.Status)
+			}
+		}
+	}
+	if err == nil {
+		d.output(sending + "		Success!\n")
+		del.Status = store.DeliveryDelivered
+		return
+	}
+
+	d.output(fmt.Sprintf("%s		Failed: %s!\n", sending, err))
+	del.LastError = err.Error()
+	if del.Attempts >= maxAttempts {
+		del.Status = store.DeliveryFailed
+		return
+	}
+	del.NextAttempt = del.LastAttempt.Add(backoff(del.Attempts))
+}
+
+// backoff
+// How long to wait after the given number of failed attempts
+func backoff(attempts int) time.Duration {
+	ret := baseBackoff
+	for i := 1; i < attempts && ret < maxBackoff; i++ {
+		ret *= 2
+	}
+	if ret > maxBackoff {
+		ret = maxBackoff
+	}
+	return ret
+}
+
+// Sign
+// Receivers check this against the X-Infant-Info-Signature header
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Retry
+// Put a finished delivery back in the queue to be sent again
+func (d *Dispatcher) Retry(id uint64) error {
+	dels, err := d.admin.Deliveries("", 0)
+	if err != nil {
+		return err
+	}
+	for i := range dels {
+		if dels[i].ID == id {
+			dels[i].Status = store.DeliveryPending
+			dels[i].Attempts = 0
+			dels[i].NextAttempt = time.Now()
+			if err := d.admin.SaveDelivery(dels[i]); err != nil {
+				return err
+			}
+			d.poke()
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid Delivery")
+}
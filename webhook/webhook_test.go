@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openwichita/infant-info/store"
+)
+
+// received is a delivery as the receiver saw it
+type received struct {
+	Event     string
+	Signature string
+	Body      []byte
+}
+
+// receiver is an httptest server that records deliveries and answers
+// with the statuses in 'replies' (200 once they run out)
+type receiver struct {
+	*httptest.Server
+	mu      sync.Mutex
+	got     []received
+	replies []int
+}
+
+func newReceiver(t *testing.T, replies ...int) *receiver {
+	r := &receiver{replies: replies}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.mu.Lock()
+		r.got = append(r.got, received{
+			Event:     req.Header.Get(EventHeader),
+			Signature: req.Header.Get(SignatureHeader),
+			Body:      body,
+		})
+		status := http.StatusOK
+		if len(r.replies) > 0 {
+			status, r.replies = r.replies[0], r.replies[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := make([]string, 0, len(r.got))
+	for i := range r.got {
+		ret = append(ret, r.got[i].Event)
+	}
+	return ret
+}
+
+// newTestDispatcher
+// Returns a Dispatcher on a fresh admin database in a temporary directory
+func newTestDispatcher(t *testing.T) (*store.AdminStore, *Dispatcher) {
+	t.Helper()
+	adm, err := store.OpenAdmin(filepath.Join(t.TempDir(), "iiAdmin.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adm, New(adm, "test", nil)
+}
+
+// queue
+// Queue the event the store sends when 'title' is created, updated or deleted
+func queue(d *Dispatcher, event, title string) {
+	d.Queue(store.Event{
+		Type:     event,
+		Resource: store.Resource{Title: title, URL: "https://example.org/" + title},
+	})
+}
+
+func TestSignature(t *testing.T) {
+	adm, d := newTestDispatcher(t)
+	r := newReceiver(t)
+	h, err := adm.SaveWebhook(r.URL, []string{store.EventCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue(d, store.EventCreated, "Healthy Babies")
+	d.sendPending(time.Now())
+
+	if len(r.got) != 1 {
+		t.Fatalf("Got %d deliveries, wanted 1", len(r.got))
+	}
+	got := r.got[0]
+	mac := hmac.New(sha256.New, []byte(h.Secret))
+	mac.Write(got.Body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got.Signature != want {
+		t.Errorf("Signature is %q, wanted %q", got.Signature, want)
+	}
+	var p Payload
+	if err := json.Unmarshal(got.Body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Event != store.EventCreated || p.Resource.Title != "Healthy Babies" {
+		t.Errorf("Payload is %+v", p)
+	}
+}
+
+func TestEventFilters(t *testing.T) {
+	adm, d := newTestDispatcher(t)
+	hooks := map[string]*receiver{
+		store.EventCreated: newReceiver(t),
+		store.EventUpdated: newReceiver(t),
+		store.EventDeleted: newReceiver(t),
+	}
+	for ev, r := range hooks {
+		if _, err := adm.SaveWebhook(r.URL, []string{ev}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all := newReceiver(t)
+	if _, err := adm.SaveWebhook(all.URL, store.WebhookEvents); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ev := range []string{store.EventCreated, store.EventUpdated, store.EventDeleted} {
+		queue(d, ev, "Healthy Babies")
+	}
+	d.sendPending(time.Now())
+
+	for ev, r := range hooks {
+		if got := r.events(); len(got) != 1 || got[0] != ev {
+			t.Errorf("The %s webhook got %v", ev, got)
+		}
+	}
+	if got := all.events(); len(got) != 3 {
+		t.Errorf("The webhook for everything got %v", got)
+	}
+}
+
+func TestRetryAfterFailure(t *testing.T) {
+	adm, d := newTestDispatcher(t)
+	r := newReceiver(t, http.StatusInternalServerError)
+	if _, err := adm.SaveWebhook(r.URL, store.WebhookEvents); err != nil {
+		t.Fatal(err)
+	}
+	queue(d, store.EventCreated, "Healthy Babies")
+
+	now := time.Now()
+	d.sendPending(now)
+	dels, err := adm.Deliveries("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	del := dels[0]
+	if del.Status != store.DeliveryPending || del.Attempts != 1 || del.LastStatus != http.StatusInternalServerError {
+		t.Fatalf("After a 500 the delivery is %s after %d attempts (%d)", del.Status, del.Attempts, del.LastStatus)
+	}
+	if wait := del.NextAttempt.Sub(del.LastAttempt); wait != baseBackoff {
+		t.Errorf("The retry is %s later, wanted %s", wait, baseBackoff)
+	}
+
+	// Not due yet
+	d.sendPending(now.Add(baseBackoff / 2))
+	if n := len(r.events()); n != 1 {
+		t.Errorf("Retried before the backoff, %d attempts", n)
+	}
+
+	d.sendPending(now.Add(2 * baseBackoff))
+	if dels, err = adm.Deliveries("", 0); err != nil {
+		t.Fatal(err)
+	}
+	if del = dels[0]; del.Status != store.DeliveryDelivered || del.Attempts != 2 {
+		t.Errorf("After the retry the delivery is %s after %d attempts", del.Status, del.Attempts)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, baseBackoff},
+		{2, 2 * baseBackoff},
+		{3, 4 * baseBackoff},
+		{maxAttempts, 128 * baseBackoff},
+		{100, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, wanted %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestReceiversDontBlockSaves
+// Queue runs inside Store.Save, so a receiver that hangs or is down must
+// only hold up the dispatcher
+func TestReceiversDontBlockSaves(t *testing.T) {
+	adm, d := newTestDispatcher(t)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	for _, url := range []string{slow.URL, down.URL} {
+		if _, err := adm.SaveWebhook(url, store.WebhookEvents); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queue(d, store.EventCreated, "Healthy Babies")
+	sending := make(chan struct{})
+	go func() {
+		d.sendPending(time.Now())
+		close(sending)
+	}()
+	defer func() {
+		close(release)
+		<-sending
+	}()
+
+	start := time.Now()
+	for _, title := range []string{"Parents as Teachers", "Safe Sleep", "WIC"} {
+		queue(d, store.EventCreated, title)
+	}
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("Queueing took %s while a receiver was hanging", took)
+	}
+	select {
+	case <-sending:
+		t.Error("The dispatcher didn't wait for the slow receiver")
+	default:
+	}
+	dels, err := adm.Deliveries(store.DeliveryPending, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 webhooks for each of the 4 resources
+	if len(dels) != 8 {
+		t.Errorf("%d deliveries are pending, wanted 8", len(dels))
+	}
+}
+
+// TestSlowReceiver
+// A receiver that hangs holds up its own deliveries, which still go in
+// order, but not the other webhooks'
+func TestSlowReceiver(t *testing.T) {
+	adm, d := newTestDispatcher(t)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var slowGot []string
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		mu.Lock()
+		slowGot = append(slowGot, req.Header.Get(EventHeader))
+		mu.Unlock()
+	}))
+	defer slow.Close()
+	if _, err := adm.SaveWebhook(slow.URL, store.WebhookEvents); err != nil {
+		t.Fatal(err)
+	}
+	// More than there are senders, so some wait for a free one
+	fast := make([]*receiver, 0, 0)
+	for i := 0; i < maxSenders+2; i++ {
+		r := newReceiver(t)
+		if _, err := adm.SaveWebhook(r.URL, store.WebhookEvents); err != nil {
+			t.Fatal(err)
+		}
+		fast = append(fast, r)
+	}
+	events := []string{store.EventCreated, store.EventUpdated, store.EventDeleted}
+	for _, ev := range events {
+		queue(d, ev, "Healthy Babies")
+	}
+
+	sending := make(chan struct{})
+	go func() {
+		d.sendPending(time.Now())
+		close(sending)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for _, r := range fast {
+		for len(r.events()) < len(events) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := r.events(); len(got) != len(events) {
+			t.Errorf("A receiver got %v while another was hanging", got)
+		}
+	}
+
+	close(release)
+	<-sending
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(slowGot, ",") != strings.Join(events, ",") {
+		t.Errorf("The slow receiver got %v, wanted %v", slowGot, events)
+	}
+}